@@ -0,0 +1,194 @@
+// Package analytics records page views and invite clicks of bots and
+// servers, and serves them back as daily series.
+//
+// Recording a view never touches the entity's row. Each view or invite click
+// is appended to entity_clicks, its visitor is added to two Redis
+// HyperLogLogs (one for the day, one all-time), and the entity's counter
+// increments are accumulated in a Redis hash. The view_analytics_rollup task
+// (Rollup) then, every few minutes:
+//
+//   - applies the accumulated increments to the clicks and invite_clicks
+//     columns, so each row is rewritten at most once per run however popular
+//     the entity is
+//   - folds today's and yesterday's clicks and unique visitor counts into
+//     entity_views_daily, which is kept forever
+//   - deletes clicks past their 90 day retention
+//
+// Visitors are identified by a SHA256 hash of their IP, as before. Only the
+// host of the referrer is kept.
+package analytics
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"popplio/state"
+)
+
+const (
+	// pendingKey accumulates counter increments, as fields of
+	// "<target_type>:<target_id>:<kind>"
+	pendingKey = "analytics:pending"
+
+	// flushingKey holds the increments being applied by a Rollup, so that
+	// increments arriving meanwhile go to a fresh pendingKey
+	flushingKey = "analytics:flushing"
+
+	// Daily HyperLogLogs only need to outlive the last rollup of their day
+	dailyUniqueExpiry = 72 * time.Hour
+
+	// ClickRetention is how long individual clicks are kept. Daily totals
+	// are kept forever.
+	ClickRetention = 90 * 24 * time.Hour
+
+	// maxReferrerLength bounds what a client can make us store
+	maxReferrerLength = 253
+)
+
+type Kind string
+
+const (
+	KindView   Kind = "view"
+	KindInvite Kind = "invite"
+)
+
+// Click is one view or invite click
+type Click struct {
+	TargetType string
+	TargetID   string
+	Kind       Kind
+
+	// Referrer is the URL or host the visitor came from, if known
+	Referrer string
+
+	// ViaVanity is whether the visitor reached the page through its vanity
+	// URL rather than its ID
+	ViaVanity bool
+}
+
+func dailyUniqueKey(targetType, targetID string, day time.Time) string {
+	return "analytics:uv:" + targetType + ":" + targetID + ":" + day.UTC().Format(time.DateOnly)
+}
+
+func allTimeUniqueKey(targetType, targetID string) string {
+	return "analytics:uv:" + targetType + ":" + targetID
+}
+
+// VisitorHash identifies the visitor of r without storing their IP
+func VisitorHash(r *http.Request) string {
+	ip := r.RemoteAddr
+
+	// RealIP leaves the port on when there is no proxy header
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(ip)))
+}
+
+// referrerHost reduces a referrer to its lowercased host, or "" if it has
+// none or it is the list itself
+func referrerHost(referrer string) string {
+	referrer = strings.TrimSpace(referrer)
+
+	if referrer == "" {
+		return ""
+	}
+
+	if !strings.Contains(referrer, "://") {
+		referrer = "https://" + referrer
+	}
+
+	u, err := url.Parse(referrer)
+
+	if err != nil || u.Hostname() == "" || len(u.Hostname()) > maxReferrerLength {
+		return ""
+	}
+
+	host := strings.ToLower(strings.TrimPrefix(u.Hostname(), "www."))
+
+	if frontend, err := url.Parse(state.Config.Sites.Frontend.Parse()); err == nil && strings.TrimPrefix(frontend.Hostname(), "www.") == host {
+		return ""
+	}
+
+	return host
+}
+
+// Record records a click by the visitor of r.
+func Record(ctx context.Context, r *http.Request, c Click) error {
+	var referrer *string
+
+	if host := referrerHost(c.Referrer); host != "" {
+		referrer = &host
+	}
+
+	_, err := state.Pool.Exec(
+		ctx,
+		"INSERT INTO entity_clicks (target_type, target_id, kind, referrer, via_vanity) VALUES ($1, $2, $3, $4, $5)",
+		c.TargetType,
+		c.TargetID,
+		c.Kind,
+		referrer,
+		c.ViaVanity,
+	)
+
+	if err != nil {
+		return fmt.Errorf("inserting click: %w", err)
+	}
+
+	pipe := state.Redis.TxPipeline()
+
+	pipe.HIncrBy(ctx, pendingKey, c.TargetType+":"+c.TargetID+":"+string(c.Kind), 1)
+
+	if c.Kind == KindView {
+		hash := VisitorHash(r)
+		dailyKey := dailyUniqueKey(c.TargetType, c.TargetID, time.Now())
+
+		pipe.PFAdd(ctx, dailyKey, hash)
+		pipe.Expire(ctx, dailyKey, dailyUniqueExpiry)
+		pipe.PFAdd(ctx, allTimeUniqueKey(c.TargetType, c.TargetID), hash)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("updating counters: %w", err)
+	}
+
+	return nil
+}
+
+// UniqueViews returns the approximate number of unique visitors an entity has
+// ever had.
+func UniqueViews(ctx context.Context, targetType, targetID string) (int64, error) {
+	var column, table string
+
+	switch targetType {
+	case "bot":
+		column, table = "bot_id", "bots"
+	case "server":
+		column, table = "server_id", "servers"
+	default:
+		return 0, fmt.Errorf("unsupported target type %q", targetType)
+	}
+
+	var legacy int64
+
+	err := state.Pool.QueryRow(ctx, "SELECT unique_clicks_legacy FROM "+table+" WHERE "+column+" = $1", targetID).Scan(&legacy)
+
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := state.Redis.PFCount(ctx, allTimeUniqueKey(targetType, targetID)).Result()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return legacy + count, nil
+}
@@ -0,0 +1,155 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"popplio/counters"
+	"popplio/state"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// maxReferrers is how many of a day's top referrers are kept
+const maxReferrers = 20
+
+// Rollup applies pending counter increments, refreshes today's and yesterday's
+// daily totals and expires old clicks.
+func Rollup(ctx context.Context) error {
+	if err := flushCounters(ctx); err != nil {
+		return fmt.Errorf("flushing counters: %w", err)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	// Yesterday's clicks may have been recorded after the last run of the day
+	for _, day := range []time.Time{today.Add(-24 * time.Hour), today} {
+		if err := rollupDay(ctx, day); err != nil {
+			return fmt.Errorf("rolling up %s: %w", day.Format(time.DateOnly), err)
+		}
+	}
+
+	_, err := state.Pool.Exec(ctx, "DELETE FROM entity_clicks WHERE created_at < $1", time.Now().Add(-ClickRetention))
+
+	if err != nil {
+		return fmt.Errorf("expiring clicks: %w", err)
+	}
+
+	return nil
+}
+
+// flushCounters applies the increments in pendingKey to the clicks and
+// invite_clicks columns (see counters.Flush)
+func flushCounters(ctx context.Context) error {
+	return counters.Flush(ctx, pendingKey, flushingKey, func(field string, n int64) error {
+		targetType, rest, _ := strings.Cut(field, ":")
+		targetID, kind, _ := strings.Cut(rest, ":")
+
+		var sql string
+
+		switch targetType + ":" + kind {
+		case "bot:view":
+			sql = "UPDATE bots SET clicks = clicks + $1 WHERE bot_id = $2"
+		case "bot:invite":
+			sql = "UPDATE bots SET invite_clicks = invite_clicks + $1 WHERE bot_id = $2"
+		case "server:view":
+			sql = "UPDATE servers SET clicks = clicks + $1 WHERE server_id = $2"
+		case "server:invite":
+			sql = "UPDATE servers SET invite_clicks = invite_clicks + $1 WHERE server_id = $2"
+		default:
+			return nil
+		}
+
+		_, err := state.Pool.Exec(ctx, sql, n, targetID)
+		return err
+	})
+}
+
+type dayTotals struct {
+	TargetType   string           `db:"target_type"`
+	TargetID     string           `db:"target_id"`
+	Views        int64            `db:"views"`
+	InviteClicks int64            `db:"invite_clicks"`
+	VanityViews  int64            `db:"vanity_views"`
+	Referrers    map[string]int64 `db:"referrers"`
+}
+
+// rollupDay recomputes the daily totals of every entity clicked on day
+func rollupDay(ctx context.Context, day time.Time) error {
+	rows, err := state.Pool.Query(
+		ctx,
+		`WITH c AS (
+			SELECT target_type, target_id, kind, referrer, via_vanity FROM entity_clicks
+			WHERE created_at >= $1 AND created_at < $2
+		), refs AS (
+			SELECT target_type, target_id, jsonb_object_agg(referrer, n) AS referrers FROM (
+				SELECT target_type, target_id, referrer, COUNT(*) AS n,
+				row_number() OVER (PARTITION BY target_type, target_id ORDER BY COUNT(*) DESC, referrer) AS rank
+				FROM c WHERE kind = 'view' AND referrer IS NOT NULL
+				GROUP BY target_type, target_id, referrer
+			) r WHERE rank <= $3
+			GROUP BY target_type, target_id
+		)
+		SELECT c.target_type, c.target_id,
+			COUNT(*) FILTER (WHERE kind = 'view') AS views,
+			COUNT(*) FILTER (WHERE kind = 'invite') AS invite_clicks,
+			COUNT(*) FILTER (WHERE kind = 'view' AND via_vanity) AS vanity_views,
+			COALESCE(refs.referrers, '{}') AS referrers
+		FROM c LEFT JOIN refs USING (target_type, target_id)
+		GROUP BY c.target_type, c.target_id, refs.referrers`,
+		day,
+		day.Add(24*time.Hour),
+		maxReferrers,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	totals, err := pgx.CollectRows(rows, pgx.RowToStructByName[dayTotals])
+
+	if err != nil {
+		return err
+	}
+
+	for _, t := range totals {
+		unique, err := state.Redis.PFCount(ctx, dailyUniqueKey(t.TargetType, t.TargetID, day)).Result()
+
+		if err != nil && !errors.Is(err, redis.Nil) {
+			state.Logger.Error("Failed to count unique views", zap.Error(err), zap.String("targetID", t.TargetID), zap.String("targetType", t.TargetType))
+		}
+
+		// A unique count of 0 means the day's HyperLogLog expired before this
+		// run, in which case the last count is kept
+		_, err = state.Pool.Exec(
+			ctx,
+			`INSERT INTO entity_views_daily (target_type, target_id, day, views, unique_views, invite_clicks, vanity_views, referrers)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (target_type, target_id, day) DO UPDATE SET
+				views = EXCLUDED.views,
+				unique_views = CASE WHEN EXCLUDED.unique_views > 0 THEN EXCLUDED.unique_views ELSE entity_views_daily.unique_views END,
+				invite_clicks = EXCLUDED.invite_clicks,
+				vanity_views = EXCLUDED.vanity_views,
+				referrers = EXCLUDED.referrers`,
+			t.TargetType,
+			t.TargetID,
+			day,
+			t.Views,
+			unique,
+			t.InviteClicks,
+			t.VanityViews,
+			t.Referrers,
+		)
+
+		if err != nil {
+			return fmt.Errorf("upserting %s %s: %w", t.TargetType, t.TargetID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// DefaultDays is how many days a series covers if not given
+	DefaultDays = 30
+
+	// MaxDays is how many days a series may cover
+	MaxDays = 366
+)
+
+// Series returns an entity's daily totals for the days from to to inclusive,
+// with days nobody visited on as zeroes.
+func Series(ctx context.Context, targetType, targetID string, from, to time.Time) ([]types.ViewDay, error) {
+	rows, err := state.Pool.Query(
+		ctx,
+		`SELECT to_char(d, 'YYYY-MM-DD') AS day,
+			COALESCE(v.views, 0) AS views,
+			COALESCE(v.unique_views, 0) AS unique_views,
+			COALESCE(v.invite_clicks, 0) AS invite_clicks,
+			COALESCE(v.vanity_views, 0) AS vanity_views,
+			COALESCE(v.views - v.vanity_views, 0) AS direct_views,
+			COALESCE(v.referrers, '{}') AS referrers
+		FROM generate_series($3::date, $4::date, '1 day') d
+		LEFT JOIN entity_views_daily v ON v.target_type = $1 AND v.target_id = $2 AND v.day = d::date
+		ORDER BY d`,
+		targetType,
+		targetID,
+		from,
+		to,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[types.ViewDay])
+}
+
+// ParseQuery reads the from and to query parameters of an analytics endpoint.
+// Both are YYYY-MM-DD UTC days, inclusive, and default to the last
+// DefaultDays days. Errors are user facing.
+func ParseQuery(q url.Values) (from, to time.Time, err error) {
+	to = time.Now().UTC().Truncate(24 * time.Hour)
+
+	if v := q.Get("to"); v != "" {
+		if to, err = time.Parse(time.DateOnly, v); err != nil {
+			return from, to, errors.New("to must be a date in YYYY-MM-DD format")
+		}
+	}
+
+	from = to.AddDate(0, 0, -(DefaultDays - 1))
+
+	if v := q.Get("from"); v != "" {
+		if from, err = time.Parse(time.DateOnly, v); err != nil {
+			return from, to, errors.New("from must be a date in YYYY-MM-DD format")
+		}
+	}
+
+	if from.After(to) {
+		return from, to, errors.New("from must not be after to")
+	}
+
+	if to.Sub(from) >= MaxDays*24*time.Hour {
+		return from, to, errors.New("a range can cover at most 366 days")
+	}
+
+	return from, to, nil
+}
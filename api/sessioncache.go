@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"popplio/state"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// sessionCacheTTL is how long a session stays cached after it is looked up,
+// and so the longest a change to it can go unnoticed if its cache entry is
+// somehow not invalidated
+const sessionCacheTTL = 1 * time.Minute
+
+// Cached sessions are keyed by the hash of their token, never the token. An
+// index per target holds the hashes of its cached sessions, so that they can
+// all be invalidated at once.
+func sessionCacheKey(tokenHash string) string {
+	return "session:" + tokenHash
+}
+
+func sessionIndexKey(targetType, targetID string) string {
+	return "session_index:" + targetType + ":" + targetID
+}
+
+// cachedSession returns the cached session with a token hash, or nil if it
+// isn't cached or has expired. Errors are logged and treated as a miss, so
+// sessions are still looked up from the database when Redis is down.
+func cachedSession(ctx context.Context, tokenHash string) *session {
+	raw, err := state.Redis.Get(ctx, sessionCacheKey(tokenHash)).Bytes()
+
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			state.Logger.Error("Failed to get cached session", zap.Error(err))
+		}
+
+		return nil
+	}
+
+	var s session
+
+	if err := json.Unmarshal(raw, &s); err != nil {
+		state.Logger.Error("Failed to decode cached session", zap.Error(err))
+		return nil
+	}
+
+	if time.Now().After(s.Expiry) {
+		return nil
+	}
+
+	return &s
+}
+
+// cacheSession caches a session for sessionCacheTTL, or until it expires if
+// that is sooner
+func cacheSession(ctx context.Context, tokenHash string, s *session) {
+	ttl := min(sessionCacheTTL, time.Until(s.Expiry))
+
+	if ttl <= 0 {
+		return
+	}
+
+	raw, err := json.Marshal(s)
+
+	if err != nil {
+		state.Logger.Error("Failed to encode session for caching", zap.Error(err))
+		return
+	}
+
+	indexKey := sessionIndexKey(s.TargetType, s.TargetID)
+
+	pipe := state.Redis.TxPipeline()
+	pipe.Set(ctx, sessionCacheKey(tokenHash), raw, ttl)
+	pipe.SAdd(ctx, indexKey, tokenHash)
+	pipe.Expire(ctx, indexKey, sessionCacheTTL)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		state.Logger.Error("Failed to cache session", zap.Error(err))
+	}
+}
+
+// InvalidateSessionCache drops every cached session of a target, so that the
+// next request with any of them is checked against the database again. It
+// must be called after anything that revokes sessions or changes what is
+// cached about them: deleting sessions, and banning or unbanning users.
+// Entity permissions, including those given by team roles, are not cached
+// and are checked on every request.
+func InvalidateSessionCache(ctx context.Context, targetType, targetID string) {
+	indexKey := sessionIndexKey(targetType, targetID)
+
+	hashes, err := state.Redis.SMembers(ctx, indexKey).Result()
+
+	if err != nil {
+		state.Logger.Error("Failed to get cached sessions to invalidate", zap.Error(err), zap.String("targetType", targetType), zap.String("targetID", targetID))
+		return
+	}
+
+	keys := []string{indexKey}
+
+	for _, hash := range hashes {
+		keys = append(keys, sessionCacheKey(hash))
+	}
+
+	if err := state.Redis.Del(ctx, keys...).Err(); err != nil {
+		state.Logger.Error("Failed to invalidate cached sessions", zap.Error(err), zap.String("targetType", targetType), zap.String("targetID", targetID))
+	}
+}
+
+// DeleteExpiredSessions deletes API sessions that have expired. Expired
+// sessions are already rejected when they are looked up, so this only keeps
+// api_sessions from growing.
+func DeleteExpiredSessions(ctx context.Context) error {
+	_, err := state.Pool.Exec(ctx, "DELETE FROM api_sessions WHERE expiry < NOW()")
+	return err
+}
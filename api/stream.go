@@ -0,0 +1,116 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"popplio/state"
+
+	"github.com/go-chi/chi/v5"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/jsonimpl"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+)
+
+// StreamHandler handles a streaming route. Unlike a uapi handler it owns the
+// connection and writes to it directly for as long as it likes.
+type StreamHandler func(d uapi.RouteData, w http.ResponseWriter, r *http.Request)
+
+// streamRoutes has every route mounted by RouteStream, with do-nothing
+// handlers, so that requests can be matched against them before routing
+var streamRoutes = chi.NewMux()
+
+// IsStreamRequest reports whether r asked for a Server-Sent Events stream.
+// Streaming routes refuse anything else.
+func IsStreamRequest(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// IsStreamRoute reports whether r is for a route mounted by RouteStream.
+//
+// main.go exempts these from the global request timeout. It goes by the
+// route rather than the Accept header, which any client could send to any
+// route to get out of the timeout.
+func IsStreamRoute(r *http.Request) bool {
+	return streamRoutes.Match(chi.NewRouteContext(), r.Method, r.URL.Path)
+}
+
+// RouteStream mounts a GET route whose handler streams Server-Sent Events,
+// which uapi cannot express since its handlers return one buffered response.
+//
+// route is declared exactly as it would be for uapi.Route (Handler is
+// ignored), and goes through the same docs generation, sanity checks and
+// Authorize call, so a streaming route is documented and authorized like any
+// other.
+func RouteStream(route uapi.Route, handler StreamHandler, r *chi.Mux) {
+	if route.Method != uapi.GET {
+		panic("Streaming routes must be GET: " + route.String())
+	}
+
+	if err := uapi.State.BaseSanityCheck(route); err != nil {
+		panic("Base sanity check failed: " + err.Error())
+	}
+
+	docsObj := route.Docs()
+	docsObj.Pattern = route.Pattern
+	docsObj.OpId = route.OpId
+	docsObj.Method = route.Method.String()
+	docsObj.Tags = []string{uapi.State.InitData.Tag}
+	docsObj.AuthType = []string{}
+
+	for _, auth := range route.Auth {
+		docsObj.AuthType = append(docsObj.AuthType, uapi.State.AuthTypeMap[auth.Type])
+	}
+
+	docs.Route(docsObj)
+
+	streamRoutes.Get(route.Pattern, func(http.ResponseWriter, *http.Request) {})
+
+	r.Get(route.Pattern, func(w http.ResponseWriter, req *http.Request) {
+		if !IsStreamRequest(req) {
+			writeResponse(w, uapi.HttpResponse{
+				Status: http.StatusNotAcceptable,
+				Json:   DefaultResponder{}.New("This endpoint only serves `Accept: text/event-stream`", nil),
+			})
+			return
+		}
+
+		authData, hresp, ok := Authorize(route, req)
+
+		if !ok {
+			writeResponse(w, hresp)
+			return
+		}
+
+		handler(uapi.RouteData{Context: req.Context(), Auth: authData}, w, req)
+	})
+}
+
+// writeResponse writes a uapi.HttpResponse the way uapi itself would, for
+// the failures a streaming route returns before it starts streaming.
+func writeResponse(w http.ResponseWriter, hresp uapi.HttpResponse) {
+	for k, v := range hresp.Headers {
+		w.Header().Set(k, v)
+	}
+
+	if hresp.Json != nil {
+		bytes, err := jsonimpl.Marshal(hresp.Json)
+
+		if err != nil {
+			state.Logger.Error("Failed to marshal stream route response", zap.Error(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		hresp.Bytes = bytes
+	}
+
+	if hresp.Status == 0 {
+		hresp.Status = http.StatusOK
+	}
+
+	w.WriteHeader(hresp.Status)
+	w.Write(hresp.Bytes)
+	w.Write([]byte(hresp.Data))
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"popplio/state"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SessionTokenPrefix starts every session token, followed by its target type
+// and an underscore, so that leaked tokens can be recognised by secret
+// scanners. Tokens issued before this have no prefix.
+const SessionTokenPrefix = "pop_"
+
+// sessionTokenBytes is how many random bytes a session token has
+const sessionTokenBytes = 48
+
+// NewSessionToken returns a new session token for an entity of targetType,
+// such as pop_bot_... Only its hash, from HashSessionToken, may be stored.
+func NewSessionToken(targetType string) (string, error) {
+	b := make([]byte, sessionTokenBytes)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return SessionTokenPrefix + targetType + "_" + hex.EncodeToString(b), nil
+}
+
+// HashSessionToken returns the hash of a session token that api_sessions
+// stores in place of the token. It is keyed with meta.session_hash_key, so
+// that the hashes in a leaked database can't be checked against guesses
+// without the key too.
+func HashSessionToken(token string) string {
+	mac := hmac.New(sha256.New, []byte(state.Config.Meta.SessionHashKey))
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// session is what authorizing a request needs of an API session
+type session struct {
+	ID         string    `json:"id"`
+	TargetID   string    `json:"target_id"`
+	TargetType string    `json:"target_type"`
+	PermLimits []string  `json:"perm_limits"`
+	Expiry     time.Time `json:"expiry"`
+
+	// Banned is whether a user session's user is banned
+	Banned bool `json:"banned"`
+}
+
+// errSessionNotFound is returned when no unexpired session has a token
+var errSessionNotFound = errors.New("session not found")
+
+// lookupSession finds the unexpired session with a token, from the session
+// cache if it is there.
+func lookupSession(ctx context.Context, token string) (*session, error) {
+	tokenHash := HashSessionToken(token)
+
+	if s := cachedSession(ctx, tokenHash); s != nil {
+		return s, nil
+	}
+
+	s, err := fetchSession(ctx, token, tokenHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cacheSession(ctx, tokenHash, s)
+
+	return s, nil
+}
+
+// fetchSession finds the unexpired session with a token in the database.
+// Sessions created before tokens were hashed still have their token in
+// plaintext, and are moved over to its hash the first time they are used.
+func fetchSession(ctx context.Context, token, tokenHash string) (*session, error) {
+	var s session
+	var storedHash string
+
+	err := state.Pool.QueryRow(
+		ctx,
+		"SELECT id, target_id, target_type, perm_limits, expiry, token_hash FROM api_sessions WHERE token_hash = $1 AND expiry >= NOW()",
+		tokenHash,
+	).Scan(&s.ID, &s.TargetID, &s.TargetType, &s.PermLimits, &s.Expiry, &storedHash)
+
+	switch {
+	case err == nil:
+		// The hash was matched by an index, so check it again in constant time
+		if subtle.ConstantTimeCompare([]byte(storedHash), []byte(tokenHash)) != 1 {
+			return nil, errSessionNotFound
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, err
+	case strings.HasPrefix(token, SessionTokenPrefix):
+		// Prefixed tokens have only ever been stored hashed
+		return nil, errSessionNotFound
+	default:
+		err = state.Pool.QueryRow(
+			ctx,
+			"UPDATE api_sessions SET token_hash = $1, token = NULL WHERE token = $2 AND token_hash IS NULL AND expiry >= NOW() RETURNING id, target_id, target_type, perm_limits, expiry",
+			tokenHash,
+			token,
+		).Scan(&s.ID, &s.TargetID, &s.TargetType, &s.PermLimits, &s.Expiry)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errSessionNotFound
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.TargetType == TargetTypeUser {
+		err = state.Pool.QueryRow(ctx, "SELECT banned FROM users WHERE user_id = $1", s.TargetID).Scan(&s.Banned)
+
+		if err != nil {
+			return nil, fmt.Errorf("error fetching user associated with session: %w", err)
+		}
+	}
+
+	return &s, nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"popplio/audit"
 	"popplio/constants"
 	"popplio/perms"
 	"popplio/state"
@@ -13,7 +14,6 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/infinitybotlist/eureka/uapi"
-	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
@@ -110,14 +110,6 @@ func Authorize(r uapi.Route, req *http.Request) (uapi.AuthData, uapi.HttpRespons
 
 	authData := uapi.AuthData{}
 
-	// Before doing anything else, delete expired/old auths first
-	_, err := state.Pool.Exec(state.Context, "DELETE FROM api_sessions WHERE expiry < NOW()")
-
-	if err != nil {
-		state.Logger.Error("Failed to delete expired web API tokens [db delete]", zap.Error(err))
-		return uapi.AuthData{}, uapi.DefaultResponse(http.StatusInternalServerError), false
-	}
-
 	// Get the prefix from the auth header, if any, by splitNing it into 2 parts
 	// The first part is the prefix, the second part is the token (if len == 2)
 	// Otherwise, prefix is empty
@@ -129,15 +121,11 @@ func Authorize(r uapi.Route, req *http.Request) (uapi.AuthData, uapi.HttpRespons
 		authHeader = parts[1]
 	}
 
-	// Check if the anything at all exists with said API token
-	var sessId string
-	var targetId string
-	var targetType string
-	var permLimits []string
-
-	err = state.Pool.QueryRow(state.Context, "SELECT id, target_id, target_type, perm_limits FROM api_sessions WHERE token = $1", authHeader).Scan(&sessId, &targetId, &targetType, &permLimits)
+	// Check if the anything at all exists with said API token. Expired
+	// sessions are not found, and are deleted by the api_session_expiry task
+	sess, err := lookupSession(state.Context, authHeader)
 
-	if errors.Is(err, pgx.ErrNoRows) {
+	if errors.Is(err, errSessionNotFound) {
 		return uapi.AuthData{}, uapi.HttpResponse{
 			Status: http.StatusUnauthorized,
 			Json:   types.ApiError{Message: "Invalid session token"},
@@ -152,6 +140,11 @@ func Authorize(r uapi.Route, req *http.Request) (uapi.AuthData, uapi.HttpRespons
 		return uapi.AuthData{}, uapi.DefaultResponse(http.StatusInternalServerError), false
 	}
 
+	var sessId = sess.ID
+	var targetId = sess.TargetID
+	var targetType = sess.TargetType
+	var permLimits = sess.PermLimits
+
 	if len(permLimits) == 0 {
 		permLimits = []string{}
 	}
@@ -181,20 +174,11 @@ func Authorize(r uapi.Route, req *http.Request) (uapi.AuthData, uapi.HttpRespons
 
 		switch auth.Type {
 		case TargetTypeUser:
-			var banned bool
-
-			err := state.Pool.QueryRow(state.Context, "SELECT banned FROM users WHERE user_id = $1", targetId).Scan(&banned)
-
-			if err != nil {
-				state.Logger.Error("Failed to fetch user associated with session [db fetch]", zap.Error(err), zap.String("userID", targetId))
-				return uapi.AuthData{}, uapi.DefaultResponse(http.StatusInternalServerError), false
-			}
-
 			authData = uapi.AuthData{
 				TargetType: TargetTypeUser,
 				ID:         targetId,
 				Authorized: true,
-				Banned:     banned,
+				Banned:     sess.Banned,
 			}
 		case TargetTypeBot:
 			var count int64
@@ -371,6 +355,9 @@ func Authorize(r uapi.Route, req *http.Request) (uapi.AuthData, uapi.HttpRespons
 					Json:   types.ApiError{Message: "Entity permission checks failed: " + err.Error()},
 				}, false
 			}
+
+			// Only snapshots the entity on mutating requests, see audit.Middleware
+			audit.Begin(req, r.OpId, authData, targetTypeOfEntity, targetIdOfEntity)
 		}
 	}
 
@@ -3,6 +3,7 @@ package apps
 import (
 	"errors"
 	"fmt"
+	"popplio/analytics"
 	"popplio/perms"
 	"popplio/state"
 	"popplio/teams"
@@ -135,13 +136,18 @@ func extraLogicCert(d uapi.RouteData, p types.Position, answers map[string]strin
 
 	// Now check server count and unique clicks
 	var serverCount int64
-	var uniqueClicks int64
-	err = state.Pool.QueryRow(d.Context, "SELECT servers, cardinality(unique_clicks) AS unique_clicks FROM bots WHERE bot_id = $1", botID).Scan(&serverCount, &uniqueClicks)
+	err = state.Pool.QueryRow(d.Context, "SELECT servers FROM bots WHERE bot_id = $1", botID).Scan(&serverCount)
 
 	if err != nil {
 		return fmt.Errorf("error getting server count: %w", err)
 	}
 
+	uniqueClicks, err := analytics.UniqueViews(d.Context, "bot", botID)
+
+	if err != nil {
+		return fmt.Errorf("error getting unique clicks: %w", err)
+	}
+
 	if serverCount < 100 {
 		return errors.New("bot does not have enough servers to be certified: has " + fmt.Sprint(serverCount) + ", needs 100")
 	}
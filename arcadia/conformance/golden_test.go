@@ -60,6 +60,10 @@ func TestGoldenPanelStrings(t *testing.T) {
 		"You do not have permission to update blog entries [manage_blog]",
 		"You do not have permission to delete blog entries [manage_blog]",
 
+		// Announcements.
+		"You do not have permission to create announcements [manage_announcements]",
+		"You do not have permission to cancel announcements [manage_announcements]",
+
 		// Staff positions and members.
 		"Positions have the same index",
 		"Either 'a' or 'b' is lower than the lowest index of the member",
@@ -39,6 +39,10 @@ func (s *Server) dispatch(ctx context.Context, req *types.PanelQuery) (response,
 		return s.updateChangelog(ctx, req.UpdateChangelog)
 	case req.UpdateBlog != nil:
 		return s.updateBlog(ctx, req.UpdateBlog)
+	case req.UpdateAnnouncements != nil:
+		return s.updateAnnouncements(ctx, req.UpdateAnnouncements)
+	case req.UpdateTags != nil:
+		return s.updateTags(ctx, req.UpdateTags)
 	case req.UpdateStaffPositions != nil:
 		return s.updateStaffPositions(ctx, req.UpdateStaffPositions)
 	case req.UpdateStaffMembers != nil:
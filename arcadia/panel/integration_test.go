@@ -335,6 +335,22 @@ func TestPermissionGates(t *testing.T) {
 			},
 			wantDenied: "You do not have permission to delete blog entries [manage_blog]",
 		},
+		{
+			name: "UpdateAnnouncements/Cancel",
+			perm: "manage_announcements",
+			body: func(tok string) string {
+				return fmt.Sprintf(`{"UpdateAnnouncements":{"login_token":%q,"action":{"Cancel":{"id":"00000000-0000-0000-0000-000000000000"}}}}`, tok)
+			},
+			wantDenied: "You do not have permission to cancel announcements [manage_announcements]",
+		},
+		{
+			name: "UpdateTags/Merge",
+			perm: "manage_tags",
+			body: func(tok string) string {
+				return fmt.Sprintf(`{"UpdateTags":{"login_token":%q,"action":{"Merge":{"from":"nope","into":"nope-either"}}}}`, tok)
+			},
+			wantDenied: "You do not have permission to manage tags [manage_tags]",
+		},
 	}
 
 	for _, tt := range cases {
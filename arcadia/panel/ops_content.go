@@ -10,8 +10,10 @@ import (
 
 	"popplio/arcadia/impls"
 	"popplio/arcadia/types"
+	"popplio/notifications/broadcast"
 	"popplio/perms"
 	"popplio/state"
+	"popplio/taxonomy"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -432,3 +434,244 @@ func blogExists(ctx context.Context, itag uuid.UUID) (bool, error) {
 
 	return count > 0, nil
 }
+
+type announcementRow struct {
+	ID             string    `db:"id"`
+	Author         string    `db:"author"`
+	Title          string    `db:"title"`
+	Content        string    `db:"content"`
+	Segment        string    `db:"segment"`
+	Target         *string   `db:"target"`
+	AlertType      string    `db:"alert_type"`
+	URL            *string   `db:"url"`
+	ScheduledFor   time.Time `db:"scheduled_for"`
+	Status         string    `db:"status"`
+	DeliveredCount int64     `db:"delivered_count"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+func (s *Server) updateAnnouncements(ctx context.Context, q *types.QUpdateAnnouncements) (response, error) {
+	authData, err := checkAuth(ctx, q.LoginToken)
+
+	if err != nil {
+		return response{}, err
+	}
+
+	userPerms, err := resolvedPerms(ctx, authData.UserID)
+
+	if err != nil {
+		return response{}, err
+	}
+
+	switch {
+	case q.Action.List != nil:
+		// No permission check, delivered announcements are public anyway.
+		rows, err := state.Pool.Query(ctx,
+			"SELECT id::text AS id, author, title, content, segment, target, alert_type, url, scheduled_for, status, delivered_count, created_at FROM announcements ORDER BY scheduled_for DESC")
+
+		if err != nil {
+			return response{}, newError(err)
+		}
+
+		announcementRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[announcementRow])
+
+		if err != nil {
+			return response{}, newError(err)
+		}
+
+		entries := make([]types.AnnouncementEntry, 0, len(announcementRows))
+
+		for _, row := range announcementRows {
+			entries = append(entries, types.AnnouncementEntry{
+				ID:             row.ID,
+				Author:         row.Author,
+				Title:          row.Title,
+				Content:        row.Content,
+				Segment:        row.Segment,
+				Target:         row.Target,
+				AlertType:      row.AlertType,
+				URL:            row.URL,
+				ScheduledFor:   types.NewTimestamp(row.ScheduledFor),
+				Status:         row.Status,
+				DeliveredCount: row.DeliveredCount,
+				CreatedAt:      types.NewTimestamp(row.CreatedAt),
+			})
+		}
+
+		return writeJSON(http.StatusOK, entries), nil
+	case q.Action.Create != nil:
+		if !userPerms.Has(perms.StaffManageAnnouncements) {
+			return writeText(http.StatusForbidden, "You do not have permission to create announcements [manage_announcements]"), nil
+		}
+
+		entry := q.Action.Create
+
+		if entry.Title == "" || entry.Content == "" {
+			return writeText(http.StatusBadRequest, "Title and content cannot be empty"), nil
+		}
+
+		if !broadcast.ValidSegment(entry.Segment) {
+			return writeText(http.StatusBadRequest, "Invalid segment"), nil
+		}
+
+		switch entry.AlertType {
+		case "success", "error", "info", "warning":
+		default:
+			return writeText(http.StatusBadRequest, "Alert type must be one of success, error, info or warning"), nil
+		}
+
+		if entry.URL != nil && !strings.HasPrefix(*entry.URL, "https://") {
+			return writeText(http.StatusBadRequest, "URL must start with https://"), nil
+		}
+
+		var target *string
+
+		if broadcast.SegmentNeedsTarget(entry.Segment) {
+			if entry.Target == nil {
+				return writeText(http.StatusBadRequest, "This segment needs a target team"), nil
+			}
+
+			var count int64
+
+			if err := state.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM teams WHERE id::text = $1", *entry.Target).Scan(&count); err != nil {
+				return response{}, newError(err)
+			}
+
+			if count == 0 {
+				return writeText(http.StatusBadRequest, "Team does not exist"), nil
+			}
+
+			target = entry.Target
+		}
+
+		scheduledFor := time.Now()
+
+		if entry.ScheduledFor != nil {
+			scheduledFor = entry.ScheduledFor.Time
+		}
+
+		var id string
+
+		err := state.Pool.QueryRow(ctx,
+			"INSERT INTO announcements (author, title, content, segment, target, alert_type, url, scheduled_for) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id::text",
+			authData.UserID, entry.Title, entry.Content, entry.Segment, target, entry.AlertType, entry.URL, scheduledFor).Scan(&id)
+
+		if err != nil {
+			return response{}, newError(err)
+		}
+
+		return writeJSON(http.StatusOK, map[string]string{"id": id}), nil
+	case q.Action.Cancel != nil:
+		if !userPerms.Has(perms.StaffManageAnnouncements) {
+			return writeText(http.StatusForbidden, "You do not have permission to cancel announcements [manage_announcements]"), nil
+		}
+
+		id, err := uuid.Parse(q.Action.Cancel.ID)
+
+		if err != nil {
+			return response{}, newError(err)
+		}
+
+		// Alerts already sent stay sent; this only stops the rest.
+		tag, err := state.Pool.Exec(ctx,
+			"UPDATE announcements SET status = 'cancelled', modified_date = NOW() WHERE id = $1 AND status IN ('pending', 'delivering')", id)
+
+		if err != nil {
+			return response{}, newError(err)
+		}
+
+		if tag.RowsAffected() == 0 {
+			return writeText(http.StatusBadRequest, "Announcement does not exist or has already been delivered"), nil
+		}
+
+		return writeNoContent(), nil
+	default:
+		return response{}, errStatus(http.StatusBadRequest, "No announcement action was specified")
+	}
+}
+
+// tagResult turns the error of a tag registry operation into the response
+func tagResult(ok response, err error) (response, error) {
+	if errors.Is(err, taxonomy.ErrUser) {
+		return writeText(http.StatusBadRequest, err.Error()), nil
+	}
+
+	if err != nil {
+		return response{}, newError(err)
+	}
+
+	return ok, nil
+}
+
+func tagEdit(e *types.TagEdit) taxonomy.Edit {
+	return taxonomy.Edit{
+		ID:          e.ID,
+		Name:        e.Name,
+		Description: e.Description,
+		Icon:        e.Icon,
+		TargetTypes: e.TargetTypes,
+		Aliases:     e.Aliases,
+	}
+}
+
+func (s *Server) updateTags(ctx context.Context, q *types.QUpdateTags) (response, error) {
+	authData, err := checkAuth(ctx, q.LoginToken)
+
+	if err != nil {
+		return response{}, err
+	}
+
+	userPerms, err := resolvedPerms(ctx, authData.UserID)
+
+	if err != nil {
+		return response{}, err
+	}
+
+	if q.Action.List == nil && !userPerms.Has(perms.StaffManageTags) {
+		return writeText(http.StatusForbidden, "You do not have permission to manage tags [manage_tags]"), nil
+	}
+
+	switch {
+	case q.Action.List != nil:
+		// No permission check.
+		tags, err := taxonomy.List(ctx)
+
+		if err != nil {
+			return response{}, newError(err)
+		}
+
+		entries := make([]types.TagEntry, 0, len(tags))
+
+		for _, t := range tags {
+			entries = append(entries, types.TagEntry{
+				ID:          t.ID,
+				Name:        t.Name,
+				Description: t.Description,
+				Icon:        t.Icon,
+				TargetTypes: t.TargetTypes,
+				Aliases:     t.Aliases,
+				Retired:     t.Retired,
+				CreatedAt:   types.NewTimestamp(t.CreatedAt),
+				UpdatedAt:   types.NewTimestamp(t.UpdatedAt),
+			})
+		}
+
+		return writeJSON(http.StatusOK, entries), nil
+	case q.Action.Create != nil:
+		id, err := taxonomy.Create(ctx, tagEdit(q.Action.Create))
+		return tagResult(writeJSON(http.StatusOK, types.TagID{ID: id}), err)
+	case q.Action.Edit != nil:
+		return tagResult(writeNoContent(), taxonomy.Update(ctx, tagEdit(q.Action.Edit)))
+	case q.Action.Merge != nil:
+		return tagResult(writeNoContent(), taxonomy.Merge(ctx, q.Action.Merge.From, q.Action.Merge.Into))
+	case q.Action.Rename != nil:
+		id, err := taxonomy.Rename(ctx, q.Action.Rename.ID, q.Action.Rename.Name)
+		return tagResult(writeJSON(http.StatusOK, types.TagID{ID: id}), err)
+	case q.Action.Retire != nil:
+		return tagResult(writeNoContent(), taxonomy.SetRetired(ctx, q.Action.Retire.ID, true))
+	case q.Action.Unretire != nil:
+		return tagResult(writeNoContent(), taxonomy.SetRetired(ctx, q.Action.Unretire.ID, false))
+	default:
+		return response{}, errStatus(http.StatusBadRequest, "No tag action was specified")
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"popplio/arcadia/impls"
 	"popplio/arcadia/types"
+	"popplio/notifications/savedsearches"
 	"popplio/state"
 
 	"github.com/disgoorg/disgo/discord"
@@ -257,6 +258,10 @@ func approve(ctx context.Context, m *types.RPCTargetReason, h Handle) (Success,
 		return Success{}, err
 	}
 
+	if err := savedsearches.Enqueue(ctx, "bot", m.TargetID); err != nil {
+		state.Logger.Error("Failed to queue bot for saved search alerts", zap.Error(err), zap.String("botID", m.TargetID))
+	}
+
 	managers, err := impls.GetEntityManagers(ctx, types.TargetTypeBot, m.TargetID)
 
 	if err != nil {
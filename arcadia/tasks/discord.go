@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"popplio/api"
 	"popplio/arcadia/dclient"
 	"popplio/arcadia/impls"
 	"popplio/state"
@@ -90,6 +91,9 @@ func BansSync(ctx context.Context) error {
 			}
 		}
 
+		// Cached sessions carry whether their user is banned
+		api.InvalidateSessionCache(ctx, api.TargetTypeUser, userID)
+
 		title := "User Unban"
 		description := fmt.Sprintf("User %s was unbanned", userID)
 		colour := impls.ColourBlurple
@@ -269,3 +269,189 @@ type BlogPost struct {
 	Draft       bool      `json:"draft"`
 	Tags        []string  `json:"tags"`
 }
+
+// AnnouncementAction is the union of staff announcement operations.
+type AnnouncementAction struct {
+	List   *Unit
+	Create *AnnouncementCreate
+	Cancel *AnnouncementCancel
+}
+
+type AnnouncementCreate struct {
+	Title     string  `json:"title"`
+	Content   string  `json:"content"`
+	Segment   string  `json:"segment"`
+	Target    *string `json:"target"`
+	AlertType string  `json:"alert_type"`
+	URL       *string `json:"url"`
+	// Null sends it on the next delivery run.
+	ScheduledFor OptTimestamp `json:"scheduled_for"`
+}
+
+type AnnouncementCancel struct {
+	ID string `json:"id"`
+}
+
+func (a *AnnouncementAction) UnmarshalJSON(data []byte) error {
+	*a = AnnouncementAction{}
+
+	name, payload, err := decodeUnion(data)
+
+	if err != nil {
+		return fmt.Errorf("AnnouncementAction: %w", err)
+	}
+
+	switch name {
+	case "List":
+		a.List = unitSet()
+	case "Create":
+		a.Create = &AnnouncementCreate{}
+		return decodeVariant("AnnouncementAction", name, payload, a.Create)
+	case "Cancel":
+		a.Cancel = &AnnouncementCancel{}
+		return decodeVariant("AnnouncementAction", name, payload, a.Cancel)
+	default:
+		return errUnknownVariant("AnnouncementAction", name)
+	}
+
+	return expectUnit("AnnouncementAction", name, payload)
+}
+
+func (a AnnouncementAction) MarshalJSON() ([]byte, error) {
+	switch {
+	case a.List != nil:
+		return encodeUnit("List")
+	case a.Create != nil:
+		return encodeVariant("Create", a.Create)
+	case a.Cancel != nil:
+		return encodeVariant("Cancel", a.Cancel)
+	default:
+		return nil, fmt.Errorf("AnnouncementAction: no variant set")
+	}
+}
+
+type AnnouncementEntry struct {
+	ID             string    `json:"id"`
+	Author         string    `json:"author"`
+	Title          string    `json:"title"`
+	Content        string    `json:"content"`
+	Segment        string    `json:"segment"`
+	Target         *string   `json:"target"`
+	AlertType      string    `json:"alert_type"`
+	URL            *string   `json:"url"`
+	ScheduledFor   Timestamp `json:"scheduled_for"`
+	Status         string    `json:"status"`
+	DeliveredCount int64     `json:"delivered_count"`
+	CreatedAt      Timestamp `json:"created_at"`
+}
+
+// TagAction is the union of tag registry operations.
+type TagAction struct {
+	List     *Unit
+	Create   *TagEdit
+	Edit     *TagEdit
+	Merge    *TagMerge
+	Rename   *TagRename
+	Retire   *TagID
+	Unretire *TagID
+}
+
+// TagEdit is the tag payload accepted by Create and Edit. On Create, an empty
+// ID defaults to the slug of the name; on Edit it selects the tag. An empty
+// target_types allows every target type, and aliases replace the existing
+// ones.
+type TagEdit struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Icon        string   `json:"icon"`
+	TargetTypes []string `json:"target_types"`
+	Aliases     []string `json:"aliases"`
+}
+
+// TagMerge retags every entity tagged From with Into, leaving From as an
+// alias of Into.
+type TagMerge struct {
+	From string `json:"from"`
+	Into string `json:"into"`
+}
+
+// TagRename changes a tag's name, and its ID to the slug of the new name.
+type TagRename struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type TagID struct {
+	ID string `json:"id"`
+}
+
+func (a *TagAction) UnmarshalJSON(data []byte) error {
+	*a = TagAction{}
+
+	name, payload, err := decodeUnion(data)
+
+	if err != nil {
+		return fmt.Errorf("TagAction: %w", err)
+	}
+
+	switch name {
+	case "List":
+		a.List = unitSet()
+	case "Create":
+		a.Create = &TagEdit{}
+		return decodeVariant("TagAction", name, payload, a.Create)
+	case "Edit":
+		a.Edit = &TagEdit{}
+		return decodeVariant("TagAction", name, payload, a.Edit)
+	case "Merge":
+		a.Merge = &TagMerge{}
+		return decodeVariant("TagAction", name, payload, a.Merge)
+	case "Rename":
+		a.Rename = &TagRename{}
+		return decodeVariant("TagAction", name, payload, a.Rename)
+	case "Retire":
+		a.Retire = &TagID{}
+		return decodeVariant("TagAction", name, payload, a.Retire)
+	case "Unretire":
+		a.Unretire = &TagID{}
+		return decodeVariant("TagAction", name, payload, a.Unretire)
+	default:
+		return errUnknownVariant("TagAction", name)
+	}
+
+	return expectUnit("TagAction", name, payload)
+}
+
+func (a TagAction) MarshalJSON() ([]byte, error) {
+	switch {
+	case a.List != nil:
+		return encodeUnit("List")
+	case a.Create != nil:
+		return encodeVariant("Create", a.Create)
+	case a.Edit != nil:
+		return encodeVariant("Edit", a.Edit)
+	case a.Merge != nil:
+		return encodeVariant("Merge", a.Merge)
+	case a.Rename != nil:
+		return encodeVariant("Rename", a.Rename)
+	case a.Retire != nil:
+		return encodeVariant("Retire", a.Retire)
+	case a.Unretire != nil:
+		return encodeVariant("Unretire", a.Unretire)
+	default:
+		return nil, fmt.Errorf("TagAction: no variant set")
+	}
+}
+
+type TagEntry struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Icon        string    `json:"icon"`
+	TargetTypes []string  `json:"target_types"`
+	Aliases     []string  `json:"aliases"`
+	Retired     bool      `json:"retired"`
+	CreatedAt   Timestamp `json:"created_at"`
+	UpdatedAt   Timestamp `json:"updated_at"`
+}
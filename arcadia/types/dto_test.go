@@ -21,6 +21,13 @@ func TestActionUnionsRoundTrip(t *testing.T) {
 		{"BlogAction unit", `"ListEntries"`, func() json.Unmarshaler { return &BlogAction{} }},
 		{"BlogAction DeleteEntry", `{"DeleteEntry":{"itag":"x"}}`, func() json.Unmarshaler { return &BlogAction{} }},
 
+		{"AnnouncementAction unit", `"List"`, func() json.Unmarshaler { return &AnnouncementAction{} }},
+		{"AnnouncementAction Cancel", `{"Cancel":{"id":"x"}}`, func() json.Unmarshaler { return &AnnouncementAction{} }},
+
+		{"TagAction unit", `"List"`, func() json.Unmarshaler { return &TagAction{} }},
+		{"TagAction Merge", `{"Merge":{"from":"musica","into":"music"}}`, func() json.Unmarshaler { return &TagAction{} }},
+		{"TagAction Retire", `{"Retire":{"id":"music"}}`, func() json.Unmarshaler { return &TagAction{} }},
+
 		{"ChangelogAction unit", `"ListEntries"`, func() json.Unmarshaler { return &ChangelogAction{} }},
 		{"ChangelogAction DeleteEntry", `{"DeleteEntry":{"version":"1.0"}}`, func() json.Unmarshaler { return &ChangelogAction{} }},
 
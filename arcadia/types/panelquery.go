@@ -17,6 +17,8 @@ type PanelQuery struct {
 	UpdatePartners              *QUpdatePartners
 	UpdateChangelog             *QUpdateChangelog
 	UpdateBlog                  *QUpdateBlog
+	UpdateAnnouncements         *QUpdateAnnouncements
+	UpdateTags                  *QUpdateTags
 	UpdateStaffPositions        *QUpdateStaffPositions
 	UpdateStaffMembers          *QUpdateStaffMembers
 	UpdateStaffDisciplinaryType *QUpdateStaffDisciplinaryType
@@ -81,6 +83,16 @@ type QUpdateBlog struct {
 	Action     BlogAction `json:"action"`
 }
 
+type QUpdateAnnouncements struct {
+	LoginToken string             `json:"login_token"`
+	Action     AnnouncementAction `json:"action"`
+}
+
+type QUpdateTags struct {
+	LoginToken string    `json:"login_token"`
+	Action     TagAction `json:"action"`
+}
+
 type QUpdateStaffPositions struct {
 	LoginToken string              `json:"login_token"`
 	Action     StaffPositionAction `json:"action"`
@@ -177,6 +189,12 @@ func (q *PanelQuery) UnmarshalJSON(data []byte) error {
 	case "UpdateBlog":
 		q.UpdateBlog = &QUpdateBlog{}
 		into = q.UpdateBlog
+	case "UpdateAnnouncements":
+		q.UpdateAnnouncements = &QUpdateAnnouncements{}
+		into = q.UpdateAnnouncements
+	case "UpdateTags":
+		q.UpdateTags = &QUpdateTags{}
+		into = q.UpdateTags
 	case "UpdateStaffPositions":
 		q.UpdateStaffPositions = &QUpdateStaffPositions{}
 		into = q.UpdateStaffPositions
@@ -237,6 +255,10 @@ func (q PanelQuery) MarshalJSON() ([]byte, error) {
 		return encodeVariant("UpdateChangelog", q.UpdateChangelog)
 	case q.UpdateBlog != nil:
 		return encodeVariant("UpdateBlog", q.UpdateBlog)
+	case q.UpdateAnnouncements != nil:
+		return encodeVariant("UpdateAnnouncements", q.UpdateAnnouncements)
+	case q.UpdateTags != nil:
+		return encodeVariant("UpdateTags", q.UpdateTags)
 	case q.UpdateStaffPositions != nil:
 		return encodeVariant("UpdateStaffPositions", q.UpdateStaffPositions)
 	case q.UpdateStaffMembers != nil:
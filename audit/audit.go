@@ -0,0 +1,196 @@
+// Package audit records who changed what on bots, servers, teams and packs.
+//
+// Mutating routes don't record anything themselves. api.Authorize calls Begin
+// once a request has passed an entity permission check, which snapshots the
+// entity as it was, and Middleware snapshots it again once the handler has
+// succeeded and records the fields that differ. Routes that authorize
+// themselves (their own resources, or entities they create) call Begin or
+// Created directly.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"popplio/state"
+
+	"go.uber.org/zap"
+)
+
+// DefaultRetentionDays is how long entries are kept for a target type with no
+// retention set in the config
+const DefaultRetentionDays = 90
+
+// TargetTypes are the entity types changes are recorded for
+var TargetTypes = []string{"bot", "server", "team", "pack"}
+
+// Audited reports whether changes to targetType are recorded
+func Audited(targetType string) bool {
+	for _, t := range TargetTypes {
+		if t == targetType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Entry is one change to an entity. Before is nil when the change created the
+// entity and After is nil when it deleted it.
+type Entry struct {
+	TargetType string
+	TargetID   string
+	ActorType  string
+	ActorID    string
+	Action     string
+	Before     map[string]any
+	After      map[string]any
+}
+
+// Retention is how long entries for targetType are kept
+func Retention(targetType string) time.Duration {
+	var days int
+
+	switch targetType {
+	case "bot":
+		days = state.Config.AuditLog.BotRetentionDays
+	case "server":
+		days = state.Config.AuditLog.ServerRetentionDays
+	case "team":
+		days = state.Config.AuditLog.TeamRetentionDays
+	case "pack":
+		days = state.Config.AuditLog.PackRetentionDays
+	}
+
+	if days <= 0 {
+		days = DefaultRetentionDays
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Diff returns the values in before and after of only the keys that differ
+// between them, recursing into nested objects. A key missing from one side is
+// left out of that side.
+func Diff(before, after map[string]any) (b, a map[string]any) {
+	b, a = map[string]any{}, map[string]any{}
+
+	for k, bv := range before {
+		av, ok := after[k]
+
+		if !ok {
+			b[k] = bv
+			continue
+		}
+
+		bm, bIsMap := bv.(map[string]any)
+		am, aIsMap := av.(map[string]any)
+
+		if bIsMap && aIsMap {
+			db, da := Diff(bm, am)
+
+			if len(db) > 0 {
+				b[k] = db
+			}
+
+			if len(da) > 0 {
+				a[k] = da
+			}
+
+			continue
+		}
+
+		if !reflect.DeepEqual(bv, av) {
+			b[k] = bv
+			a[k] = av
+		}
+	}
+
+	for k, av := range after {
+		if _, ok := before[k]; !ok {
+			a[k] = av
+		}
+	}
+
+	return b, a
+}
+
+// Record saves an entry, keeping only the fields that changed. An entry that
+// changed nothing isn't saved.
+func Record(ctx context.Context, e Entry) error {
+	before, after := e.Before, e.After
+
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before != nil && after != nil:
+		before, after = Diff(before, after)
+
+		if len(before) == 0 && len(after) == 0 {
+			return nil
+		}
+	}
+
+	beforeJSON, err := marshalOrNil(before)
+
+	if err != nil {
+		return err
+	}
+
+	afterJSON, err := marshalOrNil(after)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = state.Pool.Exec(
+		ctx,
+		"INSERT INTO audit_log (target_type, target_id, actor_type, actor_id, action, before, after) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		e.TargetType,
+		e.TargetID,
+		e.ActorType,
+		e.ActorID,
+		e.Action,
+		beforeJSON,
+		afterJSON,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error recording audit log entry: %w", err)
+	}
+
+	return nil
+}
+
+func marshalOrNil(m map[string]any) ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(m)
+}
+
+// Prune deletes entries older than their target type's retention
+func Prune(ctx context.Context) error {
+	for _, targetType := range TargetTypes {
+		tag, err := state.Pool.Exec(
+			ctx,
+			"DELETE FROM audit_log WHERE target_type = $1 AND created_at < $2",
+			targetType,
+			time.Now().Add(-Retention(targetType)),
+		)
+
+		if err != nil {
+			return fmt.Errorf("pruning %s audit log: %w", targetType, err)
+		}
+
+		if tag.RowsAffected() > 0 {
+			state.Logger.Info("Pruned audit log", zap.String("targetType", targetType), zap.Int64("deleted", tag.RowsAffected()))
+		}
+	}
+
+	return nil
+}
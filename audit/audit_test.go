@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := map[string]any{
+		"short":  "old",
+		"nsfw":   false,
+		"tags":   []any{"a", "b"},
+		"vanity": "same",
+		"webhooks": map[string]any{
+			"w1": map[string]any{"name": "hook", "url": "https://a"},
+			"w2": map[string]any{"name": "gone"},
+		},
+	}
+
+	after := map[string]any{
+		"short":  "new",
+		"nsfw":   false,
+		"tags":   []any{"a", "b"},
+		"vanity": "same",
+		"webhooks": map[string]any{
+			"w1": map[string]any{"name": "hook", "url": "https://b"},
+			"w3": map[string]any{"name": "added"},
+		},
+	}
+
+	b, a := Diff(before, after)
+
+	wantB := map[string]any{
+		"short": "old",
+		"webhooks": map[string]any{
+			"w1": map[string]any{"url": "https://a"},
+			"w2": map[string]any{"name": "gone"},
+		},
+	}
+
+	wantA := map[string]any{
+		"short": "new",
+		"webhooks": map[string]any{
+			"w1": map[string]any{"url": "https://b"},
+			"w3": map[string]any{"name": "added"},
+		},
+	}
+
+	if !reflect.DeepEqual(b, wantB) {
+		t.Errorf("before = %v, want %v", b, wantB)
+	}
+
+	if !reflect.DeepEqual(a, wantA) {
+		t.Errorf("after = %v, want %v", a, wantA)
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	m := map[string]any{"name": "x", "roles": map[string]any{"r": map[string]any{"position": 1.0}}}
+
+	b, a := Diff(m, m)
+
+	if len(b) != 0 || len(a) != 0 {
+		t.Errorf("Diff of equal maps = %v, %v, want empty", b, a)
+	}
+}
+
+func TestDiffTypeChange(t *testing.T) {
+	b, a := Diff(map[string]any{"vanity": nil}, map[string]any{"vanity": map[string]any{"code": "x"}})
+
+	if !reflect.DeepEqual(b, map[string]any{"vanity": nil}) || !reflect.DeepEqual(a, map[string]any{"vanity": map[string]any{"code": "x"}}) {
+		t.Errorf("Diff = %v, %v", b, a)
+	}
+}
@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"popplio/state"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+)
+
+type pendingKey struct{}
+
+// pending is the entries a request has begun, waiting on it to succeed
+type pending struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (p *pending) add(e Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, other := range p.entries {
+		if other.TargetType == e.TargetType && other.TargetID == e.TargetID {
+			return
+		}
+	}
+
+	p.entries = append(p.entries, e)
+}
+
+func (p *pending) has(targetType, targetID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, e := range p.entries {
+		if e.TargetType == targetType && e.TargetID == targetID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func pendingFrom(r *http.Request) *pending {
+	p, _ := r.Context().Value(pendingKey{}).(*pending)
+	return p
+}
+
+// Begin snapshots an entity a request is about to change, to be recorded as
+// action by actor if the request succeeds. It does nothing for requests
+// Middleware doesn't handle, for entities that aren't audited, and for an
+// entity the request has already begun.
+func Begin(r *http.Request, action string, actor uapi.AuthData, targetType, targetID string) {
+	p := pendingFrom(r)
+
+	if p == nil || !Audited(targetType) || p.has(targetType, targetID) {
+		return
+	}
+
+	before, err := Snapshot(r.Context(), targetType, targetID)
+
+	if err != nil {
+		state.Logger.Error("Failed to snapshot entity for audit log", zap.Error(err), zap.String("action", action))
+		return
+	}
+
+	p.add(Entry{
+		TargetType: targetType,
+		TargetID:   targetID,
+		ActorType:  actor.TargetType,
+		ActorID:    actor.ID,
+		Action:     action,
+		Before:     before,
+	})
+}
+
+// Created is Begin for an entity the request has just created
+func Created(r *http.Request, action string, actor uapi.AuthData, targetType, targetID string) {
+	p := pendingFrom(r)
+
+	if p == nil || !Audited(targetType) {
+		return
+	}
+
+	p.add(Entry{
+		TargetType: targetType,
+		TargetID:   targetID,
+		ActorType:  actor.TargetType,
+		ActorID:    actor.ID,
+		Action:     action,
+	})
+}
+
+// Middleware records the entries begun by a mutating request once it has
+// succeeded, with the entities as they are after it
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		p := &pending{}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), pendingKey{}, p)))
+
+		if ww.Status() >= 400 {
+			return
+		}
+
+		p.mu.Lock()
+		entries := p.entries
+		p.mu.Unlock()
+
+		if len(entries) == 0 {
+			return
+		}
+
+		ctx := context.WithoutCancel(r.Context())
+
+		go func() {
+			for _, e := range entries {
+				after, err := Snapshot(ctx, e.TargetType, e.TargetID)
+
+				if err != nil {
+					state.Logger.Error("Failed to snapshot entity for audit log", zap.Error(err), zap.String("action", e.Action))
+					continue
+				}
+
+				e.After = after
+
+				if err := Record(ctx, e); err != nil {
+					state.Logger.Error("Failed to record audit log entry", zap.Error(err), zap.String("action", e.Action))
+				}
+			}
+		}()
+	})
+}
@@ -0,0 +1,167 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"popplio/state"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// volatileColumns are left out of snapshots. They are counters, caches and
+// stats the platform updates on its own, or secrets, none of which are a
+// change anyone made.
+var volatileColumns = []string{
+	"api_token",
+	"approximate_votes",
+	"cache_server_uninvitable",
+	"claimed_by",
+	"clicks",
+	"emojis",
+	"emojis_synced_at",
+	"invite_clicks",
+	"last_claimed",
+	"last_japi_update",
+	"last_stats_post",
+	"online_members",
+	"search_commands",
+	"search_name",
+	"search_vector",
+	"servers",
+	"shard_list",
+	"shards",
+	"stickers",
+	"total_members",
+	"total_uptime",
+	"unique_clicks_legacy",
+	"updated_at",
+	"uptime",
+	"uptime_last_checked",
+	"users",
+}
+
+// packVolatileColumns are the same for packs, whose servers column is the
+// servers in the pack rather than a stat
+var packVolatileColumns = []string{"approximate_votes", "updated_at"}
+
+// The webhook URL and secret themselves are never stored, as Discord webhook
+// URLs carry their token, only fingerprints of them so that changing either
+// still shows up
+const webhooksQuery = `COALESCE((
+	SELECT jsonb_object_agg(w.id::text, jsonb_build_object(
+		'name', w.name,
+		'url_fingerprint', left(encode(sha256(convert_to(w.url, 'UTF8')), 'hex'), 12),
+		'simple_auth', w.simple_auth,
+		'hmac_auth', w.hmac_auth,
+		'event_whitelist', w.event_whitelist,
+		'secret_fingerprint', left(encode(sha256(convert_to(w.secret, 'UTF8')), 'hex'), 12)
+	))
+	FROM webhooks w WHERE w.target_id = $1 AND w.target_type = $3
+), '{}'::jsonb)`
+
+const sessionsQuery = `COALESCE((
+	SELECT jsonb_object_agg(s.id::text, jsonb_build_object(
+		'name', s.name,
+		'type', s.type,
+		'perm_limits', s.perm_limits,
+		'expiry', s.expiry
+	))
+	FROM api_sessions s WHERE s.target_id = $1 AND s.target_type = $3
+), '{}'::jsonb)`
+
+const vanityQuery = `(SELECT v.code FROM vanity v WHERE v.target_id = $1 AND v.target_type = $3)`
+
+const teamQuery = `, 'members', COALESCE((
+	SELECT jsonb_object_agg(tm.user_id, jsonb_build_object(
+		'flags', tm.flags,
+		'roles', tm.roles,
+		'mentionable', tm.mentionable,
+		'data_holder', tm.data_holder
+	))
+	FROM team_members tm WHERE tm.team_id = e.id
+), '{}'::jsonb), 'roles', COALESCE((
+	SELECT jsonb_object_agg(tr.id::text, jsonb_build_object(
+		'name', tr.name,
+		'color', tr.color,
+		'position', tr.position,
+		'perms', tr.perms
+	))
+	FROM team_roles tr WHERE tr.team_id = e.id
+), '{}'::jsonb), 'invites', COALESCE((
+	SELECT jsonb_object_agg(ti.id::text, jsonb_build_object(
+		'user_id', ti.user_id,
+		'link', ti.code IS NOT NULL,
+		'flags', ti.flags,
+		'max_uses', ti.max_uses,
+		'expires_at', ti.expires_at
+	))
+	FROM team_invites ti WHERE ti.team_id = e.id
+), '{}'::jsonb)`
+
+// Packs have no webhooks, sessions or vanity, but do have collaborators and
+// notes on their items
+const packQuery = `SELECT (to_jsonb(e) - $2::text[]) || jsonb_build_object(
+	'collaborators', COALESCE((
+		SELECT jsonb_agg(pc.user_id ORDER BY pc.user_id)
+		FROM pack_collaborators pc WHERE pc.pack_url = e.url
+	), '[]'::jsonb),
+	'notes', COALESCE((
+		SELECT jsonb_object_agg(pn.target_type || '/' || pn.target_id, pn.note)
+		FROM pack_item_notes pn WHERE pn.pack_url = e.url
+	), '{}'::jsonb)
+) FROM packs e WHERE e.url = $1`
+
+func entityQuery(table, idColumn, extra string) string {
+	return `SELECT (to_jsonb(e) - $2::text[]) || jsonb_build_object(
+		'webhooks', ` + webhooksQuery + `,
+		'sessions', ` + sessionsQuery + `,
+		'vanity', ` + vanityQuery + extra + `
+	) FROM ` + table + ` e WHERE e.` + idColumn + `::text = $1`
+}
+
+var snapshotQueries = map[string]string{
+	"bot":    entityQuery("bots", "bot_id", ""),
+	"server": entityQuery("servers", "server_id", ""),
+	"team":   entityQuery("teams", "id", teamQuery),
+	"pack":   packQuery,
+}
+
+// Snapshot returns an entity as it is now: its row, less volatile columns,
+// along with its webhooks, API sessions and vanity, and for teams their
+// members, roles and invites. Packs have their collaborators and notes
+// instead. It returns nil if the entity doesn't exist.
+func Snapshot(ctx context.Context, targetType, targetID string) (map[string]any, error) {
+	sql, ok := snapshotQueries[targetType]
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported target type %q", targetType)
+	}
+
+	args := []any{targetID, volatileColumns, targetType}
+
+	if targetType == "pack" {
+		args = []any{targetID, packVolatileColumns}
+	}
+
+	var raw []byte
+	err := state.Pool.QueryRow(ctx, sql, args...).Scan(&raw)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error snapshotting %s %s: %w", targetType, targetID, err)
+	}
+
+	var snap map[string]any
+
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return nil, err
+	}
+
+	return snap, nil
+}
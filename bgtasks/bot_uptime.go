@@ -5,8 +5,8 @@ import (
 	"fmt"
 
 	"popplio/state"
+	"popplio/uptime"
 
-	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/snowflake/v2"
 	"go.uber.org/zap"
 )
@@ -75,6 +75,12 @@ func BotUptimeCheck(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("botID=%s: updating uptime: %w", botID, err)
 		}
+
+		// Catches transitions the gateway listener missed, e.g. while
+		// reconnecting
+		if err := uptime.Observe(ctx, botID, online); err != nil {
+			return fmt.Errorf("botID=%s: recording presence: %w", botID, err)
+		}
 	}
 
 	return nil
@@ -90,5 +96,5 @@ func isOnline(guildID, userID snowflake.ID) bool {
 		return false
 	}
 
-	return presence.Status != discord.OnlineStatusOffline && presence.Status != discord.OnlineStatusInvisible
+	return uptime.IsOnline(presence.Status)
 }
@@ -10,7 +10,18 @@ import (
 	"sync"
 	"time"
 
+	"popplio/analytics"
+	"popplio/api"
+	"popplio/audit"
+	"popplio/notifications"
+	"popplio/notifications/broadcast"
+	"popplio/notifications/savedsearches"
+	"popplio/promotions"
 	"popplio/state"
+	"popplio/statshistory"
+	"popplio/teams"
+	"popplio/transfers"
+	"popplio/uptime"
 
 	"go.uber.org/zap"
 )
@@ -34,6 +45,90 @@ func All() []Task {
 			Interval:    5 * time.Minute,
 			Run:         BotUptimeCheck,
 		},
+		{
+			Name:        "bot_offline_alerts",
+			Description: "Alerting owners who opted in when their bot has been offline too long, and when it recovers",
+			Enabled:     true,
+			Interval:    1 * time.Minute,
+			Run:         uptime.AlertOffline,
+		},
+		{
+			Name:        "notification_dm_flush",
+			Description: "Sending queued alerts to users who opted in to Discord DM notifications",
+			Enabled:     true,
+			Interval:    1 * time.Minute,
+			Run:         notifications.FlushDMQueue,
+		},
+		{
+			Name:        "announcement_delivery",
+			Description: "Sending scheduled staff announcements to their audience",
+			Enabled:     true,
+			Interval:    1 * time.Minute,
+			Run:         broadcast.Deliver,
+		},
+		{
+			Name:        "saved_search_alerts",
+			Description: "Alerting users to newly listed bots and servers matching their saved searches",
+			Enabled:     true,
+			Interval:    1 * time.Minute,
+			Run:         savedsearches.Evaluate,
+		},
+		{
+			Name:        "server_member_sync",
+			Description: "Refreshing listed servers' member counts from their invites",
+			Enabled:     true,
+			Interval:    1 * time.Hour,
+			Run:         ServerMemberSync,
+		},
+		{
+			Name:        "stats_downsample",
+			Description: "Rolling bot and server stats history up into hourly and daily averages, and expiring old samples",
+			Enabled:     true,
+			Interval:    15 * time.Minute,
+			Run:         statshistory.Downsample,
+		},
+		{
+			Name:        "view_analytics_rollup",
+			Description: "Applying pending view and invite click counts, and rolling clicks up into daily view analytics",
+			Enabled:     true,
+			Interval:    5 * time.Minute,
+			Run:         analytics.Rollup,
+		},
+		{
+			Name:        "promotion_rollup",
+			Description: "Adding counted promotion impressions and click-throughs to the daily promotion stats",
+			Enabled:     true,
+			Interval:    5 * time.Minute,
+			Run:         promotions.Rollup,
+		},
+		{
+			Name:        "team_invite_expiry",
+			Description: "Deleting team invites that have expired or been used up",
+			Enabled:     true,
+			Interval:    1 * time.Hour,
+			Run:         teams.ExpireInvites,
+		},
+		{
+			Name:        "audit_log_prune",
+			Description: "Deleting audit log entries older than their target type's retention",
+			Enabled:     true,
+			Interval:    6 * time.Hour,
+			Run:         audit.Prune,
+		},
+		{
+			Name:        "bot_transfer_expiry",
+			Description: "Deleting bot transfers that have expired",
+			Enabled:     true,
+			Interval:    1 * time.Hour,
+			Run:         transfers.ExpireBotTransfers,
+		},
+		{
+			Name:        "api_session_expiry",
+			Description: "Deleting API sessions that have expired",
+			Enabled:     true,
+			Interval:    1 * time.Hour,
+			Run:         api.DeleteExpiredSessions,
+		},
 	}
 }
 
@@ -0,0 +1,77 @@
+package bgtasks
+
+import (
+	"context"
+	"fmt"
+
+	"popplio/routes/servers/assets"
+	"popplio/state"
+	"popplio/statshistory"
+
+	"go.uber.org/zap"
+)
+
+// ServerMemberSync refreshes every listed server's member counts from its
+// invite, and samples them into the stats history.
+//
+// Member counts were otherwise only ever set when the server was added. The
+// invite's approximate counts are what Add Server uses too, so the two agree.
+// Requests go one at a time, leaving Discord's rate limits to the REST
+// client. A server whose invite no longer resolves keeps its last counts and
+// gets no sample, rather than a false drop to zero.
+func ServerMemberSync(ctx context.Context) error {
+	rows, err := state.Pool.Query(ctx, "SELECT server_id, invite FROM servers WHERE type = 'approved' OR type = 'certified'")
+
+	if err != nil {
+		return fmt.Errorf("querying listed servers: %w", err)
+	}
+
+	type listedServer struct {
+		id     string
+		invite string
+	}
+
+	var servers []listedServer
+
+	for rows.Next() {
+		var s listedServer
+
+		if err := rows.Scan(&s.id, &s.invite); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning server: %w", err)
+		}
+
+		servers = append(servers, s)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating listed servers: %w", err)
+	}
+
+	for _, s := range servers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		invite, err := assets.ResolveInvite(ctx, s.invite)
+
+		if err != nil || invite.Guild.ID.String() != s.id {
+			state.Logger.Debug("server_member_sync: invite does not resolve to the server, skipping", zap.Error(err), zap.String("serverID", s.id))
+			continue
+		}
+
+		_, err = state.Pool.Exec(ctx, "UPDATE servers SET total_members = $2, online_members = $3 WHERE server_id = $1", s.id, invite.ApproximateMemberCount, invite.ApproximatePresenceCount)
+
+		if err != nil {
+			return fmt.Errorf("serverID=%s: updating member counts: %w", s.id, err)
+		}
+
+		if err := statshistory.RecordServer(ctx, state.Pool, s.id); err != nil {
+			return fmt.Errorf("serverID=%s: recording stats history: %w", s.id, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+// Package botcommands stores the command catalogues bots publish.
+//
+// A bot publishes its whole catalogue at once with its API token. Publishing
+// the same commands again is a no-op, so bots can publish on every start.
+// Otherwise the catalogue becomes a new version, of which the latest
+// maxVersions are kept, and its command text is copied to bots.search_commands
+// for list search.
+package botcommands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"popplio/db"
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// maxVersions is how many versions of a bot's catalogue are kept
+	maxVersions = 20
+
+	// maxSearchText bounds how much command text is indexed, in line with
+	// the long description
+	maxSearchText = 100000
+)
+
+// Validate returns a user facing error if cmds can't be published. Field level
+// limits are checked by the validator beforehand.
+func Validate(cmds []types.BotCommand) error {
+	seen := map[string]bool{}
+
+	for _, c := range cmds {
+		t := c.Type
+
+		if t == 0 {
+			t = types.BotCommandTypeChatInput
+		}
+
+		// Discord allows a slash and a context menu command to share a name
+		key := fmt.Sprint(t, ":", strings.ToLower(c.Name))
+
+		if seen[key] {
+			return fmt.Errorf("Command %q is published more than once", c.Name)
+		}
+
+		seen[key] = true
+	}
+
+	return nil
+}
+
+// searchText returns what list search indexes of cmds
+func searchText(cmds []types.BotCommand) string {
+	var b strings.Builder
+
+	for _, c := range cmds {
+		b.WriteString(c.Name + " " + c.Description + " " + c.Category + "\n")
+	}
+
+	s := b.String()
+
+	if len(s) > maxSearchText {
+		s = s[:maxSearchText]
+	}
+
+	return strings.ToValidUTF8(s, "")
+}
+
+// Publish stores cmds as the bot's catalogue, returning the resulting version
+// and whether it is new.
+func Publish(ctx context.Context, botID string, cmds []types.BotCommand) (*types.BotCommandCatalogue, bool, error) {
+	for i := range cmds {
+		if cmds[i].Type == 0 {
+			cmds[i].Type = types.BotCommandTypeChatInput
+		}
+	}
+
+	b, err := json.Marshal(cmds)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	sum := sha256.Sum256(b)
+	hash := hex.EncodeToString(sum[:])
+
+	tx, err := state.Pool.Begin(ctx)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	// Serializes publishes of the same bot
+	_, err = tx.Exec(ctx, "SELECT 1 FROM bots WHERE bot_id = $1 FOR UPDATE", botID)
+
+	if err != nil {
+		return nil, false, fmt.Errorf("locking bot: %w", err)
+	}
+
+	latest, err := get(ctx, tx, botID, 0)
+
+	if err != nil {
+		return nil, false, fmt.Errorf("getting latest version: %w", err)
+	}
+
+	if latest != nil && latest.hash == hash {
+		return &latest.BotCommandCatalogue, false, nil
+	}
+
+	version := 1
+
+	if latest != nil {
+		version = latest.Version + 1
+	}
+
+	var c types.BotCommandCatalogue
+	err = tx.QueryRow(
+		ctx,
+		"INSERT INTO bot_command_versions (bot_id, version, commands, hash) VALUES ($1, $2, $3, $4) RETURNING version, commands, created_at",
+		botID,
+		version,
+		b,
+		hash,
+	).Scan(&c.Version, &c.Commands, &c.CreatedAt)
+
+	if err != nil {
+		return nil, false, fmt.Errorf("inserting version: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM bot_command_versions WHERE bot_id = $1 AND version <= $2", botID, version-maxVersions)
+
+	if err != nil {
+		return nil, false, fmt.Errorf("pruning versions: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE bots SET search_commands = $1 WHERE bot_id = $2", searchText(cmds), botID)
+
+	if err != nil {
+		return nil, false, fmt.Errorf("updating search text: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, err
+	}
+
+	return &c, true, nil
+}
+
+type version struct {
+	types.BotCommandCatalogue
+	hash string
+}
+
+func get(ctx context.Context, q db.Conn, botID string, v int) (*version, error) {
+	var c version
+
+	err := q.QueryRow(
+		ctx,
+		"SELECT version, commands, created_at, hash FROM bot_command_versions WHERE bot_id = $1 AND ($2 = 0 OR version = $2) ORDER BY version DESC LIMIT 1",
+		botID,
+		v,
+	).Scan(&c.Version, &c.Commands, &c.CreatedAt, &c.hash)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Get returns version v of a bot's catalogue, or the latest if v is 0. It is
+// nil if there is no such version.
+func Get(ctx context.Context, botID string, v int) (*types.BotCommandCatalogue, error) {
+	c, err := get(ctx, state.Pool, botID, v)
+
+	if err != nil || c == nil {
+		return nil, err
+	}
+
+	return &c.BotCommandCatalogue, nil
+}
@@ -135,6 +135,7 @@ type Config struct {
 	Servers       Servers       `yaml:"servers" validate:"required"`
 	Meta          Meta          `yaml:"meta" validate:"required"`
 	Arcadia       Arcadia       `yaml:"arcadia" validate:"required"`
+	AuditLog      AuditLog      `yaml:"audit_log"`
 }
 
 type DiscordAuth struct {
@@ -218,6 +219,7 @@ type Meta struct {
 	StripeSecretKey     Differs[string] `yaml:"stripe_secret_key" default:"" comment:"Stripe Public Key" validate:"required"`
 	UptimeRobotROAPIKey string          `yaml:"uptime_robot_ro_api_key" default:"" comment:"Uptime Robot Read-Only API Key" validate:"required"`
 	PopplioProxy        string          `yaml:"popplio_proxy" default:"https://gateway.nodebyte.host/proxy/discord" comment:"Popplio Proxy URL" validate:"required"`
+	SessionHashKey      string          `yaml:"session_hash_key" comment:"Secret key API session tokens are hashed with. Changing it invalidates every session" validate:"required"`
 }
 
 // Arcadia holds the configuration keys the staff panel API and staff bot need
@@ -259,3 +261,12 @@ type Panel struct {
 	PanelScope         string   `yaml:"panel_scope" comment:"Static handshake value the frontend sends" validate:"required"`
 	PanelResponseScope string   `yaml:"panel_response_scope" comment:"Static handshake value the frontend expects back" validate:"required"`
 }
+
+// AuditLog sets how long audit log entries are kept for each target type. Unset
+// or zero keeps them for audit.DefaultRetentionDays.
+type AuditLog struct {
+	BotRetentionDays    int `yaml:"bot_retention_days" default:"90" comment:"Days to keep audit log entries for bots"`
+	ServerRetentionDays int `yaml:"server_retention_days" default:"90" comment:"Days to keep audit log entries for servers"`
+	TeamRetentionDays   int `yaml:"team_retention_days" default:"90" comment:"Days to keep audit log entries for teams"`
+	PackRetentionDays   int `yaml:"pack_retention_days" default:"90" comment:"Days to keep audit log entries for packs"`
+}
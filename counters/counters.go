@@ -0,0 +1,103 @@
+// Package counters flushes counts accumulated in a Redis hash to the
+// database, for the rollup tasks of analytics and promotions.
+//
+// Counts are accumulated with HINCRBY on a pending key. A flush renames it to
+// a flushing key, so counts arriving meanwhile go to a fresh pending key, then
+// applies each field and deletes it once applied. A flush that fails part way
+// leaves the flushing key behind, which the next flush applies before taking
+// new counts.
+//
+// Background tasks run on every process, so a flush holds a lock for its
+// whole run; without it two flushes could read the same leftover fields and
+// apply them twice.
+package counters
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"popplio/state"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// lockExpiry is how long a flush may hold its lock. It only matters if the
+// process dies mid-flush, and is far longer than any flush takes.
+const lockExpiry = 10 * time.Minute
+
+// unlockScript releases a lock only if it is still held by the same flush
+var unlockScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Flush applies the counts in pendingKey, or those left in flushingKey by a
+// failed flush, with apply. Fields whose value isn't an integer are dropped.
+// If another flush of the same keys is running, Flush does nothing.
+func Flush(ctx context.Context, pendingKey, flushingKey string, apply func(field string, n int64) error) error {
+	lockKey := flushingKey + ":lock"
+	token := uuid.NewString()
+
+	locked, err := state.Redis.SetNX(ctx, lockKey, token, lockExpiry).Result()
+
+	if err != nil {
+		return fmt.Errorf("taking lock: %w", err)
+	}
+
+	if !locked {
+		return nil
+	}
+
+	defer unlockScript.Run(context.WithoutCancel(ctx), state.Redis, []string{lockKey}, token)
+
+	exists, err := state.Redis.Exists(ctx, flushingKey).Result()
+
+	if err != nil {
+		return err
+	}
+
+	if exists == 0 {
+		pending, err := state.Redis.Exists(ctx, pendingKey).Result()
+
+		if err != nil {
+			return err
+		}
+
+		// Nothing was counted since the last flush
+		if pending == 0 {
+			return nil
+		}
+
+		if err := state.Redis.Rename(ctx, pendingKey, flushingKey).Err(); err != nil {
+			return err
+		}
+	}
+
+	counts, err := state.Redis.HGetAll(ctx, flushingKey).Result()
+
+	if err != nil {
+		return err
+	}
+
+	for field, v := range counts {
+		n, err := strconv.ParseInt(v, 10, 64)
+
+		if err != nil {
+			continue
+		}
+
+		if err := apply(field, n); err != nil {
+			return fmt.Errorf("applying %s: %w", field, err)
+		}
+
+		// So a failure on a later field doesn't apply this one twice
+		if err := state.Redis.HDel(ctx, flushingKey, field).Err(); err != nil {
+			return err
+		}
+	}
+
+	return state.Redis.Del(ctx, flushingKey).Err()
+}
@@ -0,0 +1,49 @@
+-- Staff broadcast announcements (see notifications/broadcast).
+--
+-- The announcements table predates any code using it, so it is created here if
+-- missing and otherwise extended in place. An announcement is delivered by
+-- materializing one alert per recipient in the segment, a batch at a time, once
+-- scheduled_for has passed. delivery_cursor is the last user_id delivered to, so
+-- a restart mid-delivery carries on where it stopped instead of starting over.
+--
+-- status is pending -> delivering -> delivered, or cancelled by staff at any
+-- point before delivered. target is the team ID for the team_members segment.
+CREATE TABLE IF NOT EXISTS announcements (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    author TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL,
+    modified_date TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    status TEXT NOT NULL DEFAULT 'pending',
+    target TEXT
+);
+
+ALTER TABLE announcements ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'pending';
+
+-- Existing rows were never delivered and should not suddenly be. They would
+-- otherwise be pending, due now and sent to everyone once the columns below
+-- are added, so cancel them all first. scheduled_for only exists once this
+-- has run, so re-running it leaves announcements made since alone.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'announcements' AND column_name = 'scheduled_for'
+    ) THEN
+        UPDATE announcements SET status = 'cancelled';
+    END IF;
+END $$;
+
+ALTER TABLE announcements ADD COLUMN IF NOT EXISTS segment TEXT NOT NULL DEFAULT 'all' CHECK (segment IN ('all', 'bot_owners', 'certified_bot_owners', 'premium_owners', 'team_members'));
+ALTER TABLE announcements ADD COLUMN IF NOT EXISTS alert_type TEXT NOT NULL DEFAULT 'info' CHECK (alert_type IN ('success', 'error', 'info', 'warning'));
+ALTER TABLE announcements ADD COLUMN IF NOT EXISTS url TEXT;
+ALTER TABLE announcements ADD COLUMN IF NOT EXISTS scheduled_for TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE announcements ADD COLUMN IF NOT EXISTS delivery_cursor TEXT NOT NULL DEFAULT '';
+ALTER TABLE announcements ADD COLUMN IF NOT EXISTS delivered_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE announcements ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE announcements SET status = 'cancelled' WHERE status NOT IN ('pending', 'delivering', 'delivered', 'cancelled');
+ALTER TABLE announcements DROP CONSTRAINT IF EXISTS announcements_status_check;
+ALTER TABLE announcements ADD CONSTRAINT announcements_status_check CHECK (status IN ('pending', 'delivering', 'delivered', 'cancelled'));
+
+CREATE INDEX IF NOT EXISTS announcements_due_idx ON announcements (scheduled_for) WHERE status IN ('pending', 'delivering');
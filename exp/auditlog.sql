@@ -0,0 +1,34 @@
+-- Audit log of changes to bots, servers, teams and packs (see audit).
+--
+-- Every successful mutating request against an entity records who made it,
+-- the route it went through and the fields it changed, as the before and
+-- after values of only those fields. before is NULL when the request created
+-- the entity and after is NULL when it deleted it. Entries are kept for a
+-- number of days set per target type under audit_log in the config, and
+-- pruned by the audit_log_prune task.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    target_type TEXT NOT NULL CHECK (target_type IN ('bot', 'server', 'team', 'pack')),
+    target_id TEXT NOT NULL,
+    actor_type TEXT NOT NULL,
+    actor_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_target_idx ON audit_log (target_type, target_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at);
+
+-- Webhook URLs carry their token, so entries recorded before snapshots kept
+-- only a fingerprint of them have the URLs dropped
+UPDATE audit_log SET before = jsonb_set(before, '{webhooks}', (
+    SELECT jsonb_object_agg(k, CASE WHEN jsonb_typeof(v) = 'object' THEN v - 'url' ELSE v END)
+    FROM jsonb_each(before->'webhooks') AS w(k, v)
+)) WHERE jsonb_typeof(before->'webhooks') = 'object' AND before->'webhooks' <> '{}'::jsonb;
+
+UPDATE audit_log SET after = jsonb_set(after, '{webhooks}', (
+    SELECT jsonb_object_agg(k, CASE WHEN jsonb_typeof(v) = 'object' THEN v - 'url' ELSE v END)
+    FROM jsonb_each(after->'webhooks') AS w(k, v)
+)) WHERE jsonb_typeof(after->'webhooks') = 'object' AND after->'webhooks' <> '{}'::jsonb;
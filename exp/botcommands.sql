@@ -0,0 +1,36 @@
+-- Bot command catalogues (see routes/bots/endpoints/put_bot_commands).
+--
+-- Each publish that changes a bot's commands adds a version, and the latest
+-- 20 versions are kept. The latest version's command names, descriptions and
+-- categories are copied to bots.search_commands, which search_vector indexes
+-- at weight C so bots can be found by what their commands do.
+CREATE TABLE IF NOT EXISTS bot_command_versions (
+    bot_id TEXT NOT NULL REFERENCES bots (bot_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    version INTEGER NOT NULL,
+    commands JSONB NOT NULL,
+    hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (bot_id, version)
+);
+
+ALTER TABLE bots ADD COLUMN IF NOT EXISTS search_commands TEXT NOT NULL DEFAULT '';
+
+CREATE OR REPLACE FUNCTION bots_search_refresh() RETURNS trigger AS $$
+BEGIN
+    NEW.search_name := COALESCE((SELECT username FROM internal_user_cache__discord WHERE id = NEW.bot_id), NEW.search_name, '');
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', NEW.search_name), 'A') ||
+        setweight(to_tsvector('english', COALESCE(array_to_string(NEW.tags, ' '), '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(NEW.short, '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE(NEW.library, '')), 'C') ||
+        setweight(to_tsvector('english', COALESCE(NEW.search_commands, '')), 'C') ||
+        -- tsvectors are capped at 1MB, and past this point long descriptions
+        -- are rarely anything but embedded markup
+        setweight(to_tsvector('english', left(COALESCE(NEW.long, ''), 100000)), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bots_search_refresh ON bots;
+CREATE TRIGGER bots_search_refresh BEFORE INSERT OR UPDATE OF search_name, short, long, tags, library, search_commands ON bots
+    FOR EACH ROW EXECUTE FUNCTION bots_search_refresh();
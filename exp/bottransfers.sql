@@ -0,0 +1,27 @@
+-- Bot ownership transfers (see transfers).
+--
+-- Moving a bot to another user used to need staff to run the
+-- BotTransferOwnershipUser RPC, and patch_bot_team only moves bots into teams
+-- the user can already add bots to. A bot's owner can now propose a new owner,
+-- a user or a team, who has until expires_at to accept. A bot has at most one
+-- pending transfer, which proposing again replaces. from_user/from_team is who
+-- owned the bot when the transfer was proposed, so a transfer is void if the
+-- bot has changed hands since. The bot_transfer_expiry task deletes expired
+-- transfers.
+CREATE TABLE IF NOT EXISTS bot_transfers (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    bot_id TEXT NOT NULL UNIQUE REFERENCES bots (bot_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    from_user TEXT REFERENCES users (user_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    from_team UUID REFERENCES teams (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    to_user TEXT REFERENCES users (user_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    to_team UUID REFERENCES teams (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    proposed_by TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    CHECK ((from_user IS NULL) <> (from_team IS NULL)),
+    CHECK ((to_user IS NULL) <> (to_team IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS bot_transfers_to_user_idx ON bot_transfers (to_user);
+CREATE INDEX IF NOT EXISTS bot_transfers_to_team_idx ON bot_transfers (to_team);
+CREATE INDEX IF NOT EXISTS bot_transfers_expires_at_idx ON bot_transfers (expires_at);
@@ -0,0 +1,22 @@
+-- Discord DM delivery of alerts (see notifications/discorddm.go).
+--
+-- A row in user_notification_dms means the user opted in. Alerts are queued
+-- rather than sent inline so that several alerts arriving close together go
+-- out as one DM, and so a slow or rate-limited Discord never holds up the
+-- request that raised the alert.
+CREATE TABLE IF NOT EXISTS user_notification_dms (
+    user_id TEXT PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    disabled_reason TEXT, -- Set when Popplio turned the channel off itself, e.g. the user closed their DMs
+    last_sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS user_notification_dm_queue (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    alert JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS user_notification_dm_queue_user_id_idx ON user_notification_dm_queue (user_id, created_at);
@@ -0,0 +1,26 @@
+-- Collaborative packs (see routes/packs/assets).
+--
+-- A pack used to be edited only by its owner. Its owner can now add
+-- collaborators, who can edit, reorder and annotate its items but not delete
+-- it or manage its collaborators. Items are still the packs.bots and
+-- packs.servers arrays, whose order is the order they are shown in. Notes on
+-- items are kept here, and are dropped when their item leaves the pack.
+CREATE TABLE IF NOT EXISTS pack_collaborators (
+    pack_url TEXT NOT NULL REFERENCES packs (url) ON DELETE CASCADE ON UPDATE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    added_by TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (pack_url, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS pack_collaborators_user_id_idx ON pack_collaborators (user_id);
+
+CREATE TABLE IF NOT EXISTS pack_item_notes (
+    pack_url TEXT NOT NULL REFERENCES packs (url) ON DELETE CASCADE ON UPDATE CASCADE,
+    target_type TEXT NOT NULL CHECK (target_type IN ('bot', 'server')),
+    target_id TEXT NOT NULL,
+    note TEXT NOT NULL,
+    updated_by TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (pack_url, target_type, target_id)
+);
@@ -0,0 +1,18 @@
+-- Promoted placements of premium bots and servers (see promotions).
+--
+-- Premium entities used to fill the index's premium row by votes, so the same
+-- few were always shown. The promotion engine now rotates them through the
+-- index and random slots, and counts each placement's impressions and
+-- click-throughs in Redis. The promotion_rollup task adds those counts to
+-- promotion_stats_daily every few minutes, which owners' promotion reports
+-- read. Rows are kept forever.
+CREATE TABLE IF NOT EXISTS promotion_stats_daily (
+    target_type TEXT NOT NULL CHECK (target_type IN ('bot', 'server')),
+    target_id TEXT NOT NULL,
+    day DATE NOT NULL,
+    surface TEXT NOT NULL,
+    slot INTEGER NOT NULL,
+    impressions BIGINT NOT NULL DEFAULT 0,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (target_type, target_id, day, surface, slot)
+);
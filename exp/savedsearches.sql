@@ -0,0 +1,35 @@
+-- Saved searches (see notifications/savedsearches).
+--
+-- A user saves a POST /list/search query under a name, and is alerted when a
+-- bot or server matching it is newly listed. Approving a bot and adding a
+-- server only queue the entity in saved_search_queue; the saved_search_alerts
+-- background task matches it against every saved search later, so neither
+-- path waits on it.
+--
+-- saved_search_matches records which entities each search has alerted for,
+-- so an entity that is denied and approved again, or queued twice, is only
+-- alerted once.
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    query JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_matched_at TIMESTAMPTZ,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS saved_search_queue (
+    target_id TEXT NOT NULL,
+    target_type TEXT NOT NULL CHECK (target_type IN ('bot', 'server')),
+    queued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (target_id, target_type)
+);
+
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+    search_id UUID NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+    target_id TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (search_id, target_id, target_type)
+);
@@ -0,0 +1,70 @@
+-- Full-text and fuzzy search index for POST /list/search (see
+-- routes/list/endpoints/search_list).
+--
+-- search_vector weighs the name highest, then tags and short description,
+-- then library, then the long description. It is kept up to date by BEFORE
+-- triggers, so no write path in Popplio has to remember it. A bot's name lives
+-- in dovewing's user cache rather than on bots, so it is copied to
+-- bots.search_name (which also carries the trigram index for typo tolerance),
+-- and a trigger on the cache pushes renames through.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE bots ADD COLUMN IF NOT EXISTS search_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE bots ADD COLUMN IF NOT EXISTS search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector;
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector;
+
+CREATE OR REPLACE FUNCTION bots_search_refresh() RETURNS trigger AS $$
+BEGIN
+    NEW.search_name := COALESCE((SELECT username FROM internal_user_cache__discord WHERE id = NEW.bot_id), NEW.search_name, '');
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', NEW.search_name), 'A') ||
+        setweight(to_tsvector('english', COALESCE(array_to_string(NEW.tags, ' '), '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(NEW.short, '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE(NEW.library, '')), 'C') ||
+        -- tsvectors are capped at 1MB, and past this point long descriptions
+        -- are rarely anything but embedded markup
+        setweight(to_tsvector('english', left(COALESCE(NEW.long, ''), 100000)), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bots_search_refresh ON bots;
+CREATE TRIGGER bots_search_refresh BEFORE INSERT OR UPDATE OF search_name, short, long, tags, library ON bots
+    FOR EACH ROW EXECUTE FUNCTION bots_search_refresh();
+
+CREATE OR REPLACE FUNCTION servers_search_refresh() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(array_to_string(NEW.tags, ' '), '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(NEW.short, '')), 'B') ||
+        setweight(to_tsvector('english', left(COALESCE(NEW.long, ''), 100000)), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS servers_search_refresh ON servers;
+CREATE TRIGGER servers_search_refresh BEFORE INSERT OR UPDATE OF name, short, long, tags ON servers
+    FOR EACH ROW EXECUTE FUNCTION servers_search_refresh();
+
+-- Setting search_name fires bots_search_refresh, which reads the new name
+-- back from the cache
+CREATE OR REPLACE FUNCTION bots_search_rename() RETURNS trigger AS $$
+BEGIN
+    UPDATE bots SET search_name = NEW.username WHERE bot_id = NEW.id AND search_name IS DISTINCT FROM NEW.username;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bots_search_rename ON internal_user_cache__discord;
+CREATE TRIGGER bots_search_rename AFTER INSERT OR UPDATE OF username ON internal_user_cache__discord
+    FOR EACH ROW EXECUTE FUNCTION bots_search_rename();
+
+-- Backfill through the triggers
+UPDATE bots SET search_name = search_name;
+UPDATE servers SET name = name;
+
+CREATE INDEX IF NOT EXISTS bots_search_vector_idx ON bots USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS bots_search_name_trgm_idx ON bots USING GIN (search_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS servers_search_vector_idx ON servers USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS servers_name_trgm_idx ON servers USING GIN (name gin_trgm_ops);
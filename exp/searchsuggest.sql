@@ -0,0 +1,6 @@
+-- GET /list/search/suggest matches team names by prefix and trigram
+-- similarity. Bots and servers already have trigram indexes from
+-- exp/searchindex.sql.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS teams_name_trgm_idx ON teams USING GIN (name gin_trgm_ops);
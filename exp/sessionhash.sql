@@ -0,0 +1,14 @@
+-- Hashed API session tokens (see api/tokens.go).
+--
+-- Session tokens used to be stored in plaintext, so anyone able to read
+-- api_sessions could act as any user, bot, server or team. Only a keyed hash
+-- of new tokens is stored now. Older sessions keep their plaintext token
+-- until they are next used, when api.Authorize moves them over to its hash
+-- and clears the token.
+ALTER TABLE api_sessions ADD COLUMN IF NOT EXISTS token_hash TEXT;
+ALTER TABLE api_sessions ALTER COLUMN token DROP NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS api_sessions_token_hash_idx ON api_sessions (token_hash);
+
+ALTER TABLE api_sessions DROP CONSTRAINT IF EXISTS api_sessions_token_or_hash;
+ALTER TABLE api_sessions ADD CONSTRAINT api_sessions_token_or_hash CHECK (token IS NOT NULL OR token_hash IS NOT NULL);
@@ -0,0 +1,24 @@
+-- Historical stats for bots and servers (see statshistory).
+--
+-- Bots get a sample every time they post stats, servers every time
+-- server_member_sync refreshes their member counts. Samples are stored at
+-- minute resolution ('raw', the last post in a minute wins) and rolled up by
+-- the stats_downsample task into hourly and daily averages. Raw samples are
+-- kept for 7 days and hourly ones for 90, daily ones forever.
+--
+-- Columns a target type does not have (e.g. total_members for bots) are NULL.
+CREATE TABLE IF NOT EXISTS entity_stats (
+    target_type TEXT NOT NULL CHECK (target_type IN ('bot', 'server')),
+    target_id TEXT NOT NULL,
+    resolution TEXT NOT NULL CHECK (resolution IN ('raw', 'hourly', 'daily')),
+    bucket TIMESTAMPTZ NOT NULL,
+    servers BIGINT,
+    shards BIGINT,
+    users BIGINT,
+    total_members BIGINT,
+    online_members BIGINT,
+    PRIMARY KEY (target_type, target_id, resolution, bucket)
+);
+
+-- For rolling up and expiring a resolution across every entity at once
+CREATE INDEX IF NOT EXISTS entity_stats_resolution_bucket_idx ON entity_stats (resolution, bucket);
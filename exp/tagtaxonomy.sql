@@ -0,0 +1,75 @@
+-- Tag registry (see the taxonomy package).
+--
+-- Tags on bots, servers, teams and packs were free-form, so the same category
+-- was split across spellings. Entity tag arrays now only hold the IDs of tags
+-- in the registry, which is curated by staff from the panel. An ID is the
+-- slug of its name (lowercase, with runs of anything but letters and digits
+-- replaced by a dash), and other slugs can be aliases of it.
+CREATE TABLE IF NOT EXISTS tags (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    -- An Iconify icon name, as used elsewhere on the site
+    icon TEXT NOT NULL DEFAULT '',
+    target_types TEXT[] NOT NULL DEFAULT '{bot,server,team,pack}',
+    -- Retired tags are kept by entities already using them but can't be added
+    retired BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS tag_aliases (
+    alias TEXT PRIMARY KEY,
+    tag_id TEXT NOT NULL REFERENCES tags (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS tag_aliases_tag_id_idx ON tag_aliases (tag_id);
+
+-- Must match taxonomy.Slug
+CREATE OR REPLACE FUNCTION tag_slug(tag TEXT) RETURNS TEXT AS $$
+    SELECT trim(BOTH '-' FROM regexp_replace(lower(tag), '[^[:alnum:]]+', '-', 'g'))
+$$ LANGUAGE sql IMMUTABLE;
+
+-- canonical_tags maps each tag to the registry tag it is an alias of, or else
+-- its slug, dropping duplicates but keeping the order tags were given in
+CREATE OR REPLACE FUNCTION canonical_tags(raw TEXT[]) RETURNS TEXT[] AS $$
+    SELECT COALESCE(array_agg(tag ORDER BY ord), '{}') FROM (
+        SELECT COALESCE(a.tag_id, tag_slug(t)) AS tag, min(ord) AS ord
+        FROM unnest(raw) WITH ORDINALITY AS u (t, ord)
+        LEFT JOIN tag_aliases a ON a.alias = tag_slug(t)
+        WHERE tag_slug(t) <> ''
+        GROUP BY 1
+    ) c
+$$ LANGUAGE sql STABLE;
+
+-- Every slug in use becomes a tag, named after its most common spelling
+WITH used AS (
+    SELECT unnest(tags) AS raw FROM bots
+    UNION ALL
+    SELECT unnest(tags) FROM servers
+    UNION ALL
+    SELECT unnest(tags) FROM teams WHERE tags IS NOT NULL
+    UNION ALL
+    SELECT unnest(tags) FROM packs
+)
+INSERT INTO tags (id, name)
+SELECT tag_slug(raw), mode() WITHIN GROUP (ORDER BY trim(raw))
+FROM used
+WHERE tag_slug(raw) <> ''
+GROUP BY tag_slug(raw)
+ON CONFLICT (id) DO NOTHING;
+
+UPDATE bots SET tags = canonical_tags(tags) WHERE tags IS DISTINCT FROM canonical_tags(tags);
+UPDATE servers SET tags = canonical_tags(tags) WHERE tags IS DISTINCT FROM canonical_tags(tags);
+UPDATE teams SET tags = canonical_tags(tags) WHERE tags IS NOT NULL AND tags IS DISTINCT FROM canonical_tags(tags);
+UPDATE packs SET tags = canonical_tags(tags) WHERE tags IS DISTINCT FROM canonical_tags(tags);
+
+-- Saved searches filter by tag IDs too (see retagSavedSearches in
+-- taxonomy/ops.go). exp/savedsearches.sql may not have been run yet.
+DO $$
+BEGIN
+    IF to_regclass('saved_searches') IS NOT NULL THEN
+        UPDATE saved_searches SET query = jsonb_set(query, '{tags,tags}', to_jsonb(canonical_tags(ARRAY(SELECT jsonb_array_elements_text(query->'tags'->'tags')))))
+        WHERE jsonb_typeof(query->'tags'->'tags') = 'array';
+    END IF;
+END $$;
@@ -0,0 +1,26 @@
+-- Team invitations (see teams/invites.go).
+--
+-- Adding a team member used to insert them straight into team_members. Members
+-- are now invited, and only join once they accept. An invite is either for a
+-- single user, who may accept or decline it, or a shareable link (user_id is
+-- NULL) that anyone may accept through its code, up to max_uses times. Either
+-- carries the flags the member joins with, and expires at expires_at; the
+-- team_invite_expiry task deletes expired invites.
+CREATE TABLE IF NOT EXISTS team_invites (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    team_id UUID NOT NULL REFERENCES teams (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    user_id TEXT REFERENCES users (user_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    code TEXT UNIQUE,
+    flags TEXT[] NOT NULL DEFAULT '{}',
+    invited_by TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    max_uses INTEGER,
+    uses INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    CHECK ((user_id IS NULL) <> (code IS NULL))
+);
+
+-- A user has at most one invite to a team, which re-inviting replaces
+CREATE UNIQUE INDEX IF NOT EXISTS team_invites_team_user_idx ON team_invites (team_id, user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS team_invites_user_id_idx ON team_invites (user_id);
+CREATE INDEX IF NOT EXISTS team_invites_expires_at_idx ON team_invites (expires_at);
@@ -0,0 +1,23 @@
+-- Team roles (see teams/roles.go).
+--
+-- A team member's permissions used to be only their own flags. Teams can now
+-- define named roles carrying a set of permissions, and assign them to
+-- members, whose permissions are the union of their roles' and their flags,
+-- which are kept as per-member extras. Positions rank roles against each
+-- other, 0 being the most senior, so that members can only manage roles below
+-- their own.
+CREATE TABLE IF NOT EXISTS team_roles (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    team_id UUID NOT NULL REFERENCES teams (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    name TEXT NOT NULL,
+    color TEXT,
+    position INTEGER NOT NULL CHECK (position >= 0),
+    perms TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (team_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS team_roles_team_id_idx ON team_roles (team_id, position);
+
+ALTER TABLE team_members ADD COLUMN IF NOT EXISTS roles UUID[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,33 @@
+-- Bot uptime incidents (see uptime).
+--
+-- bot_presence holds each listed bot's last observed presence in the main
+-- server. It is updated from gateway presence updates as they happen, and
+-- reconciled by the bot_uptime_check poll in case an update was missed.
+-- tracked_since is when the bot was first observed, so uptime percentages
+-- never count time from before we were watching.
+--
+-- Every transition to offline opens an incident, and the transition back
+-- online closes it. A bot has at most one open incident.
+--
+-- offline_alert_minutes on bots is the owner's opt-in: when an incident stays
+-- open that long the owners are alerted once, and again when it closes.
+CREATE TABLE IF NOT EXISTS bot_presence (
+    bot_id TEXT PRIMARY KEY NOT NULL REFERENCES bots(bot_id) ON DELETE CASCADE,
+    online BOOLEAN NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    tracked_since TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS bot_incidents (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    bot_id TEXT NOT NULL REFERENCES bots(bot_id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ended_at TIMESTAMPTZ,
+    owners_alerted BOOLEAN NOT NULL DEFAULT false,
+    recovery_alerted BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX IF NOT EXISTS bot_incidents_bot_started_idx ON bot_incidents (bot_id, started_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS bot_incidents_one_open_idx ON bot_incidents (bot_id) WHERE ended_at IS NULL;
+
+ALTER TABLE bots ADD COLUMN IF NOT EXISTS offline_alert_minutes INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,52 @@
+-- View analytics for bots and servers (see analytics).
+--
+-- Replaces the unique_clicks arrays, which held every visitor's hashed IP on
+-- the entity row and were rewritten on every page view. Views and invite
+-- clicks are now appended to entity_clicks, and unique visitors are counted
+-- with Redis HyperLogLogs. The view_analytics_rollup task folds both into
+-- entity_views_daily, which is what the analytics endpoints read and is kept
+-- forever; entity_clicks itself is only kept for 90 days.
+--
+-- The arrays' sizes are kept as unique_clicks_legacy, which the all-time
+-- unique count adds to. A visitor from before this migration who comes back
+-- is counted again, which is the best that can be done without their hashes.
+CREATE TABLE IF NOT EXISTS entity_clicks (
+    id BIGSERIAL PRIMARY KEY,
+    target_type TEXT NOT NULL CHECK (target_type IN ('bot', 'server')),
+    target_id TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('view', 'invite')),
+    referrer TEXT,
+    via_vanity BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS entity_clicks_created_at_idx ON entity_clicks (created_at);
+
+CREATE TABLE IF NOT EXISTS entity_views_daily (
+    target_type TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    day DATE NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    unique_views BIGINT NOT NULL DEFAULT 0,
+    invite_clicks BIGINT NOT NULL DEFAULT 0,
+    vanity_views BIGINT NOT NULL DEFAULT 0,
+    referrers JSONB NOT NULL DEFAULT '{}',
+    PRIMARY KEY (target_type, target_id, day)
+);
+
+ALTER TABLE bots ADD COLUMN IF NOT EXISTS unique_clicks_legacy BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS unique_clicks_legacy BIGINT NOT NULL DEFAULT 0;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'bots' AND column_name = 'unique_clicks') THEN
+        UPDATE bots SET unique_clicks_legacy = COALESCE(cardinality(unique_clicks), 0);
+        ALTER TABLE bots DROP COLUMN unique_clicks;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'servers' AND column_name = 'unique_clicks') THEN
+        UPDATE servers SET unique_clicks_legacy = COALESCE(cardinality(unique_clicks), 0);
+        ALTER TABLE servers DROP COLUMN unique_clicks;
+    END IF;
+END
+$$;
@@ -0,0 +1,112 @@
+// Package longdesc renders long descriptions to HTML that is safe to embed.
+//
+// Long descriptions are markdown, HTML or a mix of both, so they are rendered
+// as markdown with raw HTML passed through, and the result is then sanitized
+// against an allowlist (see policy). Rendering is cached by a hash of the
+// description, so an edit is picked up immediately and unchanged descriptions
+// are only rendered once.
+package longdesc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"time"
+
+	"popplio/state"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/redis/go-redis/v9"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+const (
+	// pipelineVersion is part of the cache key, bump it whenever the markdown
+	// options or the policy change so nothing rendered by the old ones is
+	// served
+	pipelineVersion = "1"
+
+	// cacheExpiry only bounds how long descriptions nobody reads stay cached
+	cacheExpiry = 7 * 24 * time.Hour
+)
+
+var (
+	markdown = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(
+			// Sanitizing happens afterwards, and descriptions are often
+			// written as HTML
+			html.WithUnsafe(),
+		),
+	)
+
+	policy = newPolicy()
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+
+	// Links and images
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	p.AllowURLSchemes("http", "https", "mailto")
+	p.AllowAttrs("width", "height").Matching(regexp.MustCompile(`^[0-9]{1,4}(px|%)?$`)).OnElements("img")
+
+	// Layout commonly used in READMEs
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|right|center)$`)).OnElements("p", "div", "img", "h1", "h2", "h3", "h4", "h5", "h6", "td", "th")
+	p.AllowElements("center", "details", "summary", "kbd", "mark")
+
+	// Syntax highlighting hints from fenced code blocks
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[a-zA-Z0-9_+-]+$`)).OnElements("code")
+
+	// GFM task lists
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+
+	return p
+}
+
+// Render converts a long description to sanitized HTML, without the cache.
+func Render(long string) (string, error) {
+	var buf bytes.Buffer
+
+	if err := markdown.Convert([]byte(long), &buf); err != nil {
+		return "", err
+	}
+
+	return policy.SanitizeReader(&buf).String(), nil
+}
+
+// Get returns the sanitized HTML of a long description, rendering it if it
+// isn't cached.
+func Get(ctx context.Context, long string) (string, error) {
+	sum := sha256.Sum256([]byte(long))
+	key := "long_html:" + pipelineVersion + ":" + hex.EncodeToString(sum[:])
+
+	cached, err := state.Redis.Get(ctx, key).Result()
+
+	if err == nil {
+		return cached, nil
+	}
+
+	if !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+
+	rendered, err := Render(long)
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := state.Redis.Set(ctx, key, rendered, cacheExpiry).Err(); err != nil {
+		return "", err
+	}
+
+	return rendered, nil
+}
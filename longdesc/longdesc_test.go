@@ -0,0 +1,58 @@
+package longdesc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		name    string
+		long    string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "markdown",
+			long: "# Title\n\nSome **bold** text and a [link](https://example.com).\n\n```go\nfmt.Println()\n```",
+			want: []string{"<h1", "<strong>bold</strong>", `href="https://example.com"`, `rel="nofollow`, `class="language-go"`},
+		},
+		{
+			name: "html",
+			long: `<div align="center"><img src="https://example.com/a.png" width="200"><p>Hi</p></div>`,
+			want: []string{`align="center"`, `src="https://example.com/a.png"`, `width="200"`, "<p>Hi</p>"},
+		},
+		{
+			name:    "scripts and handlers",
+			long:    `<script>alert(1)</script><img src="x" onerror="alert(1)"><a href="javascript:alert(1)">x</a>`,
+			notWant: []string{"<script", "onerror", "javascript:"},
+		},
+		{
+			name:    "styles and frames",
+			long:    `<style>body{}</style><iframe src="https://example.com"></iframe><p style="position:fixed">x</p>`,
+			notWant: []string{"<style", "<iframe", "style="},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Render(c.long)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, w := range c.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("want %q in %q", w, got)
+				}
+			}
+
+			for _, w := range c.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("did not want %q in %q", w, got)
+				}
+			}
+		})
+	}
+}
@@ -13,12 +13,15 @@ import (
 	"popplio/api"
 	poplapps "popplio/apps"
 	"popplio/arcadia"
+	"popplio/audit"
 	"popplio/bgtasks"
 	"popplio/config"
 	"popplio/constants"
 	"popplio/notifications/votereminders"
 	"popplio/routes/alerts"
+	"popplio/routes/announcements"
 	"popplio/routes/apps"
+	"popplio/routes/auditlog"
 	"popplio/routes/auth"
 	"popplio/routes/blogs"
 	"popplio/routes/bots"
@@ -30,6 +33,7 @@ import (
 	"popplio/routes/platform"
 	"popplio/routes/reminders"
 	"popplio/routes/reviews"
+	"popplio/routes/savedsearches"
 	"popplio/routes/servers"
 	"popplio/routes/shop"
 	"popplio/routes/staff"
@@ -40,8 +44,10 @@ import (
 	"popplio/routes/vanity"
 	"popplio/routes/votes"
 	"popplio/routes/webhooks"
+	"popplio/routes/widgets"
 	"popplio/state"
 	"popplio/types"
+	"popplio/uptime"
 	poplhooks "popplio/webhooks"
 
 	"github.com/cloudflare/tableflip"
@@ -102,6 +108,24 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// timeoutMiddleware is middleware.Timeout for everything except Server-Sent
+// Events streaming routes (see api.RouteStream), which are meant to stay open
+// far longer than any normal request.
+func timeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		timed := middleware.Timeout(timeout)(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if api.IsStreamRoute(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			timed.ServeHTTP(w, r)
+		})
+	}
+}
+
 func main() {
 	state.Setup()
 
@@ -153,12 +177,15 @@ func main() {
 		middleware.CleanPath,
 		corsMiddleware,
 		zapchi.Logger(state.Logger, "api"),
-		middleware.Timeout(30*time.Second),
+		timeoutMiddleware(30*time.Second),
+		audit.Middleware,
 	)
 
 	routers := []uapi.APIRouter{
 		alerts.Router{},
+		announcements.Router{},
 		apps.Router{},
+		auditlog.Router{},
 		auth.Router{},
 		blogs.Router{},
 		bots.Router{},
@@ -170,6 +197,7 @@ func main() {
 		platform.Router{},
 		reminders.Router{},
 		reviews.Router{},
+		savedsearches.Router{},
 		servers.Router{},
 		shop.Router{},
 		staff.Router{},
@@ -180,6 +208,7 @@ func main() {
 		vanity.Router{},
 		votes.Router{},
 		webhooks.Router{},
+		widgets.Router{},
 	}
 
 	for _, router := range routers {
@@ -263,6 +292,8 @@ func main() {
 
 	bgtasks.Start(state.Context)
 
+	state.Discord.AddEventListeners(uptime.Listener(state.Context))
+
 	arc := arcadia.Start(state.Context)
 	defer arc.Stop(30 * time.Second)
 
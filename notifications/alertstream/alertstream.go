@@ -0,0 +1,133 @@
+// Package alertstream fans alert changes out to every open
+// GET /users/{id}/alerts/stream connection, on every API process.
+//
+// Writers never hand events to local listeners directly. Behind a tableflip
+// upgrade two processes briefly serve side by side, and production runs more
+// than one replica, so the process that saved an alert is usually not the one
+// holding the user's stream. Every change is published to Redis instead, and
+// each process keeps a single pattern subscription that dispatches to its own
+// listeners.
+//
+// Delivery is best effort. A listener that falls too far behind is
+// disconnected rather than silently skipped over, so the client reconnects
+// and refetches the alert list instead of showing a stale unread count.
+package alertstream
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/infinitybotlist/eureka/jsonimpl"
+	"go.uber.org/zap"
+)
+
+const channelPrefix = "alertstream:"
+
+// listenerBuffer is how many undelivered events a single stream may have
+// queued before it is considered lagging and disconnected.
+const listenerBuffer = 32
+
+var (
+	startOnce sync.Once
+
+	mu        sync.Mutex
+	listeners = map[string]map[chan types.AlertStreamEvent]struct{}{}
+)
+
+// Publish sends ev to every stream open for userID, on any process.
+//
+// UnackedCount is filled in here from the database, after whatever change ev
+// describes has been committed, so every publisher reports it the same way.
+func Publish(ctx context.Context, userID string, ev types.AlertStreamEvent) error {
+	err := state.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM alerts WHERE user_id = $1 AND acked = false", userID).Scan(&ev.UnackedCount)
+
+	if err != nil {
+		return fmt.Errorf("counting unacked alerts: %w", err)
+	}
+
+	bytes, err := jsonimpl.Marshal(ev)
+
+	if err != nil {
+		return fmt.Errorf("marshalling event: %w", err)
+	}
+
+	return state.Redis.Publish(ctx, channelPrefix+userID, bytes).Err()
+}
+
+// Subscribe registers a listener for userID's events. The returned channel is
+// closed if the listener lags behind; cancel must be called once the caller
+// is done with it.
+func Subscribe(userID string) (events <-chan types.AlertStreamEvent, cancel func()) {
+	startOnce.Do(start)
+
+	ch := make(chan types.AlertStreamEvent, listenerBuffer)
+
+	mu.Lock()
+	if listeners[userID] == nil {
+		listeners[userID] = map[chan types.AlertStreamEvent]struct{}{}
+	}
+	listeners[userID][ch] = struct{}{}
+	mu.Unlock()
+
+	return ch, func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// A lagging listener has already been removed and closed by dispatch
+		if _, ok := listeners[userID][ch]; !ok {
+			return
+		}
+
+		delete(listeners[userID], ch)
+
+		if len(listeners[userID]) == 0 {
+			delete(listeners, userID)
+		}
+
+		close(ch)
+	}
+}
+
+// start opens this process's one pattern subscription. go-redis reconnects
+// and resubscribes on its own, so this runs for the life of the process.
+func start() {
+	ps := state.Redis.PSubscribe(state.Context, channelPrefix+"*")
+
+	go func() {
+		for msg := range ps.Channel() {
+			var ev types.AlertStreamEvent
+
+			if err := jsonimpl.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+				state.Logger.Error("Failed to unmarshal alert stream event", zap.Error(err), zap.String("channel", msg.Channel))
+				continue
+			}
+
+			dispatch(strings.TrimPrefix(msg.Channel, channelPrefix), ev)
+		}
+	}()
+}
+
+func dispatch(userID string, ev types.AlertStreamEvent) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for ch := range listeners[userID] {
+		select {
+		case ch <- ev:
+		default:
+			state.Logger.Warn("Alert stream listener lagging, disconnecting", zap.String("userID", userID))
+
+			delete(listeners[userID], ch)
+			close(ch)
+		}
+	}
+
+	if len(listeners[userID]) == 0 {
+		delete(listeners, userID)
+	}
+}
@@ -0,0 +1,208 @@
+// Package broadcast delivers staff announcements to every user in their
+// audience segment.
+//
+// Announcements are created through Arcadia's UpdateAnnouncements operation and
+// delivered here by the announcement_delivery background task. Delivery writes
+// one row into alerts per recipient, a batch at a time, so even an
+// announcement to every user never holds one huge transaction. Each batch
+// advances announcements.delivery_cursor in the same transaction as its
+// inserts, so a restart resumes exactly where delivery stopped.
+//
+// The alerts are only saved, not pushed. Web push and Discord DMs to every
+// user at once would be spam, and the site shows them on next load anyway.
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	// batchSize is how many alerts one delivery transaction inserts
+	batchSize = 500
+
+	// maxBatchesPerRun caps how much one run of Deliver does per
+	// announcement, so a large announcement cannot starve the others or
+	// overrun the task interval
+	maxBatchesPerRun = 20
+)
+
+// segments holds, per segment, a query selecting the user_id of every
+// recipient. Duplicates are fine, they are removed when batching. The segment
+// target, if the segment has one, is $9 (see deliverBatch).
+var segments = map[types.AnnouncementSegment]string{
+	types.AnnouncementSegmentAll: "SELECT user_id FROM users",
+	types.AnnouncementSegmentBotOwners: `SELECT owner FROM bots WHERE owner IS NOT NULL AND type IN ('approved', 'certified')
+		UNION SELECT tm.user_id FROM team_members tm JOIN bots b ON b.team_owner = tm.team_id WHERE b.type IN ('approved', 'certified')`,
+	types.AnnouncementSegmentCertifiedBotOwners: `SELECT owner FROM bots WHERE owner IS NOT NULL AND type = 'certified'
+		UNION SELECT tm.user_id FROM team_members tm JOIN bots b ON b.team_owner = tm.team_id WHERE b.type = 'certified'`,
+	types.AnnouncementSegmentPremiumOwners: `SELECT owner FROM bots WHERE owner IS NOT NULL AND premium = true
+		UNION SELECT tm.user_id FROM team_members tm JOIN bots b ON b.team_owner = tm.team_id WHERE b.premium = true`,
+	types.AnnouncementSegmentTeamMembers: "SELECT user_id FROM team_members WHERE team_id::text = $9",
+}
+
+// ValidSegment reports whether segment is one announcements can be sent to.
+func ValidSegment(segment string) bool {
+	_, ok := segments[types.AnnouncementSegment(segment)]
+	return ok
+}
+
+// SegmentNeedsTarget reports whether segment is relative to a target, which
+// must then be set on the announcement.
+func SegmentNeedsTarget(segment string) bool {
+	return types.AnnouncementSegment(segment) == types.AnnouncementSegmentTeamMembers
+}
+
+type dueAnnouncement struct {
+	ID        string                    `db:"id"`
+	Segment   types.AnnouncementSegment `db:"segment"`
+	Target    pgtype.Text               `db:"target"`
+	Title     string                    `db:"title"`
+	Content   string                    `db:"content"`
+	AlertType types.AlertType           `db:"alert_type"`
+	URL       pgtype.Text               `db:"url"`
+}
+
+// Deliver sends every announcement whose scheduled time has passed to the
+// next part of its audience.
+func Deliver(ctx context.Context) error {
+	rows, err := state.Pool.Query(
+		ctx,
+		"SELECT id::text AS id, segment, target, title, content, alert_type, url FROM announcements WHERE status IN ('pending', 'delivering') AND scheduled_for <= NOW() ORDER BY scheduled_for",
+	)
+
+	if err != nil {
+		return fmt.Errorf("querying due announcements: %w", err)
+	}
+
+	due, err := pgx.CollectRows(rows, pgx.RowToStructByName[dueAnnouncement])
+
+	if err != nil {
+		return fmt.Errorf("collecting due announcements: %w", err)
+	}
+
+	for _, a := range due {
+		for i := 0; i < maxBatchesPerRun; i++ {
+			done, err := deliverBatch(ctx, a)
+
+			if err != nil {
+				// Leave it for the next run, and let the rest go out meanwhile
+				state.Logger.Error("Failed to deliver announcement batch", zap.Error(err), zap.String("announcementID", a.ID))
+				break
+			}
+
+			if done {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// deliverBatch sends a to the next batch of its audience, returning true once
+// there is nobody left to send it to or it has been cancelled.
+func deliverBatch(ctx context.Context, a dueAnnouncement) (done bool, err error) {
+	query, ok := segments[a.Segment]
+
+	if !ok {
+		return false, fmt.Errorf("unknown segment %q", a.Segment)
+	}
+
+	tx, err := state.Pool.Begin(ctx)
+
+	if err != nil {
+		return false, fmt.Errorf("starting transaction: %w", err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// Locking the row serializes delivery against a staff cancel, and against
+	// the other process while a tableflip upgrade runs both
+	var status types.AnnouncementStatus
+	var cursor string
+
+	err = tx.QueryRow(ctx, "SELECT status, delivery_cursor FROM announcements WHERE id = $1 FOR UPDATE", a.ID).Scan(&status, &cursor)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("locking announcement: %w", err)
+	}
+
+	if status != types.AnnouncementStatusPending && status != types.AnnouncementStatusDelivering {
+		return true, nil
+	}
+
+	args := []any{
+		cursor,
+		batchSize,
+		a.AlertType,
+		a.URL,
+		a.Content,
+		a.Title,
+		map[string]any{"announcement_id": a.ID},
+		types.AlertPriorityMedium,
+	}
+
+	if SegmentNeedsTarget(string(a.Segment)) {
+		args = append(args, a.Target)
+	}
+
+	var count int64
+	var last pgtype.Text
+
+	err = tx.QueryRow(
+		ctx,
+		`WITH batch AS (
+			SELECT DISTINCT r.user_id FROM (`+query+`) r(user_id)
+			JOIN users u ON u.user_id = r.user_id
+			WHERE u.banned = false AND r.user_id > $1
+			ORDER BY r.user_id
+			LIMIT $2
+		), inserted AS (
+			INSERT INTO alerts (user_id, type, url, message, title, icon, alert_data, priority)
+			SELECT user_id, $3, $4, $5, $6, '', $7, $8 FROM batch
+		)
+		SELECT COUNT(*), MAX(user_id) FROM batch`,
+		args...,
+	).Scan(&count, &last)
+
+	if err != nil {
+		return false, fmt.Errorf("inserting alerts: %w", err)
+	}
+
+	if count == 0 {
+		_, err = tx.Exec(ctx, "UPDATE announcements SET status = 'delivered' WHERE id = $1", a.ID)
+
+		if err != nil {
+			return false, fmt.Errorf("marking delivered: %w", err)
+		}
+
+		return true, tx.Commit(ctx)
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE announcements SET status = 'delivering', delivery_cursor = $2, delivered_count = delivered_count + $3 WHERE id = $1",
+		a.ID,
+		last.String,
+		count,
+	)
+
+	if err != nil {
+		return false, fmt.Errorf("advancing cursor: %w", err)
+	}
+
+	return false, tx.Commit(ctx)
+}
@@ -0,0 +1,311 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Discord's "Cannot send messages to this user", returned when the user has
+// closed their DMs or shares no server with the bot
+const discordErrCannotDMUser rest.JSONErrorCode = 50007
+
+const (
+	// dmBatchWindow is the least time between two DMs to the same user.
+	// Alerts raised in between wait and go out together in the next one.
+	dmBatchWindow = 5 * time.Minute
+
+	// dmUsersPerFlush caps how many users one FlushDMQueue run sends to.
+	// Each costs two REST calls (open DM channel, send), so this keeps a
+	// burst of alerts well clear of Discord's global rate limit.
+	dmUsersPerFlush = 100
+
+	// dmQueueExpiry is how long a queued alert may wait (e.g. through a
+	// Discord outage) before it is dropped as no longer worth sending
+	dmQueueExpiry = 24 * time.Hour
+
+	// Discord allows at most 10 embeds per message
+	dmMaxEmbeds = 10
+
+	// Discord allows at most 6000 characters of text across all the embeds
+	// of a message
+	dmMaxEmbedChars = 6000
+
+	// dmSummaryChars is set aside out of dmMaxEmbedChars for the embed
+	// summarising the alerts left out of a DM
+	dmSummaryChars = 200
+)
+
+// ErrDMsClosed is returned by SendDM when Discord refuses to deliver to the
+// user at all.
+var ErrDMsClosed = errors.New("user does not accept DMs from Popplio")
+
+// queueDM queues notif for the user's next DM if they have opted in, and does
+// nothing otherwise.
+func queueDM(userId string, notif types.Alert) error {
+	_, err := state.Pool.Exec(
+		state.Context,
+		"INSERT INTO user_notification_dm_queue (user_id, alert) SELECT $1, $2 FROM user_notification_dms WHERE user_id = $1 AND enabled = true",
+		userId,
+		notif,
+	)
+
+	return err
+}
+
+// AlertEmbed renders an alert as a Discord embed.
+func AlertEmbed(notif types.Alert) discord.Embed {
+	embed := discord.Embed{
+		Title:       truncate(notif.Title, 256),
+		Description: truncate(notif.Message, 4096),
+		Color:       alertColor(notif.Type),
+	}
+
+	if notif.URL.Valid && strings.HasPrefix(notif.URL.String, "https://") {
+		embed.URL = notif.URL.String
+	}
+
+	if strings.HasPrefix(notif.Icon, "https://") {
+		embed.Thumbnail = &discord.EmbedResource{URL: notif.Icon}
+	}
+
+	if notif.CreatedAt.Valid {
+		embed.Timestamp = &notif.CreatedAt.Time
+	}
+
+	return embed
+}
+
+func alertColor(t types.AlertType) int {
+	switch t {
+	case types.AlertTypeSuccess:
+		return 0x00ff00
+	case types.AlertTypeError:
+		return 0xff0000
+	case types.AlertTypeWarning:
+		return 0xffa500
+	default:
+		return 0x5865f2
+	}
+}
+
+// truncate cuts s to n characters, which is how Discord counts embed limits
+func truncate(s string, n int) string {
+	r := []rune(s)
+
+	if len(r) <= n {
+		return s
+	}
+
+	return string(r[:n-3]) + "..."
+}
+
+// SendDM DMs msg to the user from Popplio's own bot, returning ErrDMsClosed
+// if Discord will not deliver to them.
+func SendDM(userId string, msg discord.MessageCreate) error {
+	id, err := snowflake.Parse(userId)
+
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	dmchan, err := state.Discord.Rest().CreateDMChannel(id)
+
+	if err != nil {
+		return wrapDMErr(err)
+	}
+
+	_, err = state.Discord.Rest().CreateMessage(dmchan.ID(), msg)
+
+	if err != nil {
+		return wrapDMErr(err)
+	}
+
+	return nil
+}
+
+func wrapDMErr(err error) error {
+	var restErr rest.Error
+
+	if errors.As(err, &restErr) && restErr.Code == discordErrCannotDMUser {
+		return fmt.Errorf("%w: %s", ErrDMsClosed, restErr.Message)
+	}
+
+	return err
+}
+
+// FlushDMQueue sends every user whose batch window has passed one DM holding
+// everything queued for them.
+//
+// A user whose DMs turn out to be closed has the channel disabled and their
+// queue dropped, and is told so through their other channels. Any other
+// failure leaves the queue as it was to be retried on the next run.
+func FlushDMQueue(ctx context.Context) error {
+	_, err := state.Pool.Exec(ctx, "DELETE FROM user_notification_dm_queue WHERE created_at < NOW() - make_interval(secs => $1)", dmQueueExpiry.Seconds())
+
+	if err != nil {
+		return fmt.Errorf("expiring old queued DMs: %w", err)
+	}
+
+	rows, err := state.Pool.Query(
+		ctx,
+		`SELECT q.user_id FROM user_notification_dm_queue q
+		JOIN user_notification_dms d ON d.user_id = q.user_id
+		WHERE d.enabled = true AND (d.last_sent_at IS NULL OR d.last_sent_at < NOW() - make_interval(secs => $1))
+		GROUP BY q.user_id
+		ORDER BY MIN(q.created_at)
+		LIMIT $2`,
+		dmBatchWindow.Seconds(),
+		dmUsersPerFlush,
+	)
+
+	if err != nil {
+		return fmt.Errorf("querying queued DMs: %w", err)
+	}
+
+	userIds, err := pgx.CollectRows(rows, pgx.RowTo[string])
+
+	if err != nil {
+		return fmt.Errorf("collecting queued DMs: %w", err)
+	}
+
+	for _, userId := range userIds {
+		if err := flushUserDMs(ctx, userId); err != nil {
+			// One user's failure should not hold up everyone else's DMs
+			state.Logger.Error("Failed to flush queued DMs", zap.Error(err), zap.String("userID", userId))
+		}
+	}
+
+	return nil
+}
+
+func flushUserDMs(ctx context.Context, userId string) error {
+	tx, err := state.Pool.Begin(ctx)
+
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(
+		ctx,
+		"WITH dequeued AS (DELETE FROM user_notification_dm_queue WHERE user_id = $1 RETURNING alert, created_at) SELECT alert FROM dequeued ORDER BY created_at",
+		userId,
+	)
+
+	if err != nil {
+		return fmt.Errorf("dequeueing: %w", err)
+	}
+
+	alerts, err := pgx.CollectRows(rows, pgx.RowTo[types.Alert])
+
+	if err != nil {
+		return fmt.Errorf("collecting: %w", err)
+	}
+
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	err = SendDM(userId, discord.MessageCreate{Embeds: batchEmbeds(alerts)})
+
+	if errors.Is(err, ErrDMsClosed) {
+		// Commit the dequeue too, nothing queued can be delivered anyway
+		return disableDMs(ctx, tx, userId, "Your Discord DMs are closed to Popplio, so DM notifications were turned off. Allow DMs from server members and turn them back on to keep receiving them.")
+	}
+
+	if err != nil {
+		return fmt.Errorf("sending: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE user_notification_dms SET last_sent_at = NOW() WHERE user_id = $1", userId)
+
+	if err != nil {
+		return fmt.Errorf("updating last_sent_at: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// batchEmbeds renders alerts, oldest first, as the embeds of one DM. If they
+// don't all fit in a message, the newest that do are kept and the rest
+// summarised, the full list is always on the site.
+func batchEmbeds(alerts []types.Alert) []discord.Embed {
+	embeds := make([]discord.Embed, 0, len(alerts))
+	chars := 0
+
+	for _, alert := range alerts {
+		embed := AlertEmbed(alert)
+		embeds = append(embeds, embed)
+		chars += embedChars(embed)
+	}
+
+	if len(embeds) <= dmMaxEmbeds && chars <= dmMaxEmbedChars {
+		return embeds
+	}
+
+	// One alert embed is always well under the budget, so at least the
+	// newest alert is kept
+	budget := dmMaxEmbedChars - dmSummaryChars
+	kept := 0
+
+	for i := len(embeds) - 1; i >= 0 && kept < dmMaxEmbeds-1; i-- {
+		n := embedChars(embeds[i])
+
+		if n > budget {
+			break
+		}
+
+		budget -= n
+		kept++
+	}
+
+	skipped := len(embeds) - kept
+
+	return append([]discord.Embed{{
+		Title:       "And " + strconv.Itoa(skipped) + " more alerts",
+		Description: "See all your alerts on " + state.Config.Sites.Frontend.Parse(),
+		Color:       alertColor(types.AlertTypeInfo),
+	}}, embeds[skipped:]...)
+}
+
+// embedChars counts the characters of an embed that Discord holds against
+// dmMaxEmbedChars. AlertEmbed only sets a title and description.
+func embedChars(embed discord.Embed) int {
+	return utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+}
+
+func disableDMs(ctx context.Context, tx pgx.Tx, userId, reason string) error {
+	_, err := tx.Exec(ctx, "UPDATE user_notification_dms SET enabled = false, disabled_reason = $2 WHERE user_id = $1", userId, reason)
+
+	if err != nil {
+		return fmt.Errorf("disabling DMs: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		return fmt.Errorf("committing: %w", err)
+	}
+
+	// Now disabled, so this only goes to the site and push notifications
+	return PushNotification(userId, types.Alert{
+		Type:    types.AlertTypeWarning,
+		Title:   "Discord DM notifications turned off",
+		Message: reason,
+	})
+}
@@ -0,0 +1,366 @@
+// Package savedsearches alerts users when a newly listed bot or server matches
+// one of their saved searches.
+//
+// Approving a bot and adding a server call Enqueue, which only records the
+// entity. The saved_search_alerts background task then runs Evaluate, which
+// matches each queued entity against every saved search for its target type
+// and alerts the owners of the searches it matches. Matching mirrors the
+// WHERE clause of POST /list/search, so a saved search alerts for exactly what
+// running it would newly list.
+//
+// Each search alerts at most once per entity, and each user is sent at most
+// maxAlertsPerDay alerts a day however many of their searches match. Matches
+// past the limit are still recorded, so they are not alerted for later either.
+package savedsearches
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"popplio/notifications"
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/infinitybotlist/eureka/dovewing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxPerUser is how many saved searches a user may have
+	MaxPerUser = 10
+
+	// maxAlertsPerDay is how many saved search alerts a user is sent in a
+	// rolling day
+	maxAlertsPerDay = 20
+
+	// queueBatch is how many queued entities one run of Evaluate handles
+	queueBatch = 50
+)
+
+// Enqueue queues a newly listed entity to be matched against saved searches.
+// Only bots and servers are searchable this way.
+func Enqueue(ctx context.Context, targetType, targetID string) error {
+	_, err := state.Pool.Exec(
+		ctx,
+		"INSERT INTO saved_search_queue (target_id, target_type) VALUES ($1, $2) ON CONFLICT (target_id, target_type) DO UPDATE SET queued_at = NOW()",
+		targetID,
+		targetType,
+	)
+
+	return err
+}
+
+// Validate returns a user facing error if q cannot be saved.
+func Validate(q types.SearchQuery) error {
+	if q.Query == "" && len(q.TagFilter.Tags) == 0 {
+		return errors.New("A saved search must have a query or at least one tag")
+	}
+
+	if q.TagFilter.TagMode != "" && q.TagFilter.TagMode != types.TagModeAll && q.TagFilter.TagMode != types.TagModeAny {
+		return errors.New("Invalid tag mode")
+	}
+
+	for _, targetType := range q.TargetTypes {
+		if targetType != "bot" && targetType != "server" {
+			return errors.New("Saved searches can only match bots and servers")
+		}
+	}
+
+	return nil
+}
+
+type queued struct {
+	TargetID   string `db:"target_id"`
+	TargetType string `db:"target_type"`
+}
+
+// entity is what a saved search's filters are checked against
+type entity struct {
+	Servers      int64
+	Votes        int64
+	Shards       int64
+	TotalMembers int64
+	NSFW         bool
+}
+
+type savedSearch struct {
+	ID     pgtype.UUID       `db:"id"`
+	UserID string            `db:"user_id"`
+	Name   string            `db:"name"`
+	Query  types.SearchQuery `db:"query"`
+}
+
+// Evaluate matches the next batch of queued entities against saved searches.
+func Evaluate(ctx context.Context) error {
+	rows, err := state.Pool.Query(ctx, "SELECT target_id, target_type FROM saved_search_queue ORDER BY queued_at LIMIT $1", queueBatch)
+
+	if err != nil {
+		return fmt.Errorf("querying queue: %w", err)
+	}
+
+	batch, err := pgx.CollectRows(rows, pgx.RowToStructByName[queued])
+
+	if err != nil {
+		return fmt.Errorf("collecting queue: %w", err)
+	}
+
+	for _, q := range batch {
+		if err := evaluateEntity(ctx, q); err != nil {
+			// Leave it queued for the next run
+			state.Logger.Error("Failed to match entity against saved searches", zap.Error(err), zap.String("targetID", q.TargetID), zap.String("targetType", q.TargetType))
+			continue
+		}
+
+		_, err = state.Pool.Exec(ctx, "DELETE FROM saved_search_queue WHERE target_id = $1 AND target_type = $2", q.TargetID, q.TargetType)
+
+		if err != nil {
+			return fmt.Errorf("dequeueing: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func evaluateEntity(ctx context.Context, q queued) error {
+	e, listed, err := getEntity(ctx, q)
+
+	if err != nil {
+		return err
+	}
+
+	// Denied or removed again before we got to it
+	if !listed {
+		return nil
+	}
+
+	rows, err := queryCandidates(ctx, q)
+
+	if err != nil {
+		return fmt.Errorf("querying saved searches: %w", err)
+	}
+
+	searches, err := pgx.CollectRows(rows, pgx.RowToStructByName[savedSearch])
+
+	if err != nil {
+		return fmt.Errorf("collecting saved searches: %w", err)
+	}
+
+	var info *entityInfo
+
+	// A user with several matching searches is alerted once
+	alerted := map[string]bool{}
+
+	for _, s := range searches {
+		if alerted[s.UserID] || !matchesFilters(s.Query, q.TargetType, e) {
+			continue
+		}
+
+		tag, err := state.Pool.Exec(
+			ctx,
+			"INSERT INTO saved_search_matches (search_id, target_id, target_type) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+			s.ID,
+			q.TargetID,
+			q.TargetType,
+		)
+
+		if err != nil {
+			return fmt.Errorf("recording match: %w", err)
+		}
+
+		if tag.RowsAffected() == 0 {
+			continue
+		}
+
+		alerted[s.UserID] = true
+
+		_, err = state.Pool.Exec(ctx, "UPDATE saved_searches SET last_matched_at = NOW() WHERE id = $1", s.ID)
+
+		if err != nil {
+			return fmt.Errorf("updating last match: %w", err)
+		}
+
+		allowed, err := underDailyLimit(ctx, s.UserID)
+
+		if err != nil {
+			return fmt.Errorf("checking alert limit: %w", err)
+		}
+
+		if !allowed {
+			continue
+		}
+
+		if info == nil {
+			info, err = describe(ctx, q)
+
+			if err != nil {
+				return fmt.Errorf("getting entity info: %w", err)
+			}
+		}
+
+		err = notifications.PushNotification(s.UserID, types.Alert{
+			Type:    types.AlertTypeInfo,
+			URL:     pgtype.Text{String: info.URL, Valid: true},
+			Message: fmt.Sprintf("%s was just listed and matches your saved search \"%s\"", info.Name, s.Name),
+			Title:   "New match for " + s.Name,
+			Icon:    info.Avatar,
+			AlertData: map[string]any{
+				"saved_search_id": s.ID,
+				"target_id":       q.TargetID,
+				"target_type":     q.TargetType,
+			},
+			Priority: types.AlertPriorityLow,
+		})
+
+		if err != nil {
+			state.Logger.Error("Failed to send saved search alert", zap.Error(err), zap.String("userID", s.UserID), zap.String("targetID", q.TargetID))
+		}
+	}
+
+	return nil
+}
+
+// getEntity returns the searchable fields of q, and whether it is listed
+func getEntity(ctx context.Context, q queued) (e entity, listed bool, err error) {
+	switch q.TargetType {
+	case "bot":
+		err = state.Pool.QueryRow(
+			ctx,
+			"SELECT type IN ('approved', 'certified'), servers, approximate_votes, shards, nsfw FROM bots WHERE bot_id = $1",
+			q.TargetID,
+		).Scan(&listed, &e.Servers, &e.Votes, &e.Shards, &e.NSFW)
+	case "server":
+		err = state.Pool.QueryRow(
+			ctx,
+			"SELECT type IN ('approved', 'certified') AND state = 'public', total_members, approximate_votes, nsfw FROM servers WHERE server_id = $1",
+			q.TargetID,
+		).Scan(&listed, &e.TotalMembers, &e.Votes, &e.NSFW)
+	default:
+		return e, false, nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return e, false, nil
+	}
+
+	return e, listed, err
+}
+
+type entityInfo struct {
+	Name   string
+	URL    string
+	Avatar string
+}
+
+// describe returns what an alert about q shows
+func describe(ctx context.Context, q queued) (*entityInfo, error) {
+	info := &entityInfo{
+		URL: state.Config.Sites.Frontend.Parse() + "/" + q.TargetType + "/" + q.TargetID,
+	}
+
+	switch q.TargetType {
+	case "bot":
+		bot, err := dovewing.GetUser(ctx, q.TargetID, state.DovewingPlatformDiscord)
+
+		if err != nil {
+			return nil, err
+		}
+
+		info.Name = bot.Username
+		info.Avatar = bot.Avatar
+	case "server":
+		err := state.Pool.QueryRow(ctx, "SELECT name, avatar FROM servers WHERE server_id = $1", q.TargetID).Scan(&info.Name, &info.Avatar)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return info, nil
+}
+
+// candidatesQuery finds the saved searches for the target type $2 whose tags
+// and text query match an entity, leaving the rest of their filters to
+// matchesFilters. %[1]s is the entity's table, %[2]s its id column and %[3]s
+// the text match of POST /list/search against the lowered query f.q.
+const candidatesQuery = `SELECT s.id, s.user_id, s.name, s.query FROM saved_searches s
+	JOIN %[1]s e ON e.%[2]s = $1
+	CROSS JOIN LATERAL (SELECT
+		lower(COALESCE(s.query->>'query', '')) AS q,
+		canonical_tags(ARRAY(SELECT jsonb_array_elements_text(CASE WHEN jsonb_typeof(s.query->'tags'->'tags') = 'array' THEN s.query->'tags'->'tags' ELSE '[]' END))) AS tags
+	) f
+	WHERE (
+		s.query->'target_types' ? $2
+		OR ($2 = 'bot' AND COALESCE(jsonb_array_length(CASE WHEN jsonb_typeof(s.query->'target_types') = 'array' THEN s.query->'target_types' END), 0) = 0)
+	)
+	AND (cardinality(f.tags) = 0 OR CASE WHEN s.query->'tags'->>'tag_mode' = '@>' THEN e.tags @> f.tags ELSE e.tags && f.tags END)
+	AND (f.q = '' OR %[3]s)
+	ORDER BY s.created_at`
+
+// queryCandidates runs candidatesQuery for q. Searches without target types
+// default to bots, as in POST /list/search.
+func queryCandidates(ctx context.Context, q queued) (pgx.Rows, error) {
+	var sql string
+
+	switch q.TargetType {
+	case "bot":
+		sql = fmt.Sprintf(candidatesQuery, "bots", "bot_id", `e.search_vector @@ websearch_to_tsquery('english', f.q)
+			OR e.search_name % f.q OR e.search_name ILIKE '%' || f.q || '%'
+			OR e.bot_id = f.q OR e.client_id = f.q`)
+	case "server":
+		sql = fmt.Sprintf(candidatesQuery, "servers", "server_id", `e.search_vector @@ websearch_to_tsquery('english', f.q)
+			OR e.name % f.q OR e.name ILIKE '%' || f.q || '%'
+			OR e.server_id = f.q`)
+	default:
+		return nil, fmt.Errorf("unsupported target type %q", q.TargetType)
+	}
+
+	return state.Pool.Query(ctx, sql, q.TargetID, q.TargetType)
+}
+
+// matchesFilters checks the filters of s that candidatesQuery leaves out
+func matchesFilters(s types.SearchQuery, targetType string, e entity) bool {
+	if e.NSFW && !s.IncludeNSFW {
+		return false
+	}
+
+	inRange := func(f types.SearchFilter, v int64) bool {
+		return (f.From == 0 || v >= f.From) && (f.To == 0 || v <= f.To)
+	}
+
+	if !inRange(s.Votes, e.Votes) {
+		return false
+	}
+
+	switch targetType {
+	case "bot":
+		return inRange(s.Servers, e.Servers) && inRange(s.Shards, e.Shards)
+	case "server":
+		return inRange(s.TotalMembers, e.TotalMembers)
+	}
+
+	return true
+}
+
+// underDailyLimit counts an alert to userID, returning false if it would go
+// over their daily limit
+func underDailyLimit(ctx context.Context, userID string) (bool, error) {
+	key := "saved_search_alerts:" + userID
+
+	count, err := state.Redis.Incr(ctx, key).Result()
+
+	if err != nil {
+		return false, err
+	}
+
+	if count == 1 {
+		if err := state.Redis.Expire(ctx, key, 24*time.Hour).Err(); err != nil {
+			return false, err
+		}
+	}
+
+	return count <= maxAlertsPerDay, nil
+}
@@ -1,4 +1,5 @@
-// Package notifications delivers web push notifications to users.
+// Package notifications delivers alerts to users, as web push notifications
+// and, for users who opted in, Discord DMs.
 //
 // Alerts are validated before being sent, since a malformed payload would be
 // rejected by the push service rather than by us, and the failure would
@@ -7,6 +8,7 @@ package notifications
 
 import (
 	"fmt"
+	"popplio/notifications/alertstream"
 	"popplio/state"
 	"popplio/types"
 
@@ -26,10 +28,10 @@ func PushNotification(userId string, notif types.Alert) error {
 		notif.AlertData = map[string]any{}
 	}
 
-	if notif.NoSave {
-		_, err = state.Pool.Exec(
+	if !notif.NoSave {
+		err = state.Pool.QueryRow(
 			state.Context,
-			"INSERT INTO alerts (user_id, type, url, message, title, icon, alert_data, priority) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+			"INSERT INTO alerts (user_id, type, url, message, title, icon, alert_data, priority) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING itag, created_at",
 			userId,
 			notif.Type,
 			notif.URL,
@@ -38,12 +40,31 @@ func PushNotification(userId string, notif types.Alert) error {
 			notif.Icon,
 			notif.AlertData,
 			notif.Priority,
-		)
+		).Scan(&notif.ITag, &notif.CreatedAt)
 
 		if err != nil {
 			state.Logger.Error("Error inserting alert", zap.Error(err), zap.String("user_id", userId), zap.Any("alert", notif))
 			return err
 		}
+
+		// Only saved alerts are streamed, an unsaved one has no itag to ack
+		// and would vanish from the bell on the next refetch anyway
+		err = alertstream.Publish(state.Context, userId, types.AlertStreamEvent{
+			Event: types.AlertStreamEventCreated,
+			Alert: &notif,
+		})
+
+		if err != nil {
+			state.Logger.Error("Error publishing alert to stream", zap.Error(err), zap.String("user_id", userId))
+		}
+	}
+
+	// Queued even when unsaved, vote reminders are exactly what users who
+	// never enabled browser push are missing
+	err = queueDM(userId, notif)
+
+	if err != nil {
+		state.Logger.Error("Error queueing alert for Discord DM", zap.Error(err), zap.String("user_id", userId))
 	}
 
 	bytes, err := jsonimpl.Marshal(notif)
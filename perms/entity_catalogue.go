@@ -14,8 +14,9 @@ const (
 	// EntityOwner implies every other entity permission.
 	EntityOwner Perm = "owner"
 
-	EntityEditTeam  Perm = "edit_team"
-	EntitySetVanity Perm = "set_vanity"
+	EntityEditTeam     Perm = "edit_team"
+	EntitySetVanity    Perm = "set_vanity"
+	EntityViewAuditLog Perm = "view_audit_log"
 
 	EntityAddBots             Perm = "add_bots"
 	EntityEditBots            Perm = "edit_bots"
@@ -30,6 +31,7 @@ const (
 	EntityAddMembers    Perm = "add_team_members"
 	EntityEditMembers   Perm = "edit_team_members"
 	EntityRemoveMembers Perm = "remove_team_members"
+	EntityManageRoles   Perm = "manage_team_roles"
 
 	EntityViewWebhooks       Perm = "view_webhooks"
 	EntityManageWebhooks     Perm = "manage_webhooks"
@@ -44,8 +46,8 @@ const (
 	EntityRedeemVoteCredits Perm = "redeem_vote_credits"
 )
 
-// Entity is what a team member may do. Teams have no roles, so a member's
-// `flags` and an API session's `perm_limits` are the whole source.
+// Entity is what a team member may do. A member's permissions come from their
+// roles and the extras in their `flags`, and an API session's `perm_limits`.
 var Entity = NewCatalogue("entity", EntityOwner, []Definition{
 	{
 		ID:          EntityOwner,
@@ -69,6 +71,12 @@ var Entity = NewCatalogue("entity", EntityOwner, []Definition{
 		Category:    "General",
 		Legacy:      []string{"team.set_vanity", "bot.set_vanity", "server.set_vanity", "global.set_vanity"},
 	},
+	{
+		ID:          EntityViewAuditLog,
+		Name:        "View Audit Log",
+		Description: "See the audit log of who changed what on the team and everything it owns.",
+		Category:    "General",
+	},
 
 	{
 		ID:          EntityAddBots,
@@ -159,6 +167,13 @@ var Entity = NewCatalogue("entity", EntityOwner, []Definition{
 		Category:    "Members",
 		Legacy:      []string{"team_member.delete", "team_member.remove"},
 	},
+	{
+		ID:          EntityManageRoles,
+		Name:        "Manage Roles",
+		Description: "Create, edit and delete the team's roles below their own.",
+		Category:    "Members",
+		Dangerous:   true,
+	},
 
 	{
 		ID:          EntityViewWebhooks,
@@ -12,8 +12,10 @@
 //     the staff server. The resync task mirrors role membership into
 //     `staff_members.positions`, so granting someone a Discord role grants them
 //     that role's permissions. Extras are `staff_members.perm_overrides`.
-//   - Team permissions live in `team_members.flags`, and API session scopes in
-//     `api_sessions.perm_limits`. Neither has roles yet, so they are all extras.
+//   - Team roles live in `team_roles`, defined by each team and assigned
+//     through `team_members.roles`. Extras are `team_members.flags`.
+//   - API session scopes live in `api_sessions.perm_limits`. They have no
+//     roles, so they are all extras.
 //
 // A holder's effective permissions are the union of every source. Nothing
 // subtracts. That is the whole model, and it is why a permission check is a
@@ -37,7 +37,7 @@ func (c *Catalogue) SetFromStrings(list []string) Set {
 }
 
 // Role is one source of permissions: a staff position, which is bound to a
-// Discord role in the staff server.
+// Discord role in the staff server, or a team role.
 //
 // Index ranks roles against each other, lower being more senior. It has no
 // effect on which permissions resolve — the union does not care about order —
@@ -75,7 +75,7 @@ func (c *Catalogue) Resolve(roles []Role, extras ...Perm) Set {
 }
 
 // ResolveStrings is [Catalogue.Resolve] for a single flat source, which is what
-// team members and API sessions have.
+// API session scopes and a team member's own flags are.
 func (c *Catalogue) ResolveStrings(list []string) Set {
 	return c.SetFromStrings(list)
 }
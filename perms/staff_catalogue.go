@@ -41,6 +41,9 @@ const (
 
 	StaffManagePartners Perm = "manage_partners"
 	StaffManageBlog     Perm = "manage_blog"
+	StaffManageTags     Perm = "manage_tags"
+
+	StaffManageAnnouncements Perm = "manage_announcements"
 
 	StaffViewTickets Perm = "view_tickets"
 
@@ -262,6 +265,18 @@ var Staff = NewCatalogue("staff", StaffAdministrator, []Definition{
 		Category:    "Content",
 		Legacy:      []string{"blog.create_entry", "blog.update_entry", "blog.delete_entry", "blog.*"},
 	},
+	{
+		ID:          StaffManageTags,
+		Name:        "Manage Tags",
+		Description: "Create, edit, merge, rename and retire the tags bots, servers, teams and packs can use.",
+		Category:    "Content",
+	},
+	{
+		ID:          StaffManageAnnouncements,
+		Name:        "Manage Announcements",
+		Description: "Create and cancel announcements broadcast to users.",
+		Category:    "Content",
+	},
 
 	{
 		ID:          StaffViewTickets,
@@ -0,0 +1,304 @@
+// Package promotions places premium bots and servers in the promoted slots of
+// the index and random listings, and measures how they perform.
+//
+// Every request for a surface picks its premium entities afresh. Each entity
+// is scored by how many impressions it has had today across the list, divided
+// by the weight of its plan, plus a random jitter of under one weighted
+// impression. The lowest scores win, so over a day an entity's share of
+// impressions tends to its share of the total weight, while early in the day
+// (and between entities with equal shares) the order is random. An entity a
+// visitor has already been shown FrequencyCap times in the last hour is only
+// picked again if there is nothing else to fill the slot with.
+//
+// Impressions and click-throughs are counted per surface and slot in Redis.
+// The promotion_rollup task (Rollup) adds them to promotion_stats_daily, which
+// Report reads. A click-through is the visitor opening the entity's page with
+// the promotion's Ref as the promo query parameter, and is only counted if the
+// visitor was shown the entity in the last hour, at most once an hour.
+package promotions
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"popplio/analytics"
+	"popplio/state"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Surface is a listing with promoted slots
+type Surface string
+
+const (
+	SurfaceBotIndex     Surface = "bot_index"
+	SurfaceBotRandom    Surface = "bot_random"
+	SurfaceServerIndex  Surface = "server_index"
+	SurfaceServerRandom Surface = "server_random"
+)
+
+// Surfaces are the surfaces with promoted slots, and how many each has
+var Surfaces = map[Surface]int{
+	SurfaceBotIndex:     9,
+	SurfaceBotRandom:    2,
+	SurfaceServerIndex:  9,
+	SurfaceServerRandom: 1,
+}
+
+// TargetType returns the target type promoted on s
+func (s Surface) TargetType() string {
+	if strings.HasPrefix(string(s), "server_") {
+		return "server"
+	}
+
+	return "bot"
+}
+
+const (
+	// FrequencyCap is how many times an hour a visitor is shown the same
+	// entity before others are preferred
+	FrequencyCap = 3
+
+	// seenExpiry is the window of FrequencyCap and of click attribution
+	seenExpiry = time.Hour
+
+	// servedExpiry only needs to outlive the day the count is for
+	servedExpiry = 48 * time.Hour
+
+	// pendingKey accumulates counts for Rollup, as fields of
+	// "<day>:<surface>:<slot>:<target_type>:<target_id>:<kind>"
+	pendingKey = "promotions:pending"
+
+	// flushingKey holds the counts being applied by a Rollup
+	flushingKey = "promotions:flushing"
+
+	// capped is added to the score of entities past FrequencyCap, putting
+	// them behind every other entity
+	capped = 1e12
+)
+
+const (
+	kindImpression = "impression"
+	kindClick      = "click"
+)
+
+// Weight returns the rotation weight of a premium period, by the plan that
+// grants it: gold (a year) is 3, silver (6 months) is 2 and bronze (a month)
+// or anything staff grant short of those is 1.
+func Weight(period time.Duration) int {
+	switch {
+	case period >= 365*24*time.Hour:
+		return 3
+	case period >= 180*24*time.Hour:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Placement is an entity picked for a promoted slot
+type Placement struct {
+	TargetID string
+
+	// Ref identifies the placement, and is passed back as the promo query
+	// parameter of the entity's page to attribute the click-through
+	Ref string
+}
+
+func ref(surface Surface, slot int) string {
+	return string(surface) + "." + strconv.Itoa(slot)
+}
+
+// parseRef returns the surface and slot of a Ref
+func parseRef(r string) (Surface, int, bool) {
+	s, slotStr, ok := strings.Cut(r, ".")
+
+	if !ok {
+		return "", 0, false
+	}
+
+	surface := Surface(s)
+	slot, err := strconv.Atoi(slotStr)
+
+	if err != nil || slot < 0 || slot >= Surfaces[surface] {
+		return "", 0, false
+	}
+
+	return surface, slot, true
+}
+
+func today() string {
+	return time.Now().UTC().Format(time.DateOnly)
+}
+
+func servedKey(targetType, day string) string {
+	return "promotions:served:" + targetType + ":" + day
+}
+
+func seenKey(visitor string) string {
+	return "promotions:seen:" + visitor
+}
+
+type candidate struct {
+	ID     string        `db:"id"`
+	Period time.Duration `db:"period"`
+	score  float64
+}
+
+func candidates(ctx context.Context, targetType string) ([]candidate, error) {
+	var sql string
+
+	switch targetType {
+	case "bot":
+		sql = "SELECT bot_id AS id, COALESCE(premium_period_length, '0') AS period FROM bots WHERE premium = true AND (type = 'approved' OR type = 'certified')"
+	case "server":
+		sql = "SELECT server_id AS id, COALESCE(premium_period_length, '0') AS period FROM servers WHERE premium = true AND state = 'public' AND (type = 'approved' OR type = 'certified')"
+	default:
+		return nil, fmt.Errorf("unsupported target type %q", targetType)
+	}
+
+	rows, err := state.Pool.Query(ctx, sql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[candidate])
+}
+
+// Pick fills the promoted slots of surface for the visitor of r, in slot
+// order, and counts their impressions. There are fewer placements than slots
+// when there aren't enough premium entities.
+func Pick(ctx context.Context, r *http.Request, surface Surface) ([]Placement, error) {
+	slots, ok := Surfaces[surface]
+
+	if !ok {
+		return nil, fmt.Errorf("unknown surface %q", surface)
+	}
+
+	targetType := surface.TargetType()
+
+	cands, err := candidates(ctx, targetType)
+
+	if err != nil {
+		return nil, fmt.Errorf("getting candidates: %w", err)
+	}
+
+	if len(cands) == 0 {
+		return []Placement{}, nil
+	}
+
+	day := today()
+	visitor := analytics.VisitorHash(r)
+
+	ids := make([]string, len(cands))
+	seenFields := make([]string, len(cands))
+
+	for i, c := range cands {
+		ids[i] = c.ID
+		seenFields[i] = targetType + ":" + c.ID
+	}
+
+	pipe := state.Redis.Pipeline()
+	servedCmd := pipe.HMGet(ctx, servedKey(targetType, day), ids...)
+	seenCmd := pipe.HMGet(ctx, seenKey(visitor), seenFields...)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("getting impression counts: %w", err)
+	}
+
+	served, seen := servedCmd.Val(), seenCmd.Val()
+
+	for i := range cands {
+		cands[i].score = float64(count(served[i]))/float64(Weight(cands[i].Period)) + rand.Float64()
+
+		if count(seen[i]) >= FrequencyCap {
+			cands[i].score += capped
+		}
+	}
+
+	slices.SortFunc(cands, func(a, b candidate) int {
+		switch {
+		case a.score < b.score:
+			return -1
+		case a.score > b.score:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	placements := make([]Placement, 0, min(slots, len(cands)))
+
+	pipe = state.Redis.TxPipeline()
+
+	for slot, c := range cands[:min(slots, len(cands))] {
+		placements = append(placements, Placement{TargetID: c.ID, Ref: ref(surface, slot)})
+
+		pipe.HIncrBy(ctx, servedKey(targetType, day), c.ID, 1)
+		pipe.HIncrBy(ctx, seenKey(visitor), targetType+":"+c.ID, 1)
+		pipe.HIncrBy(ctx, pendingKey, day+":"+ref(surface, slot)+":"+targetType+":"+c.ID+":"+kindImpression, 1)
+	}
+
+	pipe.Expire(ctx, servedKey(targetType, day), servedExpiry)
+	pipe.Expire(ctx, seenKey(visitor), seenExpiry)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("counting impressions: %w", err)
+	}
+
+	return placements, nil
+}
+
+// count reads a HMGET value, which is nil for missing fields
+func count(v any) int64 {
+	s, ok := v.(string)
+
+	if !ok {
+		return 0
+	}
+
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+// RecordClick counts a click-through to an entity's page from the placement
+// promoRef, if the visitor of r was shown the entity recently and hasn't
+// clicked through to it within the hour.
+func RecordClick(ctx context.Context, r *http.Request, targetType, targetID, promoRef string) error {
+	surface, slot, ok := parseRef(promoRef)
+
+	if !ok || surface.TargetType() != targetType {
+		return nil
+	}
+
+	visitor := analytics.VisitorHash(r)
+
+	shown, err := state.Redis.HExists(ctx, seenKey(visitor), targetType+":"+targetID).Result()
+
+	if err != nil {
+		return err
+	}
+
+	if !shown {
+		return nil
+	}
+
+	first, err := state.Redis.SetNX(ctx, "promotions:clicked:"+visitor+":"+targetType+":"+targetID, 1, seenExpiry).Result()
+
+	if err != nil {
+		return err
+	}
+
+	if !first {
+		return nil
+	}
+
+	return state.Redis.HIncrBy(ctx, pendingKey, today()+":"+ref(surface, slot)+":"+targetType+":"+targetID+":"+kindClick, 1).Err()
+}
@@ -0,0 +1,43 @@
+package promotions
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeight(t *testing.T) {
+	cases := []struct {
+		hours int
+		want  int
+	}{
+		{24 * 30, 1},     // bronze
+		{24 * 30 * 6, 2}, // silver
+		{24 * 365, 3},    // gold
+		{24 * 366, 3},    // gold, leap year
+		{0, 1},           // staff granted with no period
+	}
+
+	for _, c := range cases {
+		if got := Weight(time.Duration(c.hours) * time.Hour); got != c.want {
+			t.Errorf("Weight(%dh) = %d, want %d", c.hours, got, c.want)
+		}
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	for surface, slots := range Surfaces {
+		for slot := range slots {
+			s, n, ok := parseRef(ref(surface, slot))
+
+			if !ok || s != surface || n != slot {
+				t.Errorf("parseRef(ref(%s, %d)) = %s, %d, %v", surface, slot, s, n, ok)
+			}
+		}
+	}
+
+	for _, bad := range []string{"", "bot_index", "bot_index.9", "bot_index.-1", "nope.0", "bot_random.x"} {
+		if _, _, ok := parseRef(bad); ok {
+			t.Errorf("parseRef(%q) was accepted", bad)
+		}
+	}
+}
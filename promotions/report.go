@@ -0,0 +1,119 @@
+package promotions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func ctr(impressions, clicks int64) float64 {
+	if impressions == 0 {
+		return 0
+	}
+
+	return float64(clicks) / float64(impressions)
+}
+
+// Report returns an entity's promotion report for the days from to to
+// inclusive, or nil if the entity doesn't exist.
+func Report(ctx context.Context, targetType, targetID string, from, to time.Time) (*types.PromotionReport, error) {
+	var sql string
+
+	switch targetType {
+	case "bot":
+		sql = "SELECT premium, COALESCE(premium_period_length, '0') FROM bots WHERE bot_id = $1"
+	case "server":
+		sql = "SELECT premium, COALESCE(premium_period_length, '0') FROM servers WHERE server_id = $1"
+	default:
+		return nil, fmt.Errorf("unsupported target type %q", targetType)
+	}
+
+	report := &types.PromotionReport{
+		From: from.Format(time.DateOnly),
+		To:   to.Format(time.DateOnly),
+	}
+
+	var period time.Duration
+
+	err := state.Pool.QueryRow(ctx, sql, targetID).Scan(&report.Premium, &period)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Premium {
+		report.Weight = Weight(period)
+	}
+
+	rows, err := state.Pool.Query(
+		ctx,
+		`SELECT to_char(d, 'YYYY-MM-DD') AS day,
+			COALESCE(SUM(p.impressions), 0) AS impressions,
+			COALESCE(SUM(p.clicks), 0) AS clicks
+		FROM generate_series($3::date, $4::date, '1 day') d
+		LEFT JOIN promotion_stats_daily p ON p.target_type = $1 AND p.target_id = $2 AND p.day = d::date
+		GROUP BY d
+		ORDER BY d`,
+		targetType,
+		targetID,
+		from,
+		to,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	report.Days, err = pgx.CollectRows(rows, pgx.RowToStructByName[types.PromotionDay])
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = state.Pool.Query(
+		ctx,
+		`SELECT surface, slot, SUM(impressions) AS impressions, SUM(clicks) AS clicks
+		FROM promotion_stats_daily
+		WHERE target_type = $1 AND target_id = $2 AND day >= $3 AND day <= $4
+		GROUP BY surface, slot
+		ORDER BY SUM(impressions) DESC, surface, slot`,
+		targetType,
+		targetID,
+		from,
+		to,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	report.Placements, err = pgx.CollectRows(rows, pgx.RowToStructByName[types.PromotionPlacement])
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range report.Placements {
+		p := &report.Placements[i]
+		p.ClickThroughRate = ctr(p.Impressions, p.Clicks)
+	}
+
+	for _, d := range report.Days {
+		report.Impressions += d.Impressions
+		report.Clicks += d.Clicks
+	}
+
+	report.ClickThroughRate = ctr(report.Impressions, report.Clicks)
+
+	return report, nil
+}
@@ -0,0 +1,58 @@
+package promotions
+
+import (
+	"context"
+	"strings"
+
+	"popplio/counters"
+	"popplio/state"
+)
+
+// Rollup adds the impressions and click-throughs counted since the last run to
+// promotion_stats_daily (see counters.Flush).
+func Rollup(ctx context.Context) error {
+	return counters.Flush(ctx, pendingKey, flushingKey, func(field string, n int64) error {
+		parts := strings.Split(field, ":")
+
+		if len(parts) != 5 {
+			return nil
+		}
+
+		day, promoRef, targetType, targetID, kind := parts[0], parts[1], parts[2], parts[3], parts[4]
+
+		surface, slot, ok := parseRef(promoRef)
+
+		if !ok {
+			return nil
+		}
+
+		var impressions, clicks int64
+
+		switch kind {
+		case kindImpression:
+			impressions = n
+		case kindClick:
+			clicks = n
+		default:
+			return nil
+		}
+
+		_, err := state.Pool.Exec(
+			ctx,
+			`INSERT INTO promotion_stats_daily (target_type, target_id, day, surface, slot, impressions, clicks)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (target_type, target_id, day, surface, slot) DO UPDATE SET
+				impressions = promotion_stats_daily.impressions + EXCLUDED.impressions,
+				clicks = promotion_stats_daily.clicks + EXCLUDED.clicks`,
+			targetType,
+			targetID,
+			day,
+			surface,
+			slot,
+			impressions,
+			clicks,
+		)
+
+		return err
+	})
+}
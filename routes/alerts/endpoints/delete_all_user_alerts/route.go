@@ -7,6 +7,7 @@ package delete_all_user_alerts
 import (
 	"net/http"
 	"popplio/api/resp"
+	"popplio/notifications/alertstream"
 	"popplio/state"
 	"popplio/types"
 
@@ -39,5 +40,13 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Err("Failed to delete alerts", err, zap.String("userID", d.Auth.ID))
 	}
 
+	err = alertstream.Publish(d.Context, d.Auth.ID, types.AlertStreamEvent{
+		Event: types.AlertStreamEventCleared,
+	})
+
+	if err != nil {
+		state.Logger.Error("Failed to publish cleared alerts to stream", zap.Error(err), zap.String("userID", d.Auth.ID))
+	}
+
 	return uapi.DefaultResponse(http.StatusNoContent)
 }
@@ -8,6 +8,7 @@ package patch_user_alerts
 import (
 	"net/http"
 	"popplio/api/resp"
+	"popplio/notifications/alertstream"
 	"popplio/state"
 	"popplio/types"
 
@@ -83,5 +84,16 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Err("Error while committing transaction", err, zap.String("userID", d.Auth.ID))
 	}
 
+	// The patch itself succeeded, a stream that misses it only shows a stale
+	// unread count until the client next refetches
+	err = alertstream.Publish(d.Context, d.Auth.ID, types.AlertStreamEvent{
+		Event:   types.AlertStreamEventPatched,
+		Patches: payload.Patches,
+	})
+
+	if err != nil {
+		state.Logger.Error("Error while publishing alert patches to stream", zap.Error(err), zap.String("userID", d.Auth.ID))
+	}
+
 	return uapi.DefaultResponse(http.StatusNoContent)
 }
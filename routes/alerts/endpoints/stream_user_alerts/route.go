@@ -0,0 +1,117 @@
+// Package stream_user_alerts implements GET /users/{id}/alerts/stream —
+// "Stream User Alerts".
+//
+// Streams new alerts and read/unread changes as Server-Sent Events, so the
+// notification bell no longer has to poll GET /users/{id}/alerts.
+package stream_user_alerts
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"popplio/notifications/alertstream"
+	"popplio/state"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/jsonimpl"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+)
+
+// Proxies in front of Popplio drop connections idle for a minute, so a
+// comment line is sent well within that
+const heartbeatInterval = 25 * time.Second
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary: "Stream User Alerts",
+		Description: "Streams a users alerts as Server-Sent Events. Requires `Accept: text/event-stream`.\n\n" +
+			"A `ready` event carrying the current unacked count is sent on connect, followed by `created`, `patched` and `cleared` events as they happen, on any API instance. " +
+			"Browsers cannot set `Authorization` on an `EventSource`, so use a fetch-based SSE client.\n\n" +
+			"The stream may be closed at any time (restarts, or a client too slow to keep up). Clients should reconnect and refetch `GET /users/{id}/alerts`, as events sent while disconnected are not replayed.",
+		Resp: types.AlertStreamEvent{},
+		Params: []docs.Parameter{
+			{
+				Name:        "id",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+	}
+}
+
+func Route(d uapi.RouteData, w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+
+	if !ok {
+		state.Logger.Error("Response writer does not support flushing, cannot stream alerts", zap.String("userID", d.Auth.ID))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Subscribe before reading the unacked count, so nothing committed in
+	// between can be missed
+	events, cancel := alertstream.Subscribe(d.Auth.ID)
+	defer cancel()
+
+	ready := types.AlertStreamEvent{Event: types.AlertStreamEventReady}
+
+	err := state.Pool.QueryRow(d.Context, "SELECT COUNT(*) FROM alerts WHERE user_id = $1 AND acked = false", d.Auth.ID).Scan(&ready.UnackedCount)
+
+	if err != nil {
+		state.Logger.Error("Error getting unacked alert count for stream", zap.Error(err), zap.String("userID", d.Auth.ID))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeEvent(w, ready); err != nil {
+		return
+	}
+
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-d.Context.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := w.Write([]byte(": heartbeat\n\n")); err != nil {
+				return
+			}
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+		}
+
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, ev types.AlertStreamEvent) error {
+	bytes, err := jsonimpl.Marshal(ev)
+
+	if err != nil {
+		state.Logger.Error("Error marshalling alert stream event", zap.Error(err))
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Event, bytes)
+	return err
+}
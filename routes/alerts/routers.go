@@ -10,6 +10,7 @@ import (
 	"popplio/routes/alerts/endpoints/get_user_alert_by_itag"
 	"popplio/routes/alerts/endpoints/get_user_alerts"
 	"popplio/routes/alerts/endpoints/patch_user_alerts"
+	"popplio/routes/alerts/endpoints/stream_user_alerts"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/infinitybotlist/eureka/uapi"
@@ -92,6 +93,22 @@ func (b Router) Routes(r *chi.Mux) {
 		},
 	}.Route(r)
 
+	api.RouteStream(uapi.Route{
+		Pattern: "/users/{id}/alerts/stream",
+		OpId:    "stream_user_alerts",
+		Method:  uapi.GET,
+		Docs:    stream_user_alerts.Docs,
+		Auth: []uapi.AuthType{
+			{
+				URLVar: "id",
+				Type:   api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // No authorization is needed for this endpoint beyond defaults
+		},
+	}, stream_user_alerts.Route, r)
+
 	uapi.Route{
 		Pattern: "/users/{id}/alerts/{itag}",
 		OpId:    "get_user_alert_by_itag",
@@ -0,0 +1,68 @@
+// Package get_announcements implements GET /announcements — "Get Announcements".
+//
+// Gets the most recent public staff announcements
+package get_announcements
+
+import (
+	"net/http"
+	"popplio/api/resp"
+	"popplio/db"
+	"popplio/state"
+	"popplio/types"
+	"strings"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/dovewing"
+	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// How many announcements are returned, newest first
+const announcementLimit = 50
+
+var (
+	announcementColsArr = db.GetCols(types.Announcement{})
+
+	announcementCols = strings.Join(announcementColsArr, ",")
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get Announcements",
+		Description: "Gets the 50 most recent staff announcements, newest first. Only announcements whose delivery has started are listed, and announcements sent to the members of a single team are never listed.",
+		Resp:        types.AnnouncementList{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	rows, err := state.Pool.Query(
+		d.Context,
+		"SELECT "+announcementCols+" FROM announcements WHERE status IN ('delivering', 'delivered') AND segment != 'team_members' ORDER BY scheduled_for DESC LIMIT $1",
+		announcementLimit,
+	)
+
+	if err != nil {
+		return resp.Err("Error while fetching announcements [db query]", err)
+	}
+
+	announcements, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.Announcement])
+
+	if err != nil {
+		return resp.Err("Error while fetching announcements [collect]", err)
+	}
+
+	for i := range announcements {
+		announcements[i].Author, err = dovewing.GetUser(d.Context, announcements[i].UserID, state.DovewingPlatformDiscord)
+
+		if err != nil {
+			return resp.Err("Error while getting user [dovewing]", err, zap.String("user_id", announcements[i].UserID))
+		}
+	}
+
+	return uapi.HttpResponse{
+		Json: types.AnnouncementList{
+			Announcements: announcements,
+		},
+	}
+}
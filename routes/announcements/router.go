@@ -0,0 +1,29 @@
+// Package announcements mounts the "Announcements" group of API routes.
+//
+// These API endpoints are related to staff announcements on our list.
+package announcements
+
+import (
+	"popplio/routes/announcements/endpoints/get_announcements"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/infinitybotlist/eureka/uapi"
+)
+
+const tagName = "Announcements"
+
+type Router struct{}
+
+func (b Router) Tag() (string, string) {
+	return tagName, "These API endpoints are related to staff announcements on our list."
+}
+
+func (b Router) Routes(r *chi.Mux) {
+	uapi.Route{
+		Pattern: "/announcements",
+		OpId:    "get_announcements",
+		Method:  uapi.GET,
+		Docs:    get_announcements.Docs,
+		Handler: get_announcements.Route,
+	}.Route(r)
+}
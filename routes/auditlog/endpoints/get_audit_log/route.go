@@ -0,0 +1,147 @@
+// Package get_audit_log implements GET /{target_type}/{target_id}/audit-log
+// — "Get Audit Log".
+//
+// Gets the audit log of a bot, server, team or pack: who changed what on it
+// and when, newest first. Paginated to 20 at a time.
+package get_audit_log
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api"
+	"popplio/api/resp"
+	"popplio/audit"
+	"popplio/db"
+	"popplio/pagination"
+	packassets "popplio/routes/packs/assets"
+	"popplio/state"
+	"popplio/types"
+	"popplio/validators"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/dovewing"
+	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const perPage = 20
+
+var (
+	auditLogColsArr = db.GetCols(types.AuditLogEntry{})
+	auditLogCols    = strings.Join(auditLogColsArr, ",")
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary: "Get Audit Log",
+		Description: `Gets the audit log of a bot, server, team or pack: who changed what on it and when, newest first. Paginated to 20 at a time.
+
+Each entry holds only the fields the change touched, as they were before and after it. Entries are kept for a number of days set per target type, 90 by default.
+
+Requires the "View Audit Log" permission, or for packs, being the owner or a collaborator of the pack, which makes it the history of the pack. **Requires authentication**`,
+		Resp:     types.PagedResult[[]types.AuditLogEntry]{},
+		RespName: "PagedResultAuditLogEntry",
+		Params: []docs.Parameter{
+			{
+				Name:        "target_type",
+				Description: "The target type of the entity: bot, server, team or pack",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "target_id",
+				Description: "The target ID of the entity",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "page",
+				Description: "The page number",
+				Required:    false,
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+		},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	targetType := validators.NormalizeTargetType(chi.URLParam(r, "target_type"))
+	targetId := chi.URLParam(r, "target_id")
+
+	if !audit.Audited(targetType) {
+		return resp.BadRequest("Audit logs are only kept for bots, servers, teams and packs")
+	}
+
+	// Packs have no teams, so their owner and collaborators are the only ones
+	// who can see the log
+	if targetType == "pack" {
+		_, canEdit, err := packassets.Access(d.Context, targetId, d.Auth.ID)
+
+		if errors.Is(err, packassets.ErrPackNotFound) {
+			return resp.NotFound("Pack not found")
+		}
+
+		if err != nil {
+			return resp.Err("Error while checking pack owner [db fetch]", err, zap.String("id", targetId))
+		}
+
+		if d.Auth.TargetType != api.TargetTypeUser || !canEdit {
+			return resp.Forbidden("You are not the owner or a collaborator of this pack")
+		}
+	}
+
+	pageNum, err := pagination.Parse(r)
+
+	if err != nil {
+		return resp.BadRequest("Invalid page number")
+	}
+
+	limit := perPage
+	offset := (pageNum - 1) * perPage
+
+	rows, err := state.Pool.Query(d.Context, "SELECT "+auditLogCols+" FROM audit_log WHERE target_type = $1 AND target_id = $2 ORDER BY created_at DESC LIMIT $3 OFFSET $4", targetType, targetId, limit, offset)
+
+	if err != nil {
+		return resp.Err("Error while querying audit log [db fetch]", err, zap.String("userID", d.Auth.ID))
+	}
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.AuditLogEntry])
+
+	if err != nil {
+		return resp.Err("Error while querying audit log [collect]", err, zap.String("userID", d.Auth.ID))
+	}
+
+	for i, entry := range entries {
+		if entry.ActorType != api.TargetTypeUser {
+			continue
+		}
+
+		entries[i].Actor, err = dovewing.GetUser(d.Context, entry.ActorID, state.DovewingPlatformDiscord)
+
+		if err != nil {
+			return resp.Err("Error while querying audit log [dovewing]", err, zap.String("userID", d.Auth.ID))
+		}
+	}
+
+	var count uint64
+
+	err = state.Pool.QueryRow(d.Context, "SELECT COUNT(*) FROM audit_log WHERE target_type = $1 AND target_id = $2", targetType, targetId).Scan(&count)
+
+	if err != nil {
+		return resp.Err("Error while querying audit log [db count]", err, zap.String("userID", d.Auth.ID))
+	}
+
+	return uapi.HttpResponse{
+		Json: types.PagedResult[[]types.AuditLogEntry]{
+			Count:   count,
+			Results: entries,
+			PerPage: perPage,
+		},
+	}
+}
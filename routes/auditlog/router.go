@@ -0,0 +1,50 @@
+// Package auditlog mounts the "Audit Log" group of API routes.
+//
+// These API endpoints are related to the audit logs of entities on IBL
+package auditlog
+
+import (
+	"net/http"
+	"popplio/api"
+	"popplio/perms"
+	"popplio/routes/auditlog/endpoints/get_audit_log"
+	"popplio/validators"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/infinitybotlist/eureka/uapi"
+)
+
+const tagName = "Audit Log"
+
+type Router struct{}
+
+func (b Router) Tag() (string, string) {
+	return tagName, "These API endpoints are related to the audit logs of entities on IBL"
+}
+
+func (b Router) Routes(r *chi.Mux) {
+	uapi.Route{
+		Pattern: "/{target_type}/{target_id}/audit-log",
+		OpId:    "get_audit_log",
+		Method:  uapi.GET,
+		Docs:    get_audit_log.Docs,
+		Handler: get_audit_log.Route,
+		Auth:    api.GetAllAuthTypes(),
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: api.PermissionCheck{
+				// Packs are checked against their owner by the route itself
+				NeededPermission: func(d uapi.Route, r *http.Request, authData uapi.AuthData) (*perms.Perm, error) {
+					if validators.NormalizeTargetType(chi.URLParam(r, "target_type")) == "pack" {
+						return nil, nil
+					}
+
+					p := perms.EntityViewAuditLog
+					return &p, nil
+				},
+				GetTarget: func(d uapi.Route, r *http.Request, authData uapi.AuthData) (string, string) {
+					return validators.NormalizeTargetType(chi.URLParam(r, "target_type")), chi.URLParam(r, "target_id")
+				},
+			},
+		},
+	}.Route(r)
+}
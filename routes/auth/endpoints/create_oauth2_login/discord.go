@@ -6,11 +6,11 @@ import (
 	"net/http"
 	"net/url"
 
+	"popplio/api"
 	"popplio/api/resp"
 	"popplio/state"
 	"popplio/types"
 
-	"github.com/infinitybotlist/eureka/crypto"
 	"github.com/infinitybotlist/eureka/jsonimpl"
 	"github.com/infinitybotlist/eureka/uapi"
 )
@@ -301,12 +301,21 @@ func checkBanScope(ctx context.Context, userID, scope string) error {
 // expires_at estimate (see auth.ts's callback()) since this endpoint does not
 // return an expiry timestamp for the frontend to read.
 func createSession(ctx context.Context, userID, sessionName string) (token, sessionID string, err error) {
-	token = crypto.RandString(128)
+	token, err = api.NewSessionToken(api.TargetTypeUser)
+
+	if err != nil {
+		return "", "", &oauthError{
+			status:  http.StatusInternalServerError,
+			message: "Failed to create session token",
+			reason:  "Failed to generate session token",
+			cause:   err,
+		}
+	}
 
 	err = state.Pool.QueryRow(
 		ctx,
-		"INSERT INTO api_sessions (target_type, target_id, type, token, expiry, name) VALUES ('user', $1, 'login', $2, NOW() + INTERVAL '30 days', $3) RETURNING id",
-		userID, token, sessionName,
+		"INSERT INTO api_sessions (target_type, target_id, type, token_hash, expiry, name) VALUES ('user', $1, 'login', $2, NOW() + INTERVAL '30 days', $3) RETURNING id",
+		userID, api.HashSessionToken(token), sessionName,
 	).Scan(&sessionID)
 
 	if err != nil {
@@ -21,7 +21,6 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 
-	"github.com/infinitybotlist/eureka/crypto"
 	docs "github.com/infinitybotlist/eureka/doclib"
 	"github.com/infinitybotlist/eureka/uapi"
 )
@@ -142,15 +141,20 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 	}
 
 	// Create session
-	sessionToken := crypto.RandString(128)
+	sessionToken, err := api.NewSessionToken(targetType)
+
+	if err != nil {
+		return resp.ErrDetail("Error while generating session token", err)
+	}
+
 	var sessionId string
 
 	expiry := time.Now().Add(time.Duration(createData.Expiry) * time.Second)
 
 	err = state.Pool.QueryRow(
 		d.Context,
-		"INSERT INTO api_sessions (token, target_id, target_type, name, type, expiry, perm_limits) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
-		sessionToken,
+		"INSERT INTO api_sessions (token_hash, target_id, target_type, name, type, expiry, perm_limits) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		api.HashSessionToken(sessionToken),
 		targetId,
 		targetType,
 		createData.Name,
@@ -7,6 +7,7 @@ package revoke_session
 import (
 	"errors"
 	"net/http"
+	"popplio/api"
 	"popplio/api/resp"
 
 	"popplio/state"
@@ -82,5 +83,7 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Err("Error while revoking user session", err)
 	}
 
+	api.InvalidateSessionCache(d.Context, targetType, targetId)
+
 	return uapi.DefaultResponse(http.StatusNoContent)
 }
@@ -0,0 +1,59 @@
+package assets
+
+import (
+	"context"
+	"strings"
+
+	"popplio/db"
+	"popplio/promotions"
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var indexBotCols = strings.Join(db.GetCols(types.IndexBot{}), ",")
+
+// PromotedIndexBots returns the bots of placements in slot order, resolved
+// and with their promotion refs set. Bots that stopped being listed since
+// they were picked are left out.
+func PromotedIndexBots(ctx context.Context, placements []promotions.Placement) ([]types.IndexBot, error) {
+	ids := make([]string, len(placements))
+
+	for i, p := range placements {
+		ids[i] = p.TargetID
+	}
+
+	rows, err := state.Pool.Query(ctx, "SELECT "+indexBotCols+" FROM bots WHERE bot_id = ANY($1) AND (type = 'approved' OR type = 'certified')", ids)
+
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.IndexBot])
+
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]types.IndexBot, len(found))
+
+	for _, b := range found {
+		byID[b.BotID] = b
+	}
+
+	bots := make([]types.IndexBot, 0, len(placements))
+
+	for _, p := range placements {
+		if b, ok := byID[p.TargetID]; ok {
+			b.Promotion = p.Ref
+			bots = append(bots, b)
+		}
+	}
+
+	if err := ResolveIndexBots(ctx, bots); err != nil {
+		return nil, err
+	}
+
+	return bots, nil
+}
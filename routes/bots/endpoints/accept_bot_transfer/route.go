@@ -0,0 +1,74 @@
+// Package accept_bot_transfer implements POST
+// /users/{uid}/bot-transfers/{id}/accept — "Accept Bot Transfer".
+//
+// Accepts a bot transfer, making the user or team it is to the bot's owner.
+// Returns the accepted transfer on success
+package accept_bot_transfer
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/audit"
+	"popplio/transfers"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary: "Accept Bot Transfer",
+		Description: `Accepts a bot transfer, making the user or team it is to the bot's owner. Transfers to a team can be accepted by any member who can add bots to it.
+
+The transfer fails if the bot has changed hands, or whoever proposed it can no longer transfer the bot, since it was proposed. On success the bot's API sessions are revoked, since its previous owners hold their tokens, and its webhooks are paused until the new owner edits them. Both parties and the mod log are told.
+
+Returns the accepted transfer on success`,
+		Params: []docs.Parameter{
+			{
+				Name:        "uid",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "id",
+				Description: "The ID of the transfer",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.BotTransfer{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	id := chi.URLParam(r, "id")
+
+	// The bot is found up front so it can be snapshotted for the audit log
+	// before it changes hands
+	if t, err := transfers.GetBotTransfer(d.Context, id); err == nil && t != nil {
+		audit.Begin(r, "accept_bot_transfer", d.Auth, "bot", t.BotID)
+	}
+
+	transfer, err := transfers.AcceptBotTransfer(d.Context, d.Auth.ID, id)
+
+	switch {
+	case errors.Is(err, transfers.ErrTransferNotFound):
+		return resp.NotFound("Transfer not found, or it has expired")
+	case errors.Is(err, transfers.ErrTransferStale):
+		return resp.BadRequest(err.Error())
+	case err != nil:
+		return resp.Err("Error accepting bot transfer", err, zap.String("userID", d.Auth.ID), zap.String("transferID", id))
+	}
+
+	return uapi.HttpResponse{
+		Json: transfer,
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"popplio/api/resp"
+	"popplio/audit"
 	"regexp"
 	"slices"
 	"strings"
@@ -17,6 +18,7 @@ import (
 	"popplio/perms"
 	"popplio/routes/bots/assets"
 	"popplio/state"
+	"popplio/taxonomy"
 	"popplio/teams"
 	"popplio/types"
 	"popplio/validators"
@@ -120,6 +122,18 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.BadRequest(err.Error())
 	}
 
+	tags, tagErr, err := taxonomy.Normalize(d.Context, "bot", payload.Tags, nil)
+
+	if err != nil {
+		return resp.Err("Failed to normalize tags", err)
+	}
+
+	if tagErr != nil {
+		return resp.BadRequest(tagErr.Error())
+	}
+
+	payload.Tags = tags
+
 	// Check if the bot is already in the database
 	var count int
 
@@ -282,6 +296,8 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Err("Error while committing transaction", err, zap.String("userID", d.Auth.ID), zap.String("botID", payload.BotID))
 	}
 
+	audit.Created(r, "add_bot", d.Auth, api.TargetTypeBot, payload.BotID)
+
 	botAddedEmbed := discord.Embed{
 		URL:   state.Config.Sites.Frontend.Parse() + "/bots/" + payload.BotID,
 		Title: "New Bot Added",
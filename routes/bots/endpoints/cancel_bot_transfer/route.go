@@ -0,0 +1,62 @@
+// Package cancel_bot_transfer implements DELETE
+// /users/{uid}/bots/{bid}/transfer — "Cancel Bot Transfer".
+//
+// Cancels the pending transfer of a bot. Returns a 204 on success
+package cancel_bot_transfer
+
+import (
+	"net/http"
+	"popplio/api/resp"
+	"popplio/transfers"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Cancel Bot Transfer",
+		Description: "Cancels the pending transfer of a bot. Only the bot's owner, or an owner of the team that owns it, can cancel it. Returns a 204 on success",
+		Params: []docs.Parameter{
+			{
+				Name:        "uid",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "bid",
+				Description: "Bot ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.ApiError{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	id := chi.URLParam(r, "bid")
+
+	if err := transfers.CanTransferBot(d.Context, d.Auth.ID, id); err != nil {
+		return resp.Forbidden("You must own the bot to cancel its transfer: " + err.Error())
+	}
+
+	found, err := transfers.CancelBotTransfer(d.Context, id)
+
+	if err != nil {
+		return resp.Err("Error cancelling bot transfer", err, zap.String("botID", id), zap.String("userID", d.Auth.ID))
+	}
+
+	if !found {
+		return resp.NotFound("The bot has no pending transfer")
+	}
+
+	return uapi.DefaultResponse(http.StatusNoContent)
+}
@@ -0,0 +1,59 @@
+// Package decline_bot_transfer implements POST
+// /users/{uid}/bot-transfers/{id}/decline — "Decline Bot Transfer".
+//
+// Declines a bot transfer, alerting whoever proposed it. Returns a 204 on
+// success
+package decline_bot_transfer
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/transfers"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Decline Bot Transfer",
+		Description: "Declines a bot transfer, alerting whoever proposed it. Transfers to a team can be declined by any member who can add bots to it. Returns a 204 on success",
+		Params: []docs.Parameter{
+			{
+				Name:        "uid",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "id",
+				Description: "The ID of the transfer",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.ApiError{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	id := chi.URLParam(r, "id")
+
+	err := transfers.DeclineBotTransfer(d.Context, d.Auth.ID, id)
+
+	switch {
+	case errors.Is(err, transfers.ErrTransferNotFound):
+		return resp.NotFound("Transfer not found, or it has expired")
+	case err != nil:
+		return resp.Err("Error declining bot transfer", err, zap.String("userID", d.Auth.ID), zap.String("transferID", id))
+	}
+
+	return uapi.DefaultResponse(http.StatusNoContent)
+}
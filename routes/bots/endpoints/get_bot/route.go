@@ -4,18 +4,21 @@
 package get_bot
 
 import (
-	"crypto/sha256"
 	"errors"
-	"fmt"
 	"net/http"
+	"popplio/analytics"
 	"popplio/api/resp"
+	"popplio/botcommands"
 	"strings"
 
 	"popplio/db"
+	"popplio/longdesc"
+	"popplio/promotions"
 	botassets "popplio/routes/bots/assets"
 	"popplio/state"
 	"popplio/teams/resolvers"
 	"popplio/types"
+	"popplio/uptime"
 	"popplio/validators"
 	"popplio/votes"
 
@@ -52,7 +55,7 @@ func Docs() *docs.Doc {
 				Name: "target",
 				Description: `The target page of the request if any. 
 				
-If target is 'page', then unique clicks will be counted based on a SHA-256 hashed IP
+If target is 'page', then a view will be counted, and unique views counted based on a SHA-256 hashed IP
 
 If target is 'invite', then the invite will be counted as a click
 
@@ -66,9 +69,30 @@ Officially recognized targets:
 				In:       "query",
 				Schema:   docs.IdSchema,
 			},
+			{
+				Name:        "referrer",
+				Description: "The URL or host the visitor came from, counted with 'page' targets. Defaults to the Referer header",
+				Required:    false,
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "vanity",
+				Description: "Set to true if the visitor came through the vanity URL, counted with 'page' targets",
+				Required:    false,
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "promo",
+				Description: "The `promotion` of the promoted slot the visitor came from, if any. Counted as a click-through with 'page' targets",
+				Required:    false,
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
 			{
 				Name:        "include",
-				Description: "What extra fields to include, comma-seperated.`long` => bot long description",
+				Description: "What extra fields to include, comma-seperated.`long` => bot long description, `long_html` => bot long description rendered to sanitized HTML, `uptime` => uptime percentages and recent incidents, `commands` => the latest published command catalogue",
 				Required:    false,
 				In:          "query",
 				Schema:      docs.IdSchema,
@@ -86,58 +110,37 @@ Officially recognized targets:
 }
 
 func handleAnalytics(r *http.Request, id, target string) error {
+	var kind analytics.Kind
+
 	switch target {
 	case "page":
-		// Get IP from request and hash it
-		hashedIp := fmt.Sprintf("%x", sha256.Sum256([]byte(r.RemoteAddr)))
-
-		// Create transaction
-		tx, err := state.Pool.Begin(state.Context)
-
-		if err != nil {
-			return fmt.Errorf("error creating transaction: %w", err)
-		}
-
-		defer tx.Rollback(state.Context)
-
-		_, err = tx.Exec(state.Context, "UPDATE bots SET clicks = clicks + 1 WHERE bot_id = $1", id)
-
-		if err != nil {
-			return fmt.Errorf("error updating clicks count: %w", err)
-		}
-
-		// Check if the IP has already clicked the bot by checking the unique_clicks row
-		var hasClicked bool
-
-		err = tx.QueryRow(state.Context, "SELECT $1 = ANY(unique_clicks) FROM bots WHERE bot_id = $2", hashedIp, id).Scan(&hasClicked)
-
-		if err != nil {
-			return fmt.Errorf("error checking for any unique clicks from this user: %w", err)
-		}
+		kind = analytics.KindView
+	case "invite":
+		kind = analytics.KindInvite
+	default:
+		return nil
+	}
 
-		if !hasClicked {
-			// If not, add it to the array
-			state.Logger.Debug("Adding new unique click for user during handleAnalytics", zap.Error(err), zap.String("id", id), zap.String("target", target), zap.String("targetType", "bot"))
-			_, err = tx.Exec(state.Context, "UPDATE bots SET unique_clicks = array_append(unique_clicks, $1) WHERE bot_id = $2", hashedIp, id)
+	referrer := r.URL.Query().Get("referrer")
 
-			if err != nil {
-				return fmt.Errorf("error adding new unique click for user: %w", err)
-			}
-		}
+	if referrer == "" {
+		referrer = r.Referer()
+	}
 
-		// Commit transaction
-		err = tx.Commit(state.Context)
+	err := analytics.Record(state.Context, r, analytics.Click{
+		TargetType: "bot",
+		TargetID:   id,
+		Kind:       kind,
+		Referrer:   referrer,
+		ViaVanity:  r.URL.Query().Get("vanity") == "true",
+	})
 
-		if err != nil {
-			return fmt.Errorf("error committing transaction: %w", err)
-		}
-	case "invite":
-		// Update clicks
-		_, err := state.Pool.Exec(state.Context, "UPDATE bots SET invite_clicks = invite_clicks + 1 WHERE bot_id = $1", id)
+	if err != nil {
+		return err
+	}
 
-		if err != nil {
-			return fmt.Errorf("error updating invite clicks: %w", err)
-		}
+	if promo := r.URL.Query().Get("promo"); promo != "" && kind == analytics.KindView {
+		return promotions.RecordClick(state.Context, r, "bot", id, promo)
 	}
 
 	return nil
@@ -216,8 +219,7 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 	bot.User = botUser
 	botassets.ApplySelfStatus(bot.User, bot.SelfStatus.String, bot.Servers, bot.LastStatsPost)
 
-	var uniqueClicks int64
-	err = state.Pool.QueryRow(d.Context, "SELECT cardinality(unique_clicks) AS unique_clicks FROM bots WHERE bot_id = $1", bot.BotID).Scan(&uniqueClicks)
+	uniqueClicks, err := analytics.UniqueViews(d.Context, "bot", bot.BotID)
 
 	if err != nil {
 		return resp.ErrDetail("Error while getting bot unique clicks [db fetch]", err, zap.String("id", id), zap.String("target", target), zap.String("botID", bot.BotID))
@@ -269,6 +271,31 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 				}
 
 				bot.Long = long
+			case "long_html":
+				var long string
+				err := state.Pool.QueryRow(d.Context, "SELECT long FROM bots WHERE bot_id = $1", bot.BotID).Scan(&long)
+
+				if err != nil {
+					return resp.ErrDetail("Error while getting bot long description [db fetch]", err, zap.String("id", id), zap.String("target", target), zap.String("botID", bot.BotID))
+				}
+
+				bot.LongHTML, err = longdesc.Get(d.Context, long)
+
+				if err != nil {
+					return resp.ErrDetail("Error while rendering bot long description", err, zap.String("id", id), zap.String("target", target), zap.String("botID", bot.BotID))
+				}
+			case "uptime":
+				bot.UptimeStats, err = uptime.Get(d.Context, bot.BotID)
+
+				if err != nil {
+					return resp.ErrDetail("Error while getting bot uptime", err, zap.String("id", id), zap.String("target", target), zap.String("botID", bot.BotID))
+				}
+			case "commands":
+				bot.Commands, err = botcommands.Get(d.Context, bot.BotID, 0)
+
+				if err != nil {
+					return resp.ErrDetail("Error while getting bot commands", err, zap.String("id", id), zap.String("target", target), zap.String("botID", bot.BotID))
+				}
 			}
 		}
 	}
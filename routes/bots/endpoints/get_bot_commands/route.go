@@ -0,0 +1,71 @@
+// Package get_bot_commands implements GET /bots/{id}/commands — "Get Bot
+// Commands".
+//
+// Gets the bot's published command catalogue
+package get_bot_commands
+
+import (
+	"net/http"
+	"strconv"
+
+	"popplio/api/resp"
+	"popplio/botcommands"
+	"popplio/types"
+
+	"github.com/go-chi/chi/v5"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get Bot Commands",
+		Description: "Gets the bot's published command catalogue. Returns 404 if the bot has not published one, or the version asked for is no longer kept.",
+		Resp:        types.BotCommandCatalogue{},
+		Params: []docs.Parameter{
+			{
+				Name:        "id",
+				Description: "The bot's ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "version",
+				Description: "The version to get. Defaults to the latest",
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+		},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	id := chi.URLParam(r, "id")
+
+	var version int
+
+	if v := r.URL.Query().Get("version"); v != "" {
+		var err error
+		version, err = strconv.Atoi(v)
+
+		if err != nil || version < 1 {
+			return resp.BadRequest("version must be a positive integer")
+		}
+	}
+
+	catalogue, err := botcommands.Get(d.Context, id, version)
+
+	if err != nil {
+		return resp.Err("Error while getting commands", err, zap.String("botID", id))
+	}
+
+	if catalogue == nil {
+		return uapi.DefaultResponse(http.StatusNotFound)
+	}
+
+	return uapi.HttpResponse{
+		Json: catalogue,
+	}
+}
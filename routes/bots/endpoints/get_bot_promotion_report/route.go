@@ -0,0 +1,72 @@
+// Package get_bot_promotion_report implements GET /bots/{id}/promotion-report —
+// "Get Bot Promotion Report".
+//
+// Gets how the bot's promoted placements performed
+package get_bot_promotion_report
+
+import (
+	"net/http"
+
+	"popplio/analytics"
+	"popplio/api/resp"
+	"popplio/promotions"
+	"popplio/types"
+
+	"github.com/go-chi/chi/v5"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get Bot Promotion Report",
+		Description: "Gets the impressions and click-throughs of the bot's promoted placements, per day and per slot. Premium bots are rotated through the promoted slots of the index and random listings, weighted by plan. You must have 'Edit Bots' in the team if the bot is in a team. Days are UTC, oldest first, and a range covers at most 366 days.",
+		Resp:        types.PromotionReport{},
+		Params: []docs.Parameter{
+			{
+				Name:        "id",
+				Description: "The bot's ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "from",
+				Description: "The first day of the range, as YYYY-MM-DD. Defaults to 29 days before `to`",
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "to",
+				Description: "The last day of the range, as YYYY-MM-DD. Defaults to today",
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+		},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	id := chi.URLParam(r, "id")
+
+	from, to, err := analytics.ParseQuery(r.URL.Query())
+
+	if err != nil {
+		return resp.BadRequest(err.Error())
+	}
+
+	report, err := promotions.Report(d.Context, "bot", id, from, to)
+
+	if err != nil {
+		return resp.Err("Error while fetching promotion report", err, zap.String("botID", id))
+	}
+
+	if report == nil {
+		return uapi.DefaultResponse(http.StatusNotFound)
+	}
+
+	return uapi.HttpResponse{
+		Json: report,
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"popplio/db"
+	"popplio/promotions"
 	botAssets "popplio/routes/bots/assets"
 	"popplio/routes/packs/assets"
 	"popplio/state"
@@ -34,7 +35,7 @@ var (
 func Docs() *docs.Doc {
 	return &docs.Doc{
 		Summary:     "Get Bots Index",
-		Description: "Gets the index of the bot-side of the list. Returns a ``ListIndexBot`` object",
+		Description: "Gets the index of the bot-side of the list. Returns a ``ListIndexBot`` object.\n\nThe premium bots are picked by the promotion engine, which rotates every premium bot through the row weighted by plan. Each has a `promotion` to pass as the `promo` query parameter of Get Bot when opening its page.",
 		Resp:        types.ListIndexBot{},
 	}
 }
@@ -52,12 +53,12 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Err("Error while processing certified bots", err)
 	}
 
-	// Premium Bots
-	premRows, err := state.Pool.Query(d.Context, "SELECT "+indexBotCols+" FROM bots WHERE premium = true ORDER BY approximate_votes DESC LIMIT 9")
+	// Premium Bots, rotated by the promotion engine
+	placements, err := promotions.Pick(d.Context, r, promotions.SurfaceBotIndex)
 	if err != nil {
-		return resp.Err("Error while getting premium bots", err)
+		return resp.Err("Error while picking premium bots", err)
 	}
-	listIndex.Premium, err = processRow(d.Context, premRows)
+	listIndex.Premium, err = botAssets.PromotedIndexBots(d.Context, placements)
 	if err != nil {
 		return resp.Err("Error while processing premium bots", err)
 	}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"popplio/api/resp"
 	"popplio/db"
+	"popplio/promotions"
 	"popplio/routes/bots/assets"
 	"popplio/state"
 	"popplio/types"
@@ -25,15 +26,36 @@ var (
 func Docs() *docs.Doc {
 	return &docs.Doc{
 		Summary:     "Get Random Bots",
-		Description: "Returns a list of bots from the database in random order",
+		Description: "Returns a list of bots from the database in random order. The first bots are promoted premium bots picked by the promotion engine, and have a `promotion` to pass as the `promo` query parameter of Get Bot when opening their page.",
 		Resp: types.RandomBots{
 			Bots: []types.IndexBot{},
 		},
 	}
 }
 
+// count is how many bots are returned, including promoted ones
+const count = 6
+
 func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
-	rows, err := state.Pool.Query(d.Context, "SELECT "+indexBotCols+" FROM bots WHERE (type = 'approved' OR type = 'certified') ORDER BY RANDOM() LIMIT 6")
+	placements, err := promotions.Pick(d.Context, r, promotions.SurfaceBotRandom)
+
+	if err != nil {
+		return resp.Err("Error while picking promoted bots", err)
+	}
+
+	promoted, err := assets.PromotedIndexBots(d.Context, placements)
+
+	if err != nil {
+		return resp.ErrBody("Error resolving promoted indexbot", "An error occurred while resolving index bot.", err)
+	}
+
+	promotedIDs := make([]string, len(promoted))
+
+	for i, b := range promoted {
+		promotedIDs[i] = b.BotID
+	}
+
+	rows, err := state.Pool.Query(d.Context, "SELECT "+indexBotCols+" FROM bots WHERE (type = 'approved' OR type = 'certified') AND bot_id <> ALL($1) ORDER BY RANDOM() LIMIT $2", promotedIDs, count-len(promoted))
 
 	if err != nil {
 		return resp.Err("Error while getting random bots [db fetch]", err)
@@ -52,7 +74,7 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 
 	return uapi.HttpResponse{
 		Json: types.RandomBots{
-			Bots: bots,
+			Bots: append(promoted, bots...),
 		},
 	}
 }
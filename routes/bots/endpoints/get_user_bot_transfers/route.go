@@ -0,0 +1,46 @@
+// Package get_user_bot_transfers implements GET /users/{uid}/bot-transfers —
+// "Get User Bot Transfers".
+//
+// Gets the pending bot transfers a user can accept or decline, and those they
+// have proposed.
+package get_user_bot_transfers
+
+import (
+	"net/http"
+	"popplio/api/resp"
+	"popplio/transfers"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get User Bot Transfers",
+		Description: "Gets the pending bot transfers a user can accept or decline, which are those to them and those to teams they can add bots to, and the transfers they have proposed.",
+		Params: []docs.Parameter{
+			{
+				Name:        "uid",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.BotTransferList{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	list, err := transfers.UserBotTransfers(d.Context, d.Auth.ID)
+
+	if err != nil {
+		return resp.Err("Error getting bot transfers", err, zap.String("userID", d.Auth.ID))
+	}
+
+	return uapi.HttpResponse{
+		Json: list,
+	}
+}
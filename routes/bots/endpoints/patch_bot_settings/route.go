@@ -6,10 +6,12 @@
 package patch_bot_settings
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"popplio/api/resp"
 	"popplio/state"
+	"popplio/taxonomy"
 	"popplio/types"
 	"popplio/validators"
 	"reflect"
@@ -20,6 +22,7 @@ import (
 	docs "github.com/infinitybotlist/eureka/doclib"
 	"github.com/infinitybotlist/eureka/dovewing"
 	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 
 	"github.com/go-chi/chi/v5"
@@ -37,6 +40,7 @@ func updateBotsArgs(bot types.BotSettingsUpdate) []any {
 		bot.Tags,
 		bot.NSFW,
 		bot.CaptchaOptOut,
+		bot.OfflineAlertMinutes,
 	}
 }
 
@@ -101,6 +105,30 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.BadRequest(err.Error())
 	}
 
+	var currentTags []string
+
+	err = state.Pool.QueryRow(d.Context, "SELECT tags FROM bots WHERE bot_id = $1", id).Scan(&currentTags)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uapi.DefaultResponse(http.StatusNotFound)
+	}
+
+	if err != nil {
+		return resp.Err("Failed to get current tags", err, zap.String("id", id))
+	}
+
+	tags, tagErr, err := taxonomy.Normalize(d.Context, "bot", payload.Tags, currentTags)
+
+	if err != nil {
+		return resp.Err("Failed to normalize tags", err)
+	}
+
+	if tagErr != nil {
+		return resp.BadRequest(tagErr.Error())
+	}
+
+	payload.Tags = tags
+
 	// Get bot discord user
 	botUser, err := dovewing.GetUser(d.Context, id, state.DovewingPlatformDiscord)
 
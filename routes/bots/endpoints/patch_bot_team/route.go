@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"popplio/api"
 	"popplio/api/resp"
+	"popplio/audit"
 	"popplio/perms"
 	"popplio/state"
 	"popplio/types"
@@ -101,6 +102,8 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Forbidden("You must be able to add the bot in the new team to transfer it: " + err.Error())
 	}
 
+	audit.Begin(r, "patch_bot_team", d.Auth, api.TargetTypeBot, id)
+
 	// Get old team ID for audit log
 	var currentBotTeam pgtype.UUID
 
@@ -11,7 +11,9 @@ import (
 
 	"popplio/api/resp"
 	"popplio/state"
+	"popplio/statshistory"
 	"popplio/types"
+	"popplio/widgets"
 
 	docs "github.com/infinitybotlist/eureka/doclib"
 	"github.com/infinitybotlist/eureka/uapi"
@@ -109,11 +111,21 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		}
 	}
 
+	err = statshistory.RecordBot(d.Context, tx, d.Auth.ID)
+
+	if err != nil {
+		return resp.Err("Error while recording stats history", err, zap.String("botID", d.Auth.ID))
+	}
+
 	err = tx.Commit(d.Context)
 
 	if err != nil {
 		return resp.Err("Error while committing transaction", err, zap.String("botID", d.Auth.ID), zap.Any("payload", payload))
 	}
 
+	if err := widgets.Invalidate(d.Context, "bot", d.Auth.ID); err != nil {
+		state.Logger.Error("Failed to invalidate widgets", zap.Error(err), zap.String("botID", d.Auth.ID))
+	}
+
 	return resp.NoContent()
 }
@@ -0,0 +1,98 @@
+// Package propose_bot_transfer implements PUT /users/{uid}/bots/{bid}/transfer
+// — "Propose Bot Transfer".
+//
+// Proposes transferring a bot to another user or team, who has until the
+// transfer expires to accept it. Returns the transfer on success
+package propose_bot_transfer
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/state"
+	"popplio/transfers"
+	"popplio/types"
+	"time"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+var compiledMessages = uapi.CompileValidationErrors(types.ProposeBotTransfer{})
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary: "Propose Bot Transfer",
+		Description: `Proposes transferring a bot to another user or team. Only the bot's owner, or an owner of the team that owns it, can transfer it.
+
+The bot doesn't change hands until the new owner accepts, which they have until ` + "`expires_in_hours`" + ` (3 days by default) to do. For a team, any member who can add bots to it may accept. A bot can only have one pending transfer, so proposing another replaces it.
+
+Returns the transfer on success`,
+		Params: []docs.Parameter{
+			{
+				Name:        "uid",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "bid",
+				Description: "Bot ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Req:  types.ProposeBotTransfer{},
+		Resp: types.BotTransfer{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	id := chi.URLParam(r, "bid")
+
+	var payload types.ProposeBotTransfer
+
+	hresp, ok := uapi.MarshalReq(r, &payload)
+
+	if !ok {
+		return hresp
+	}
+
+	err := state.Validator.Struct(payload)
+
+	if err != nil {
+		return uapi.ValidatorErrorResponse(compiledMessages, err.(validator.ValidationErrors))
+	}
+
+	if err := transfers.CanTransferBot(d.Context, d.Auth.ID, id); err != nil {
+		return resp.Forbidden("You must own the bot to transfer it: " + err.Error())
+	}
+
+	expiry := transfers.DefaultBotTransferExpiry
+
+	if payload.ExpiresInHours > 0 {
+		expiry = time.Duration(payload.ExpiresInHours) * time.Hour
+	}
+
+	transfer, err := transfers.ProposeBotTransfer(d.Context, id, d.Auth.ID, payload.NewOwnerType, payload.NewOwnerID, expiry)
+
+	switch {
+	case errors.Is(err, transfers.ErrNewOwnerNotFound):
+		return resp.NotFound("The new owner does not exist. Users must login here at least once before bots can be transferred to them")
+	case errors.Is(err, transfers.ErrAlreadyOwner):
+		return resp.BadRequest(err.Error())
+	case err != nil:
+		return resp.Err("Error proposing bot transfer", err, zap.String("botID", id), zap.String("userID", d.Auth.ID))
+	}
+
+	return uapi.HttpResponse{
+		Status: http.StatusCreated,
+		Json:   transfer,
+	}
+}
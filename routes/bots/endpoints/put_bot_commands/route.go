@@ -0,0 +1,69 @@
+// Package put_bot_commands implements PUT /bots/commands — "Publish Bot
+// Commands".
+//
+// Publishes the bot's command catalogue
+package put_bot_commands
+
+import (
+	"net/http"
+
+	"popplio/api/resp"
+	"popplio/botcommands"
+	"popplio/state"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var compiledMessages = uapi.CompileValidationErrors(types.PublishBotCommands{})
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Publish Bot Commands",
+		Description: "Publishes the bot's command catalogue, replacing the previous one. Commands are shaped like Discord application commands, with an optional `category`, so a bot can publish what it registers with Discord. Publishing the same commands again keeps the current version and returns 200; otherwise a new version is created and 201 is returned. The latest 20 versions are kept.\n\nCommand names, descriptions and categories are searchable through list search.",
+		Req:         types.PublishBotCommands{},
+		Resp:        types.BotCommandCatalogue{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var payload types.PublishBotCommands
+
+	marshalResp, ok := uapi.MarshalReq(r, &payload)
+
+	if !ok {
+		return marshalResp
+	}
+
+	err := state.Validator.Struct(payload)
+
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		return uapi.ValidatorErrorResponse(compiledMessages, errors)
+	}
+
+	if err := botcommands.Validate(payload.Commands); err != nil {
+		return resp.BadRequest(err.Error())
+	}
+
+	catalogue, created, err := botcommands.Publish(d.Context, d.Auth.ID, payload.Commands)
+
+	if err != nil {
+		return resp.Err("Error while publishing commands", err, zap.String("botID", d.Auth.ID))
+	}
+
+	status := http.StatusOK
+
+	if created {
+		status = http.StatusCreated
+	}
+
+	return uapi.HttpResponse{
+		Json:   catalogue,
+		Status: status,
+	}
+}
@@ -7,17 +7,27 @@ import (
 	"net/http"
 	"popplio/api"
 	"popplio/perms"
+	"popplio/routes/bots/endpoints/accept_bot_transfer"
 	"popplio/routes/bots/endpoints/add_bot"
+	"popplio/routes/bots/endpoints/cancel_bot_transfer"
+	"popplio/routes/bots/endpoints/decline_bot_transfer"
 	"popplio/routes/bots/endpoints/delete_bot"
 	"popplio/routes/bots/endpoints/get_all_bots"
 	"popplio/routes/bots/endpoints/get_bot"
+	"popplio/routes/bots/endpoints/get_bot_analytics"
+	"popplio/routes/bots/endpoints/get_bot_commands"
 	"popplio/routes/bots/endpoints/get_bot_meta"
+	"popplio/routes/bots/endpoints/get_bot_promotion_report"
 	"popplio/routes/bots/endpoints/get_bot_seo"
+	"popplio/routes/bots/endpoints/get_bot_stats_history"
 	"popplio/routes/bots/endpoints/get_bots_index"
 	"popplio/routes/bots/endpoints/get_random_bots"
+	"popplio/routes/bots/endpoints/get_user_bot_transfers"
 	"popplio/routes/bots/endpoints/patch_bot_settings"
 	"popplio/routes/bots/endpoints/patch_bot_team"
 	"popplio/routes/bots/endpoints/post_bot_stats"
+	"popplio/routes/bots/endpoints/propose_bot_transfer"
+	"popplio/routes/bots/endpoints/put_bot_commands"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/infinitybotlist/eureka/uapi"
@@ -93,6 +103,57 @@ func (b Router) Routes(r *chi.Mux) {
 		Handler: get_bot_seo.Route,
 	}.Route(r)
 
+	uapi.Route{
+		Pattern: "/bots/{id}/analytics",
+		OpId:    "get_bot_analytics",
+		Method:  uapi.GET,
+		Docs:    get_bot_analytics.Docs,
+		Handler: get_bot_analytics.Route,
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/bots/{id}/promotion-report",
+		OpId:    "get_bot_promotion_report",
+		Method:  uapi.GET,
+		Docs:    get_bot_promotion_report.Docs,
+		Handler: get_bot_promotion_report.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type: api.TargetTypeUser,
+			},
+			{
+				Type: api.TargetTypeTeam,
+			},
+			{
+				Type: api.TargetTypeBot,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: api.PermissionCheck{
+				NeededPermission: api.Needs(perms.EntityEditBots),
+				GetTarget: func(d uapi.Route, r *http.Request, authData uapi.AuthData) (string, string) {
+					return api.TargetTypeBot, chi.URLParam(r, "id")
+				},
+			},
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/bots/{id}/commands",
+		OpId:    "get_bot_commands",
+		Method:  uapi.GET,
+		Docs:    get_bot_commands.Docs,
+		Handler: get_bot_commands.Route,
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/bots/{id}/stats/history",
+		OpId:    "get_bot_stats_history",
+		Method:  uapi.GET,
+		Docs:    get_bot_stats_history.Docs,
+		Handler: get_bot_stats_history.Route,
+	}.Route(r)
+
 	uapi.Route{
 		Pattern: "/bots/stats",
 		OpId:    "post_bot_stats",
@@ -109,6 +170,22 @@ func (b Router) Routes(r *chi.Mux) {
 		},
 	}.Route(r)
 
+	uapi.Route{
+		Pattern: "/bots/commands",
+		OpId:    "put_bot_commands",
+		Method:  uapi.PUT,
+		Docs:    put_bot_commands.Docs,
+		Handler: put_bot_commands.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type: api.TargetTypeBot,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // No authorization is needed for this endpoint beyond defaults
+		},
+	}.Route(r)
+
 	uapi.Route{
 		Pattern: "/bots",
 		OpId:    "add_bot",
@@ -197,4 +274,89 @@ func (b Router) Routes(r *chi.Mux) {
 			api.PERMISSION_CHECK_KEY: nil, // The endpoint itself handles authorization
 		},
 	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{uid}/bots/{bid}/transfer",
+		OpId:    "propose_bot_transfer",
+		Method:  uapi.PUT,
+		Docs:    propose_bot_transfer.Docs,
+		Handler: propose_bot_transfer.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type:   api.TargetTypeUser,
+				URLVar: "uid",
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // The endpoint itself handles authorization
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{uid}/bots/{bid}/transfer",
+		OpId:    "cancel_bot_transfer",
+		Method:  uapi.DELETE,
+		Docs:    cancel_bot_transfer.Docs,
+		Handler: cancel_bot_transfer.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type:   api.TargetTypeUser,
+				URLVar: "uid",
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // The endpoint itself handles authorization
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{uid}/bot-transfers",
+		OpId:    "get_user_bot_transfers",
+		Method:  uapi.GET,
+		Docs:    get_user_bot_transfers.Docs,
+		Handler: get_user_bot_transfers.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type:   api.TargetTypeUser,
+				URLVar: "uid",
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // No authorization is needed for this endpoint beyond defaults
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{uid}/bot-transfers/{id}/accept",
+		OpId:    "accept_bot_transfer",
+		Method:  uapi.POST,
+		Docs:    accept_bot_transfer.Docs,
+		Handler: accept_bot_transfer.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type:   api.TargetTypeUser,
+				URLVar: "uid",
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // The endpoint itself handles authorization
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{uid}/bot-transfers/{id}/decline",
+		OpId:    "decline_bot_transfer",
+		Method:  uapi.POST,
+		Docs:    decline_bot_transfer.Docs,
+		Handler: decline_bot_transfer.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type:   api.TargetTypeUser,
+				URLVar: "uid",
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // The endpoint itself handles authorization
+		},
+	}.Route(r)
 }
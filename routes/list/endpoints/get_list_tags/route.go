@@ -0,0 +1,97 @@
+// Package get_list_tags implements GET /list/tags — "Get List Tags".
+//
+// Lists every tag in use, with how many bots, servers, teams and packs use it
+package get_list_tags
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"popplio/api/resp"
+	"popplio/state"
+	"popplio/taxonomy"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/jsonimpl"
+	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const cacheExpiry = 5 * time.Minute
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get List Tags",
+		Description: "Lists every tag used by a listed bot, server, team or pack, with how many of each use it. Tags are tag IDs of the tag registry (see Get Tag Registry), sorted by total usage. Only approved and certified bots and public approved or certified servers are counted.\n\nThe list is cached for 5 minutes.",
+		Resp:        types.TagList{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	cached, err := state.Redis.Get(d.Context, taxonomy.ListCacheKey).Bytes()
+
+	if err == nil {
+		var tags types.TagList
+
+		if jsonimpl.Unmarshal(cached, &tags) == nil {
+			return uapi.HttpResponse{
+				Json: tags,
+			}
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		state.Logger.Error("Error reading tag list cache", zap.Error(err))
+	}
+
+	rows, err := state.Pool.Query(
+		d.Context,
+		`WITH used AS (
+			SELECT lower(unnest(tags)) AS tag, 'bot' AS target_type FROM bots WHERE type = 'approved' OR type = 'certified'
+			UNION ALL
+			SELECT lower(unnest(tags)), 'server' FROM servers WHERE (type = 'approved' OR type = 'certified') AND state = 'public'
+			UNION ALL
+			SELECT lower(unnest(tags)), 'team' FROM teams WHERE tags IS NOT NULL
+			UNION ALL
+			SELECT lower(unnest(tags)), 'pack' FROM packs
+		)
+		SELECT
+			tag,
+			COUNT(*) FILTER (WHERE target_type = 'bot') AS bots,
+			COUNT(*) FILTER (WHERE target_type = 'server') AS servers,
+			COUNT(*) FILTER (WHERE target_type = 'team') AS teams,
+			COUNT(*) FILTER (WHERE target_type = 'pack') AS packs
+		FROM used
+		WHERE tag != ''
+		GROUP BY tag
+		ORDER BY COUNT(*) DESC, tag`,
+	)
+
+	if err != nil {
+		return resp.Err("Failed to fetch tags", err)
+	}
+
+	tags, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.TagUsage])
+
+	if err != nil {
+		return resp.Err("Failed to collect tags", err)
+	}
+
+	list := types.TagList{Tags: tags}
+
+	bytes, err := jsonimpl.Marshal(list)
+
+	if err == nil {
+		err = state.Redis.Set(d.Context, taxonomy.ListCacheKey, bytes, cacheExpiry).Err()
+	}
+
+	if err != nil {
+		state.Logger.Error("Error caching tag list", zap.Error(err))
+	}
+
+	return uapi.HttpResponse{
+		Json: list,
+	}
+}
@@ -0,0 +1,35 @@
+// Package get_tag_registry implements GET /list/tags/registry — "Get Tag Registry".
+//
+// Lists the tags that bots, servers, teams and packs may be tagged with
+package get_tag_registry
+
+import (
+	"net/http"
+
+	"popplio/api/resp"
+	"popplio/taxonomy"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get Tag Registry",
+		Description: "Lists every tag of the tag registry, which the tags of bots, servers, teams and packs must come from. Tags sent when adding or editing an entity may be a tag's ID, one of its aliases or any spelling of either (case and punctuation are ignored), and are stored as the tag's ID.\n\nA tag can only be used on its `target_types`. Retired tags are kept by entities already using them, but can't be newly added.",
+		Resp:        types.TagRegistry{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	tags, err := taxonomy.List(d.Context)
+
+	if err != nil {
+		return resp.Err("Failed to fetch tags", err)
+	}
+
+	return uapi.HttpResponse{
+		Json: types.TagRegistry{Tags: tags},
+	}
+}
@@ -0,0 +1,87 @@
+package search_list
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"popplio/state"
+	"popplio/types"
+)
+
+// How many tags and libraries a facet lists, most used first
+const facetLimit = 50
+
+// The facet columns each target type selects from its matches. library is
+// NULL for servers, and size is server count for bots and total members for
+// servers.
+const (
+	botFacetCols    = "bots.tags, bots.library, bots.nsfw, bots.premium, bots.type = 'certified' AS certified, bots.servers AS size"
+	serverFacetCols = "servers.tags, NULL::text AS library, servers.nsfw, servers.premium, servers.type = 'certified' AS certified, servers.total_members AS size"
+)
+
+// sizeBuckets are inclusive like SearchFilter, so a bucket can be passed
+// straight back as the servers/total_members filter. 0 as the upper bound
+// means unbounded.
+var sizeBuckets = [][2]int64{{0, 99}, {100, 999}, {1000, 9999}, {10000, 99999}, {100000, 0}}
+
+var bucketCounts = func() string {
+	var counts []string
+
+	for _, b := range sizeBuckets {
+		if b[1] == 0 {
+			counts = append(counts, fmt.Sprintf("COUNT(*) FILTER (WHERE size >= %d)", b[0]))
+		} else {
+			counts = append(counts, fmt.Sprintf("COUNT(*) FILTER (WHERE size >= %d AND size <= %d)", b[0], b[1]))
+		}
+	}
+
+	return "ARRAY[" + strings.Join(counts, ", ") + "]"
+}()
+
+// runFacets aggregates over every match of the search in tctx, ignoring
+// pagination.
+func runFacets(ctx context.Context, tmpl *template.Template, tctx searchSqlTemplateCtx, facetCols string, args []any) (*types.SearchFacets, error) {
+	tctx.Facets = true
+	tctx.FacetCols = facetCols
+
+	sqlString := &strings.Builder{}
+
+	if err := tmpl.Execute(sqlString, tctx); err != nil {
+		return nil, err
+	}
+
+	facets := &types.SearchFacets{}
+
+	var buckets []int64
+
+	err := state.Pool.QueryRow(
+		ctx,
+		`WITH matches AS (`+sqlString.String()+"\n)"+`
+		SELECT
+			COALESCE((SELECT jsonb_object_agg(tag, n) FROM (
+				SELECT lower(tag) AS tag, COUNT(*) AS n FROM matches, unnest(matches.tags) AS tag GROUP BY 1 ORDER BY n DESC, 1 LIMIT $`+fmt.Sprint(len(args)+1)+`
+			) t), '{}'),
+			COALESCE((SELECT jsonb_object_agg(library, n) FROM (
+				SELECT library, COUNT(*) AS n FROM matches WHERE library IS NOT NULL GROUP BY 1 ORDER BY n DESC, 1 LIMIT $`+fmt.Sprint(len(args)+1)+`
+			) l), '{}'),
+			(SELECT COUNT(*) FILTER (WHERE nsfw) FROM matches),
+			(SELECT COUNT(*) FILTER (WHERE premium) FROM matches),
+			(SELECT COUNT(*) FILTER (WHERE certified) FROM matches),
+			(SELECT `+bucketCounts+` FROM matches)`,
+		append(args, facetLimit)...,
+	).Scan(&facets.Tags, &facets.Libraries, &facets.NSFW, &facets.Premium, &facets.Certified, &buckets)
+
+	if err != nil {
+		return nil, err
+	}
+
+	facets.Size = make([]types.SearchFacetBucket, len(sizeBuckets))
+
+	for i, b := range sizeBuckets {
+		facets.Size[i] = types.SearchFacetBucket{From: b[0], To: b[1], Count: buckets[i]}
+	}
+
+	return facets, nil
+}
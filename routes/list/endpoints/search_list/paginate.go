@@ -0,0 +1,211 @@
+package search_list
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"html"
+	"strings"
+	"text/template"
+
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/infinitybotlist/eureka/jsonimpl"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultLimit = 12
+
+	// ts_headline options for snippets. Matches are wrapped in STX/ETX rather
+	// than markup, since the text itself is not HTML safe; highlight swaps
+	// them for <mark> after escaping.
+	headlineOpts = "'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxFragments=2, MaxWords=20, MinWords=6'"
+
+	// Counting every match of a broad query would cost as much as the query
+	// itself on every page, so the count stops here
+	countCap = 1000
+)
+
+type sortKey struct {
+	Expr string // SQL expression sorted on, descending
+	Type string // The Postgres type cursor values are cast back to
+}
+
+// The *Sorts maps give each target type's expression per sort. relevance
+// refers to the lowercased query argument of that type's template ($8 for
+// bots, $7 for servers, $5 for teams, $3 for packs and blogs) and so can only
+// be used when there is a query.
+var botSorts = map[types.SearchSort]sortKey{
+	types.SearchSortVotes:     {Expr: "bots.approximate_votes", Type: "bigint"},
+	types.SearchSortServers:   {Expr: "bots.servers", Type: "bigint"},
+	types.SearchSortMembers:   {Expr: "bots.servers", Type: "bigint"},
+	types.SearchSortCreatedAt: {Expr: "bots.created_at", Type: "timestamptz"},
+	types.SearchSortRating: {
+		Expr: "COALESCE((SELECT AVG(stars) FROM reviews WHERE reviews.target_type = 'bot' AND reviews.target_id = bots.bot_id AND reviews.parent_id IS NULL AND reviews.owner_review = false), 0)",
+		Type: "numeric",
+	},
+	types.SearchSortRelevance: {
+		Expr: "(ts_rank_cd(bots.search_vector, websearch_to_tsquery('english', $8)) + similarity(bots.search_name, $8) + " +
+			"CASE WHEN lower(bots.search_name) = $8 OR bots.bot_id = $8 THEN 1 ELSE 0 END) * " + popularityBoost("bots"),
+		Type: "double precision",
+	},
+}
+
+var serverSorts = map[types.SearchSort]sortKey{
+	types.SearchSortVotes:     {Expr: "servers.approximate_votes", Type: "bigint"},
+	types.SearchSortServers:   {Expr: "servers.total_members", Type: "bigint"},
+	types.SearchSortMembers:   {Expr: "servers.total_members", Type: "bigint"},
+	types.SearchSortCreatedAt: {Expr: "servers.created_at", Type: "timestamptz"},
+	types.SearchSortRating: {
+		Expr: "COALESCE((SELECT AVG(stars) FROM reviews WHERE reviews.target_type = 'server' AND reviews.target_id = servers.server_id AND reviews.parent_id IS NULL AND reviews.owner_review = false), 0)",
+		Type: "numeric",
+	},
+	types.SearchSortRelevance: {
+		Expr: "(ts_rank_cd(servers.search_vector, websearch_to_tsquery('english', $7)) + similarity(servers.name, $7) + " +
+			"CASE WHEN lower(servers.name) = $7 OR servers.server_id = $7 THEN 1 ELSE 0 END) * " + popularityBoost("servers"),
+		Type: "double precision",
+	},
+}
+
+var packSorts = map[types.SearchSort]sortKey{
+	types.SearchSortVotes: {
+		Expr: "(SELECT COUNT(*) FILTER (WHERE upvote) - COUNT(*) FILTER (WHERE NOT upvote) FROM entity_votes WHERE entity_votes.target_type = 'pack' AND entity_votes.target_id = packs.url AND entity_votes.void = false)",
+		Type: "bigint",
+	},
+	types.SearchSortCreatedAt: {Expr: "packs.created_at", Type: "timestamptz"},
+	types.SearchSortRelevance: {Expr: "ts_rank(to_tsvector(packs.name || ' ' || packs.short), plainto_tsquery($3))", Type: "real"},
+}
+
+var teamSorts = map[types.SearchSort]sortKey{
+	types.SearchSortVotes:     {Expr: "teams.approximate_votes", Type: "bigint"},
+	types.SearchSortCreatedAt: {Expr: "teams.created_at", Type: "timestamptz"},
+	types.SearchSortRelevance: {Expr: "ts_rank(to_tsvector(teams.name || ' ' || COALESCE(teams.short, '')), plainto_tsquery($5))", Type: "real"},
+}
+
+var blogSorts = map[types.SearchSort]sortKey{
+	types.SearchSortCreatedAt: {Expr: "blogs.created_at", Type: "timestamptz"},
+	types.SearchSortRelevance: {Expr: "ts_rank(to_tsvector(blogs.title || ' ' || blogs.description), plainto_tsquery($3))", Type: "real"},
+}
+
+// popularityBoost scales text relevance by votes, logarithmically so that a
+// popular bot cannot bury an exact name match: 1000 votes is worth a 1.7x
+// boost, 100000 a 2.15x one.
+func popularityBoost(table string) string {
+	return "(1 + ln(1 + GREATEST(" + table + ".approximate_votes, 0)) / 10)"
+}
+
+// sortFor returns the expression for sort, or for fallback if sort does not
+// apply to this target type (e.g. servers for a team). Cursors record the
+// requested sort, so the same fallback is applied on every page.
+func sortFor(sorts map[types.SearchSort]sortKey, sort, fallback types.SearchSort) sortKey {
+	if key, ok := sorts[sort]; ok {
+		return key
+	}
+
+	return sorts[fallback]
+}
+
+var errBadCursor = errors.New("invalid cursor")
+
+// cursor is the position after the last row of a page. Value is the row's
+// sort key as Postgres printed it, so casting it back compares exactly.
+type cursor struct {
+	Sort  types.SearchSort `json:"s"`
+	Value string           `json:"v"`
+	ID    string           `json:"i"`
+}
+
+func (c cursor) encode() string {
+	bytes, _ := jsonimpl.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// decodeCursor decodes s, returning nil for the first page. A cursor from a
+// different sort is rejected, as its value would compare against the wrong
+// expression.
+func decodeCursor(s string, sort types.SearchSort) (*cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	bytes, err := base64.RawURLEncoding.DecodeString(s)
+
+	if err != nil {
+		return nil, errBadCursor
+	}
+
+	var c cursor
+
+	if err := jsonimpl.Unmarshal(bytes, &c); err != nil || c.Sort != sort || c.ID == "" {
+		return nil, errBadCursor
+	}
+
+	return &c, nil
+}
+
+// runSearch counts the matches of the search in tctx (up to countCap), then
+// queries the page after c. One row more than limit is asked for, so the
+// caller can tell whether there is a next page.
+func runSearch(ctx context.Context, tmpl *template.Template, tctx searchSqlTemplateCtx, args []any, c *cursor, limit int) (pgx.Rows, int64, string, error) {
+	tctx.Count = true
+	tctx.CountCap = countCap
+
+	sqlString := &strings.Builder{}
+
+	if err := tmpl.Execute(sqlString, tctx); err != nil {
+		return nil, 0, sqlString.String(), err
+	}
+
+	var total int64
+
+	if err := state.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM ("+sqlString.String()+") matches", args...).Scan(&total); err != nil {
+		return nil, 0, sqlString.String(), err
+	}
+
+	tctx.Count = false
+
+	if c != nil {
+		args = append(args, c.Value, c.ID)
+		tctx.CursorArg = len(args) - 1
+		tctx.CursorIDArg = len(args)
+	}
+
+	args = append(args, limit+1)
+	tctx.LimitArg = len(args)
+
+	sqlString.Reset()
+
+	if err := tmpl.Execute(sqlString, tctx); err != nil {
+		return nil, 0, sqlString.String(), err
+	}
+
+	rows, err := state.Pool.Query(ctx, sqlString.String(), args...)
+
+	return rows, total, sqlString.String(), err
+}
+
+// trimPage cuts the limit+1 rows fetched by runSearch down to limit, and
+// describes the page. key returns a row's sort key and ID.
+func trimPage[R any](rows []R, limit int, total int64, sort types.SearchSort, key func(R) (value, id string)) ([]R, *types.SearchPage) {
+	page := &types.SearchPage{EstimatedTotal: total}
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+		value, id := key(rows[len(rows)-1])
+		page.NextCursor = cursor{Sort: sort, Value: value, ID: id}.encode()
+	}
+
+	return rows, page
+}
+
+// highlight turns a ts_headline snippet into HTML with matches in <mark>. It
+// returns false if nothing in it matched.
+func highlight(snippet string) (string, bool) {
+	if !strings.ContainsRune(snippet, '\x02') {
+		return "", false
+	}
+
+	return strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>").Replace(html.EscapeString(snippet)), true
+}
@@ -1,10 +1,12 @@
 // Package search_list implements POST /list/search — "Search List".
 //
-// Searches the list returning a list of bots/servers that match the query
+// Searches the list returning a list of bots/servers/packs/teams/blogs that match the query
 package search_list
 
 import (
+	"context"
 	_ "embed"
+	"fmt"
 	"net/http"
 	"popplio/api/resp"
 	"strings"
@@ -12,9 +14,12 @@ import (
 
 	"popplio/db"
 	botAssets "popplio/routes/bots/assets"
+	packAssets "popplio/routes/packs/assets"
 	serverAssets "popplio/routes/servers/assets"
 	"popplio/state"
+	"popplio/taxonomy"
 	"popplio/types"
+	"popplio/votes"
 
 	docs "github.com/infinitybotlist/eureka/doclib"
 	"github.com/infinitybotlist/eureka/dovewing"
@@ -43,6 +48,15 @@ var (
 	indexServerColsArr = db.GetCols(types.IndexServer{})
 	indexServerCols    = strings.Join(indexServerColsArr, ",")
 
+	packColsArr = db.GetCols(types.BotPack{})
+	packCols    = strings.Join(packColsArr, ",")
+
+	teamColsArr = db.GetCols(types.Team{})
+	teamCols    = strings.Join(teamColsArr, ",")
+
+	blogColsArr = db.GetCols(types.BlogListPost{})
+	blogCols    = strings.Join(blogColsArr, ",")
+
 	compiledMessages = uapi.CompileValidationErrors(types.SearchQuery{})
 )
 
@@ -54,24 +68,80 @@ var (
 	//go:embed sql/servers.tmpl
 	serversSql        string
 	serverSqlTemplate *template.Template
+
+	//go:embed sql/packs.tmpl
+	packsSql        string
+	packSqlTemplate *template.Template
+
+	//go:embed sql/teams.tmpl
+	teamsSql        string
+	teamSqlTemplate *template.Template
+
+	//go:embed sql/blogs.tmpl
+	blogsSql        string
+	blogSqlTemplate *template.Template
 )
 
 type searchSqlTemplateCtx struct {
-	Query          string
-	TagMode        types.TagMode
-	Cols           string
-	PlatformTables []string
+	Query        string
+	TagMode      types.TagMode
+	Cols         string
+	SortExpr     string
+	SortType     string
+	IncludeNSFW  bool
+	HeadlineOpts string
+
+	// Set by runSearch
+	Count       bool
+	CountCap    int
+	CursorArg   int
+	CursorIDArg int
+	LimitArg    int
+
+	// Set by runFacets
+	Facets    bool
+	FacetCols string
+}
+
+type botRow struct {
+	types.IndexBot
+	SortKey string `db:"sort_key"`
+	Snippet string `db:"snippet"`
+}
+
+type serverRow struct {
+	types.IndexServer
+	SortKey string `db:"sort_key"`
+	Snippet string `db:"snippet"`
+}
+
+type packRow struct {
+	types.BotPack
+	SortKey string `db:"sort_key"`
+}
+
+type teamRow struct {
+	types.Team
+	SortKey string `db:"sort_key"`
+}
+
+type blogRow struct {
+	types.BlogListPost
+	SortKey string `db:"sort_key"`
 }
 
 func Setup() {
 	botSqlTemplate = template.Must(template.New("sqlA").Parse(botsSql))
 	serverSqlTemplate = template.Must(template.New("sqlB").Parse(serversSql))
+	packSqlTemplate = template.Must(template.New("sqlC").Parse(packsSql))
+	teamSqlTemplate = template.Must(template.New("sqlD").Parse(teamsSql))
+	blogSqlTemplate = template.Must(template.New("sqlE").Parse(blogsSql))
 }
 
 func Docs() *docs.Doc {
 	return &docs.Doc{
 		Summary:     "Search List",
-		Description: "Searches the list returning a list of bots/servers that match the query",
+		Description: "Searches the list returning a list of bots/servers/packs/teams/blogs that match the query.\n\nResults are paginated per target type: pass the `next_cursor` of `bots_page`/`servers_page` etc. in `cursors` along with the same query, filters and sort to get the next page.\n\nVote banned packs and teams and draft blog posts are never returned. NSFW teams, and packs containing NSFW bots, are only returned with `include_nsfw`.",
 		Req:         types.SearchQuery{},
 		Resp:        types.SearchResponse{},
 	}
@@ -93,6 +163,14 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return uapi.ValidatorErrorResponse(compiledMessages, errors)
 	}
 
+	// Entities are tagged with tag IDs, so filter by the IDs of whatever
+	// names or aliases were given
+	payload.TagFilter.Tags, err = taxonomy.Canonical(d.Context, payload.TagFilter.Tags)
+
+	if err != nil {
+		return resp.Err("Failed to load tag registry", err)
+	}
+
 	if payload.Query == "" && len(payload.TagFilter.Tags) == 0 {
 		// Return 206 because the user didn't specify a query or tags
 		//
@@ -105,10 +183,6 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		payload.TagFilter.TagMode = types.TagModeAny
 	}
 
-	if len(payload.TagFilter.Tags) == 0 {
-		payload.TagFilter.Tags = []string{}
-	}
-
 	if len(payload.TargetTypes) == 0 {
 		return resp.BadRequest("No target types specified")
 	}
@@ -117,25 +191,34 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.BadRequest("Invalid tag mode")
 	}
 
-	sr := types.SearchResponse{}
+	if payload.Sort == "" {
+		if payload.Query != "" {
+			payload.Sort = types.SearchSortRelevance
+		} else {
+			payload.Sort = types.SearchSortVotes
+		}
+	}
+
+	if payload.Sort == types.SearchSortRelevance && payload.Query == "" {
+		return resp.BadRequest("Sorting by relevance needs a query")
+	}
+
+	if payload.Limit == 0 {
+		payload.Limit = defaultLimit
+	}
+
+	sr := types.SearchResponse{
+		Snippets: map[string]string{},
+	}
 
 	for _, targetType := range payload.TargetTypes {
 		switch targetType {
 		case "bot":
 			sr.TargetTypes = append(sr.TargetTypes, "bot")
-			sqlString := &strings.Builder{}
-
-			err = botSqlTemplate.Execute(sqlString, searchSqlTemplateCtx{
-				Query:   payload.Query,
-				TagMode: payload.TagFilter.TagMode,
-				Cols:    indexBotColsWithPrefix, // We need to prefix the columns with bots. to avoid ambiguity
-				PlatformTables: []string{
-					dovewing.TableName(state.DovewingPlatformDiscord),
-				},
-			})
+			c, err := decodeCursor(payload.Cursors.Bot, payload.Sort)
 
 			if err != nil {
-				return resp.Err("Failed to execute template", err, zap.String("sql", sqlString.String()))
+				return resp.BadRequest("Invalid bot cursor, cursors only work with the query, filters and sort they came from")
 			}
 
 			args := []any{
@@ -152,23 +235,49 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 				args = append(args, strings.ToLower(payload.Query), "%"+strings.ToLower(payload.Query)+"%") // 8-9
 			}
 
-			state.Logger.Debug("SQL result", zap.String("sql", sqlString.String()), zap.String("targetType", "bot"))
+			sort := botSorts[payload.Sort]
+
+			tctx := searchSqlTemplateCtx{
+				Query:        payload.Query,
+				TagMode:      payload.TagFilter.TagMode,
+				Cols:         indexBotColsWithPrefix, // We need to prefix the columns with bots. to avoid ambiguity
+				SortExpr:     sort.Expr,
+				SortType:     sort.Type,
+				HeadlineOpts: headlineOpts,
+			}
+
+			if payload.Facets && c == nil {
+				sr.BotFacets, err = runFacets(d.Context, botSqlTemplate, tctx, botFacetCols, args)
+
+				if err != nil {
+					return resp.Err("Failed to compute facets", err, zap.String("targetType", "bot"))
+				}
+			}
+
+			rows, total, sqlString, err := runSearch(d.Context, botSqlTemplate, tctx, args, c, payload.Limit)
 
-			rows, err := state.Pool.Query(
-				d.Context,
-				sqlString.String(),
-				// Args
-				args...,
-			)
+			state.Logger.Debug("SQL result", zap.String("sql", sqlString), zap.String("targetType", "bot"))
 
 			if err != nil {
 				return resp.ErrBody("Failed to query", "Error querying.", err, zap.String("targetType", "bot"))
 			}
 
-			bots, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.IndexBot])
+			botRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[botRow])
 
 			if err != nil {
-				return resp.ErrBody("Failed to collect rows [bots]", "Error collecting rows.", err, zap.String("sql", sqlString.String()))
+				return resp.ErrBody("Failed to collect rows [bots]", "Error collecting rows.", err, zap.String("sql", sqlString))
+			}
+
+			botRows, page := trimPage(botRows, payload.Limit, total, payload.Sort, func(r botRow) (string, string) { return r.SortKey, r.BotID })
+
+			bots := make([]types.IndexBot, len(botRows))
+
+			for i := range botRows {
+				bots[i] = botRows[i].IndexBot
+
+				if snippet, ok := highlight(botRows[i].Snippet); ok {
+					sr.Snippets[bots[i].BotID] = snippet
+				}
 			}
 
 			if err := botAssets.ResolveIndexBots(d.Context, bots); err != nil {
@@ -176,19 +285,14 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 			}
 
 			sr.Bots = bots
+			sr.BotsPage = page
 		case "server":
 			sr.TargetTypes = append(sr.TargetTypes, "server")
 
-			sqlString := &strings.Builder{}
-
-			err = serverSqlTemplate.Execute(sqlString, searchSqlTemplateCtx{
-				Query:   payload.Query,
-				TagMode: payload.TagFilter.TagMode,
-				Cols:    indexServerCols,
-			})
+			c, err := decodeCursor(payload.Cursors.Server, payload.Sort)
 
 			if err != nil {
-				return resp.Err("Failed to execute template", err, zap.String("sql", sqlString.String()))
+				return resp.BadRequest("Invalid server cursor, cursors only work with the query, filters and sort they came from")
 			}
 
 			args := []any{
@@ -203,23 +307,49 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 				args = append(args, "%"+strings.ToLower(payload.Query)+"%", strings.ToLower(payload.Query)) // 6-7
 			}
 
-			state.Logger.Debug("SQL result", zap.String("sql", sqlString.String()), zap.String("targetType", "server"))
+			sort := serverSorts[payload.Sort]
+
+			tctx := searchSqlTemplateCtx{
+				Query:        payload.Query,
+				TagMode:      payload.TagFilter.TagMode,
+				Cols:         indexServerCols,
+				SortExpr:     sort.Expr,
+				SortType:     sort.Type,
+				HeadlineOpts: headlineOpts,
+			}
+
+			if payload.Facets && c == nil {
+				sr.ServerFacets, err = runFacets(d.Context, serverSqlTemplate, tctx, serverFacetCols, args)
+
+				if err != nil {
+					return resp.Err("Failed to compute facets", err, zap.String("targetType", "server"))
+				}
+			}
 
-			rows, err := state.Pool.Query(
-				d.Context,
-				sqlString.String(),
-				// Args
-				args...,
-			)
+			rows, total, sqlString, err := runSearch(d.Context, serverSqlTemplate, tctx, args, c, payload.Limit)
+
+			state.Logger.Debug("SQL result", zap.String("sql", sqlString), zap.String("targetType", "server"))
 
 			if err != nil {
 				return resp.Err("Failed to query", err, zap.String("targetType", "server"))
 			}
 
-			servers, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.IndexServer])
+			serverRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[serverRow])
 
 			if err != nil {
-				return resp.Err("Failed to collect rows", err, zap.String("sql", sqlString.String()))
+				return resp.Err("Failed to collect rows", err, zap.String("sql", sqlString))
+			}
+
+			serverRows, page := trimPage(serverRows, payload.Limit, total, payload.Sort, func(r serverRow) (string, string) { return r.SortKey, r.ServerID })
+
+			servers := make([]types.IndexServer, len(serverRows))
+
+			for i := range serverRows {
+				servers[i] = serverRows[i].IndexServer
+
+				if snippet, ok := highlight(serverRows[i].Snippet); ok {
+					sr.Snippets[servers[i].ServerID] = snippet
+				}
 			}
 
 			if err := serverAssets.ResolveIndexServers(d.Context, servers); err != nil {
@@ -227,6 +357,174 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 			}
 
 			sr.Servers = servers
+			sr.ServersPage = page
+		case "pack":
+			sr.TargetTypes = append(sr.TargetTypes, "pack")
+
+			c, err := decodeCursor(payload.Cursors.Pack, payload.Sort)
+
+			if err != nil {
+				return resp.BadRequest("Invalid pack cursor, cursors only work with the query, filters and sort they came from")
+			}
+
+			args := []any{
+				payload.TagFilter.Tags, // 1
+			}
+
+			if payload.Query != "" {
+				args = append(args, "%"+strings.ToLower(payload.Query)+"%", strings.ToLower(payload.Query)) // 2-3
+			}
+
+			sort := sortFor(packSorts, payload.Sort, types.SearchSortVotes)
+
+			rows, total, sqlString, err := runSearch(d.Context, packSqlTemplate, searchSqlTemplateCtx{
+				Query:       payload.Query,
+				TagMode:     payload.TagFilter.TagMode,
+				Cols:        packCols,
+				SortExpr:    sort.Expr,
+				SortType:    sort.Type,
+				IncludeNSFW: payload.IncludeNSFW,
+			}, args, c, payload.Limit)
+
+			state.Logger.Debug("SQL result", zap.String("sql", sqlString), zap.String("targetType", "pack"))
+
+			if err != nil {
+				return resp.Err("Failed to query", err, zap.String("targetType", "pack"))
+			}
+
+			packRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[packRow])
+
+			if err != nil {
+				return resp.Err("Failed to collect rows [packs]", err, zap.String("sql", sqlString))
+			}
+
+			packRows, page := trimPage(packRows, payload.Limit, total, payload.Sort, func(r packRow) (string, string) { return r.SortKey, r.URL })
+
+			packs := make([]types.BotPack, len(packRows))
+
+			for i := range packRows {
+				packs[i] = packRows[i].BotPack
+
+				if err := packAssets.ResolveBotPack(d.Context, &packs[i]); err != nil {
+					return resp.ErrBody("Error resolving pack", "Error resolving pack.", err, zap.String("url", packs[i].URL))
+				}
+			}
+
+			sr.Packs = packs
+			sr.PacksPage = page
+		case "team":
+			sr.TargetTypes = append(sr.TargetTypes, "team")
+
+			c, err := decodeCursor(payload.Cursors.Team, payload.Sort)
+
+			if err != nil {
+				return resp.BadRequest("Invalid team cursor, cursors only work with the query, filters and sort they came from")
+			}
+
+			args := []any{
+				payload.Votes.From,     // 1
+				payload.Votes.To,       // 2
+				payload.TagFilter.Tags, // 3
+			}
+
+			if payload.Query != "" {
+				args = append(args, "%"+strings.ToLower(payload.Query)+"%", strings.ToLower(payload.Query)) // 4-5
+			}
+
+			sort := sortFor(teamSorts, payload.Sort, types.SearchSortVotes)
+
+			rows, total, sqlString, err := runSearch(d.Context, teamSqlTemplate, searchSqlTemplateCtx{
+				Query:       payload.Query,
+				TagMode:     payload.TagFilter.TagMode,
+				Cols:        teamCols,
+				SortExpr:    sort.Expr,
+				SortType:    sort.Type,
+				IncludeNSFW: payload.IncludeNSFW,
+			}, args, c, payload.Limit)
+
+			state.Logger.Debug("SQL result", zap.String("sql", sqlString), zap.String("targetType", "team"))
+
+			if err != nil {
+				return resp.Err("Failed to query", err, zap.String("targetType", "team"))
+			}
+
+			teamRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[teamRow])
+
+			if err != nil {
+				return resp.Err("Failed to collect rows [teams]", err, zap.String("sql", sqlString))
+			}
+
+			teamRows, page := trimPage(teamRows, payload.Limit, total, payload.Sort, func(r teamRow) (string, string) { return r.SortKey, r.ID })
+
+			teams := make([]types.Team, len(teamRows))
+
+			for i := range teamRows {
+				teams[i] = teamRows[i].Team
+
+				if err := resolveTeam(d.Context, &teams[i]); err != nil {
+					return resp.ErrBody("Error resolving team", "Error resolving team.", err, zap.String("id", teams[i].ID))
+				}
+			}
+
+			sr.Teams = teams
+			sr.TeamsPage = page
+		case "blog":
+			sr.TargetTypes = append(sr.TargetTypes, "blog")
+
+			c, err := decodeCursor(payload.Cursors.Blog, payload.Sort)
+
+			if err != nil {
+				return resp.BadRequest("Invalid blog cursor, cursors only work with the query, filters and sort they came from")
+			}
+
+			args := []any{
+				payload.TagFilter.Tags, // 1
+			}
+
+			if payload.Query != "" {
+				args = append(args, "%"+strings.ToLower(payload.Query)+"%", strings.ToLower(payload.Query)) // 2-3
+			}
+
+			sort := sortFor(blogSorts, payload.Sort, types.SearchSortCreatedAt)
+
+			rows, total, sqlString, err := runSearch(d.Context, blogSqlTemplate, searchSqlTemplateCtx{
+				Query:    payload.Query,
+				TagMode:  payload.TagFilter.TagMode,
+				Cols:     blogCols,
+				SortExpr: sort.Expr,
+				SortType: sort.Type,
+			}, args, c, payload.Limit)
+
+			state.Logger.Debug("SQL result", zap.String("sql", sqlString), zap.String("targetType", "blog"))
+
+			if err != nil {
+				return resp.Err("Failed to query", err, zap.String("targetType", "blog"))
+			}
+
+			blogRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[blogRow])
+
+			if err != nil {
+				return resp.Err("Failed to collect rows [blogs]", err, zap.String("sql", sqlString))
+			}
+
+			blogRows, page := trimPage(blogRows, payload.Limit, total, payload.Sort, func(r blogRow) (string, string) { return r.SortKey, r.Slug })
+
+			blogs := make([]types.BlogListPost, len(blogRows))
+
+			for i := range blogRows {
+				blogs[i] = blogRows[i].BlogListPost
+
+				blogs[i].Author, err = dovewing.GetUser(d.Context, blogs[i].UserID, state.DovewingPlatformDiscord)
+
+				if err != nil {
+					return resp.Err("Error while getting user [dovewing]", err, zap.String("user_id", blogs[i].UserID))
+				}
+			}
+
+			sr.Blogs = blogs
+			sr.BlogsPage = page
+		default:
+			return resp.BadRequest("Invalid target type: " + targetType)
 		}
 	}
 
@@ -238,3 +536,20 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		Json: sr,
 	}
 }
+
+// resolveTeam fills in what get_team would, short of the team's entities
+func resolveTeam(ctx context.Context, team *types.Team) error {
+	err := state.Pool.QueryRow(ctx, "SELECT code FROM vanity WHERE itag = $1", team.VanityRef).Scan(&team.Vanity)
+
+	if err != nil {
+		return fmt.Errorf("error querying vanity table: %w", err)
+	}
+
+	team.Votes, err = votes.EntityGetVoteCount(ctx, state.Pool, team.ID, "team")
+
+	if err != nil {
+		return fmt.Errorf("error getting vote count: %w", err)
+	}
+
+	return nil
+}
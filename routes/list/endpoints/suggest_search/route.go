@@ -0,0 +1,221 @@
+// Package suggest_search implements GET /list/search/suggest — "Suggest Search".
+//
+// Returns typeahead suggestions for the list search box
+package suggest_search
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"popplio/api/resp"
+	"popplio/state"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/jsonimpl"
+	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// The search box fires this on every keystroke, so a slow section is
+	// dropped rather than holding up the others
+	latencyBudget = 150 * time.Millisecond
+
+	perSection = 5
+
+	// Below this a query matches too much to be a useful suggestion
+	minQueryLength = 2
+
+	tagsCacheKey = "search_suggest:tags"
+
+	// Tags only change when an entity is added, edited or removed, and a new
+	// tag taking a few minutes to be suggested is harmless
+	tagsCacheExpiry = 5 * time.Minute
+)
+
+var (
+	likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+	// Only one process-wide tag rebuild at a time
+	refreshingTags atomic.Bool
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Suggest Search",
+		Description: "Returns up to 5 bots, servers, teams and tags matching the start of `q`, falling back to fuzzy matches for typos. Meant for typeahead in the search box, use `POST /list/search` for actual results.\n\nAnswers within a strict latency budget: a section that cannot be looked up in time is returned empty rather than delaying the rest. Queries shorter than 2 characters return nothing. The tag list is rebuilt at most every 5 minutes.",
+		Resp:        types.SearchSuggestions{},
+		Params: []docs.Parameter{
+			{
+				Name:        "q",
+				Description: "What has been typed so far",
+				Required:    true,
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+		},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	q := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q")))
+
+	sugg := types.SearchSuggestions{
+		Bots:    []types.SearchSuggestion{},
+		Servers: []types.SearchSuggestion{},
+		Teams:   []types.SearchSuggestion{},
+		Tags:    []string{},
+	}
+
+	if len([]rune(q)) < minQueryLength {
+		return uapi.HttpResponse{
+			Json: sugg,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(d.Context, latencyBudget)
+	defer cancel()
+
+	prefix := likeEscaper.Replace(q) + "%"
+
+	var g errgroup.Group
+
+	g.Go(func() error {
+		return suggest(ctx, &sugg.Bots, "bots", `SELECT bot_id, search_name FROM bots
+			WHERE (type = 'approved' OR type = 'certified') AND (search_name ILIKE $1 OR search_name % $2)
+			ORDER BY search_name ILIKE $1 DESC, similarity(search_name, $2) DESC, approximate_votes DESC LIMIT $3`, prefix, q)
+	})
+
+	g.Go(func() error {
+		return suggest(ctx, &sugg.Servers, "servers", `SELECT server_id, name FROM servers
+			WHERE (type = 'approved' OR type = 'certified') AND state = 'public' AND (name ILIKE $1 OR name % $2)
+			ORDER BY name ILIKE $1 DESC, similarity(name, $2) DESC, approximate_votes DESC LIMIT $3`, prefix, q)
+	})
+
+	g.Go(func() error {
+		return suggest(ctx, &sugg.Teams, "teams", `SELECT id::text, name FROM teams
+			WHERE vote_banned = false AND nsfw = false AND (name ILIKE $1 OR name % $2)
+			ORDER BY name ILIKE $1 DESC, similarity(name, $2) DESC, approximate_votes DESC LIMIT $3`, prefix, q)
+	})
+
+	g.Go(func() error {
+		tags, err := getTags(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		for _, tag := range tags {
+			if strings.HasPrefix(tag, q) {
+				sugg.Tags = append(sugg.Tags, tag)
+
+				if len(sugg.Tags) == perSection {
+					break
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return resp.Err("Error getting search suggestions", err, zap.String("q", q))
+	}
+
+	return uapi.HttpResponse{
+		Json: sugg,
+		Headers: map[string]string{
+			"Cache-Control": "public, max-age=60",
+		},
+	}
+}
+
+// suggest runs query into into, leaving it empty if the latency budget runs
+// out first
+func suggest(ctx context.Context, into *[]types.SearchSuggestion, section, query string, prefix, q string) error {
+	rows, err := state.Pool.Query(ctx, query, prefix, q, perSection)
+
+	if err == nil {
+		*into, err = pgx.CollectRows(rows, pgx.RowToStructByPos[types.SearchSuggestion])
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+		state.Logger.Debug("Search suggestions over latency budget", zap.String("section", section))
+		*into = []types.SearchSuggestion{}
+		return nil
+	}
+
+	return err
+}
+
+// getTags returns every tag in use by a listed bot or server, sorted.
+//
+// Building the list scans every listed entity, which does not fit the latency
+// budget, so a cache miss starts a rebuild in the background and suggests no
+// tags until it lands.
+func getTags(ctx context.Context) ([]string, error) {
+	var tags []string
+
+	cached, err := state.Redis.Get(ctx, tagsCacheKey).Bytes()
+
+	if err == nil && jsonimpl.Unmarshal(cached, &tags) == nil {
+		return tags, nil
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+		return []string{}, nil
+	}
+
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	if refreshingTags.CompareAndSwap(false, true) {
+		go func() {
+			defer refreshingTags.Store(false)
+
+			if err := refreshTags(state.Context); err != nil {
+				state.Logger.Error("Error refreshing search suggestion tags", zap.Error(err))
+			}
+		}()
+	}
+
+	return []string{}, nil
+}
+
+func refreshTags(ctx context.Context) error {
+	rows, err := state.Pool.Query(ctx, `SELECT DISTINCT lower(tag) FROM (
+		SELECT unnest(tags) AS tag FROM bots WHERE type = 'approved' OR type = 'certified'
+		UNION ALL
+		SELECT unnest(tags) AS tag FROM servers WHERE (type = 'approved' OR type = 'certified') AND state = 'public'
+	) t`)
+
+	if err != nil {
+		return err
+	}
+
+	tags, err := pgx.CollectRows(rows, pgx.RowTo[string])
+
+	if err != nil {
+		return err
+	}
+
+	slices.Sort(tags)
+
+	bytes, err := jsonimpl.Marshal(tags)
+
+	if err != nil {
+		return err
+	}
+
+	return state.Redis.Set(ctx, tagsCacheKey, bytes, tagsCacheExpiry).Err()
+}
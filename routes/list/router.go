@@ -6,12 +6,15 @@ package list
 import (
 	"popplio/routes/list/endpoints/current_status"
 	"popplio/routes/list/endpoints/get_list_stats"
+	"popplio/routes/list/endpoints/get_list_tags"
 	"popplio/routes/list/endpoints/get_list_team"
 	"popplio/routes/list/endpoints/get_partners"
 	"popplio/routes/list/endpoints/get_rss_feed"
 	"popplio/routes/list/endpoints/get_sitemap"
 	"popplio/routes/list/endpoints/get_staff_templates"
+	"popplio/routes/list/endpoints/get_tag_registry"
 	"popplio/routes/list/endpoints/search_list"
+	"popplio/routes/list/endpoints/suggest_search"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/infinitybotlist/eureka/uapi"
@@ -51,6 +54,14 @@ func (b Router) Routes(r *chi.Mux) {
 		Setup:   search_list.Setup,
 	}.Route(r)
 
+	uapi.Route{
+		Pattern: "/list/search/suggest",
+		OpId:    "suggest_search",
+		Method:  uapi.GET,
+		Docs:    suggest_search.Docs,
+		Handler: suggest_search.Route,
+	}.Route(r)
+
 	uapi.Route{
 		Pattern: "/list/stats",
 		OpId:    "get_list_stats",
@@ -59,6 +70,22 @@ func (b Router) Routes(r *chi.Mux) {
 		Handler: get_list_stats.Route,
 	}.Route(r)
 
+	uapi.Route{
+		Pattern: "/list/tags",
+		OpId:    "get_list_tags",
+		Method:  uapi.GET,
+		Docs:    get_list_tags.Docs,
+		Handler: get_list_tags.Route,
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/list/tags/registry",
+		OpId:    "get_tag_registry",
+		Method:  uapi.GET,
+		Docs:    get_tag_registry.Docs,
+		Handler: get_tag_registry.Route,
+	}.Route(r)
+
 	uapi.Route{
 		Pattern: "/list/partners",
 		OpId:    "get_partners",
@@ -0,0 +1,66 @@
+// Package get_user_discord_notifications implements GET
+// /users/{id}/notifications/discord — "Get User Discord Notifications".
+//
+// Gets whether the user receives alerts as Discord DMs.
+package get_user_discord_notifications
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/db"
+	"popplio/state"
+	"popplio/types"
+	"strings"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var (
+	dmSettingsCols    = db.GetCols(types.DiscordDMSettings{})
+	dmSettingsColsStr = strings.Join(dmSettingsCols, ",")
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get User Discord Notifications",
+		Description: "Gets whether the user receives alerts as Discord DMs. Users who never opted in get `enabled: false`.",
+		Params: []docs.Parameter{
+			{
+				Name:        "id",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.DiscordDMSettings{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	rows, err := state.Pool.Query(d.Context, "SELECT "+dmSettingsColsStr+" FROM user_notification_dms WHERE user_id = $1", d.Auth.ID)
+
+	if err != nil {
+		return resp.Err("Failed to get discord dm settings [db fetch]", err, zap.String("userID", d.Auth.ID))
+	}
+
+	settings, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[types.DiscordDMSettings])
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uapi.HttpResponse{
+			Json: types.DiscordDMSettings{},
+		}
+	}
+
+	if err != nil {
+		return resp.Err("Failed to get discord dm settings [collect]", err, zap.String("userID", d.Auth.ID))
+	}
+
+	return uapi.HttpResponse{
+		Json: settings,
+	}
+}
@@ -0,0 +1,119 @@
+// Package patch_user_discord_notifications implements PATCH
+// /users/{id}/notifications/discord — "Patch User Discord Notifications".
+//
+// Turns Discord DM notifications on or off. Returns 204 on success
+package patch_user_discord_notifications
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/notifications"
+	"popplio/state"
+	"popplio/types"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/ratelimit"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Patch User Discord Notifications",
+		Description: "Turns Discord DM notifications on or off. Enabling sends a confirmation DM first, and fails with a 400 if Popplio cannot DM the user (e.g. they do not allow DMs from server members). Enabling when already enabled does nothing, and enabling is rate limited as each attempt sends a DM. Returns 204 on success",
+		Params: []docs.Parameter{
+			{
+				Name:        "id",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Req:  types.PatchDiscordDMSettings{},
+		Resp: types.ApiError{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var payload types.PatchDiscordDMSettings
+
+	hresp, ok := uapi.MarshalReq(r, &payload)
+
+	if !ok {
+		return hresp
+	}
+
+	if !payload.Enabled {
+		_, err := state.Pool.Exec(d.Context, "UPDATE user_notification_dms SET enabled = false, disabled_reason = NULL WHERE user_id = $1", d.Auth.ID)
+
+		if err != nil {
+			return resp.Err("Failed to disable discord dms", err, zap.String("userID", d.Auth.ID))
+		}
+
+		return uapi.DefaultResponse(http.StatusNoContent)
+	}
+
+	var enabled bool
+
+	err := state.Pool.QueryRow(d.Context, "SELECT EXISTS (SELECT 1 FROM user_notification_dms WHERE user_id = $1 AND enabled = true)", d.Auth.ID).Scan(&enabled)
+
+	if err != nil {
+		return resp.Err("Failed to check discord dm settings", err, zap.String("userID", d.Auth.ID))
+	}
+
+	// Already on, so there is nothing to confirm
+	if enabled {
+		return uapi.DefaultResponse(http.StatusNoContent)
+	}
+
+	// Every attempt DMs the user, so keep clients from using this to spam them
+	limit, err := ratelimit.Ratelimit{
+		Expiry:      1 * time.Hour,
+		MaxRequests: 3,
+		Bucket:      "discord_dm_optin",
+	}.Limit(d.Context, r)
+
+	if err != nil {
+		return resp.Err("Error while ratelimiting", err, zap.String("bucket", "discord_dm_optin"))
+	}
+
+	if limit.Exceeded {
+		return resp.RateLimited(limit)
+	}
+
+	// Opting in is checked up front, otherwise a user with closed DMs would
+	// only find out when the first real alert bounced and turned it off again
+	err = notifications.SendDM(d.Auth.ID, discord.MessageCreate{
+		Embeds: []discord.Embed{
+			notifications.AlertEmbed(types.Alert{
+				Type:    types.AlertTypeSuccess,
+				Title:   "Discord DM notifications enabled",
+				Message: "This is an automated message to let you know that your alerts will now also be sent to you here. Alerts arriving close together are grouped into one message.",
+			}),
+		},
+	})
+
+	if errors.Is(err, notifications.ErrDMsClosed) {
+		return resp.BadRequest("Popplio could not DM you. Make sure you share a server with our bot and allow DMs from its members, then try again")
+	}
+
+	if err != nil {
+		return resp.Err("Failed to send discord dm confirmation", err, zap.String("userID", d.Auth.ID))
+	}
+
+	_, err = state.Pool.Exec(
+		d.Context,
+		"INSERT INTO user_notification_dms (user_id, enabled) VALUES ($1, true) ON CONFLICT (user_id) DO UPDATE SET enabled = true, disabled_reason = NULL",
+		d.Auth.ID,
+	)
+
+	if err != nil {
+		return resp.Err("Failed to enable discord dms", err, zap.String("userID", d.Auth.ID))
+	}
+
+	return uapi.DefaultResponse(http.StatusNoContent)
+}
@@ -8,7 +8,9 @@ import (
 	"popplio/routes/notifications/endpoints/create_user_notifications"
 	"popplio/routes/notifications/endpoints/delete_user_notifications"
 	"popplio/routes/notifications/endpoints/get_notification_info"
+	"popplio/routes/notifications/endpoints/get_user_discord_notifications"
 	"popplio/routes/notifications/endpoints/get_user_notifications"
+	"popplio/routes/notifications/endpoints/patch_user_discord_notifications"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/infinitybotlist/eureka/uapi"
@@ -81,4 +83,38 @@ func (b Router) Routes(r *chi.Mux) {
 			api.PERMISSION_CHECK_KEY: nil, // No authorization is needed for this endpoint beyond defaults
 		},
 	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{id}/notifications/discord",
+		OpId:    "get_user_discord_notifications",
+		Method:  uapi.GET,
+		Docs:    get_user_discord_notifications.Docs,
+		Handler: get_user_discord_notifications.Route,
+		Auth: []uapi.AuthType{
+			{
+				URLVar: "id",
+				Type:   api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // No authorization is needed for this endpoint beyond defaults
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{id}/notifications/discord",
+		OpId:    "patch_user_discord_notifications",
+		Method:  uapi.PATCH,
+		Docs:    patch_user_discord_notifications.Docs,
+		Handler: patch_user_discord_notifications.Route,
+		Auth: []uapi.AuthType{
+			{
+				URLVar: "id",
+				Type:   api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // No authorization is needed for this endpoint beyond defaults
+		},
+	}.Route(r)
 }
@@ -0,0 +1,160 @@
+package assets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	payassets "popplio/routes/payments/assets"
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/infinitybotlist/eureka/dovewing"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// MaxCollaborators is how many collaborators a pack can have
+	MaxCollaborators = 10
+
+	// ItemLimit is how many bots, and separately how many servers, a pack can
+	// have
+	ItemLimit = 10
+
+	// PremiumItemLimit is the ItemLimit of packs owned by premium users
+	PremiumItemLimit = 50
+)
+
+// ErrPackNotFound is returned when a pack doesn't exist
+var ErrPackNotFound = errors.New("pack not found")
+
+// Access returns the owner of a pack, and whether userID can edit it as its
+// owner or one of its collaborators.
+func Access(ctx context.Context, url, userID string) (owner string, canEdit bool, err error) {
+	err = state.Pool.QueryRow(
+		ctx,
+		"SELECT owner, owner = $2 OR EXISTS(SELECT 1 FROM pack_collaborators WHERE pack_url = $1 AND user_id = $2) FROM packs WHERE url = $1",
+		url,
+		userID,
+	).Scan(&owner, &canEdit)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, ErrPackNotFound
+	}
+
+	if err != nil {
+		return "", false, err
+	}
+
+	return owner, canEdit, nil
+}
+
+// ItemLimitFor returns how many bots, and separately how many servers, a pack
+// owned by ownerID can have. Premium users, being boosters of the main
+// server, get PremiumItemLimit.
+func ItemLimitFor(ownerID string) int {
+	id, err := snowflake.Parse(ownerID)
+
+	if err != nil {
+		return ItemLimit
+	}
+
+	if payassets.CheckUserBoosterStatus(id).IsBooster {
+		return PremiumItemLimit
+	}
+
+	return ItemLimit
+}
+
+// CheckItemLimit returns an error describing the problem if a pack owned by
+// ownerID can't have this many bots and servers
+func CheckItemLimit(ownerID string, bots, servers []string) error {
+	limit := ItemLimitFor(ownerID)
+
+	for _, c := range []struct {
+		name string
+		n    int
+	}{
+		{"bots", len(bots)},
+		{"servers", len(servers)},
+	} {
+		if c.n <= limit {
+			continue
+		}
+
+		if limit < PremiumItemLimit {
+			return fmt.Errorf("this pack can have at most %d %s, or %d if its owner is premium", limit, c.name, PremiumItemLimit)
+		}
+
+		return fmt.Errorf("this pack can have at most %d %s", limit, c.name)
+	}
+
+	return nil
+}
+
+// HasItem returns whether a bot or server is in a pack
+func HasItem(pack *types.BotPack, targetType, targetID string) bool {
+	switch targetType {
+	case "bot":
+		return slices.Contains(pack.Bots, targetID)
+	case "server":
+		return slices.Contains(pack.Servers, targetID)
+	default:
+		return false
+	}
+}
+
+// PruneNotes removes the notes on items no longer in a pack
+func PruneNotes(ctx context.Context, url string, bots, servers []string) error {
+	_, err := state.Pool.Exec(
+		ctx,
+		`DELETE FROM pack_item_notes WHERE pack_url = $1 AND (
+			(target_type = 'bot' AND NOT target_id = ANY($2))
+			OR (target_type = 'server' AND NOT target_id = ANY($3))
+		)`,
+		url,
+		bots,
+		servers,
+	)
+
+	return err
+}
+
+// resolveCollaboration fills in a pack's collaborators and notes
+func resolveCollaboration(ctx context.Context, pack *types.BotPack) error {
+	rows, err := state.Pool.Query(ctx, "SELECT user_id, added_by, created_at FROM pack_collaborators WHERE pack_url = $1 ORDER BY created_at", pack.URL)
+
+	if err != nil {
+		return fmt.Errorf("error querying pack collaborators: %w", err)
+	}
+
+	pack.Collaborators, err = pgx.CollectRows(rows, pgx.RowToStructByName[types.PackCollaborator])
+
+	if err != nil {
+		return fmt.Errorf("error querying pack collaborators: %w", err)
+	}
+
+	for i := range pack.Collaborators {
+		pack.Collaborators[i].User, err = dovewing.GetUser(ctx, pack.Collaborators[i].UserID, state.DovewingPlatformDiscord)
+
+		if err != nil {
+			return fmt.Errorf("error querying dovewing for collaborator: %w", err)
+		}
+	}
+
+	rows, err = state.Pool.Query(ctx, "SELECT target_type, target_id, note, updated_by, updated_at FROM pack_item_notes WHERE pack_url = $1", pack.URL)
+
+	if err != nil {
+		return fmt.Errorf("error querying pack item notes: %w", err)
+	}
+
+	pack.Notes, err = pgx.CollectRows(rows, pgx.RowToStructByName[types.PackItemNote])
+
+	if err != nil {
+		return fmt.Errorf("error querying pack item notes: %w", err)
+	}
+
+	return nil
+}
@@ -104,5 +104,11 @@ func ResolveBotPack(ctx context.Context, pack *types.BotPack) error {
 		return fmt.Errorf("error getting vote count: %w", err)
 	}
 
+	err = resolveCollaboration(ctx, pack)
+
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -6,7 +6,10 @@ package add_pack
 import (
 	"net/http"
 	"popplio/api/resp"
+	"popplio/audit"
+	packassets "popplio/routes/packs/assets"
 	"popplio/state"
+	"popplio/taxonomy"
 	"popplio/types"
 	"popplio/validators"
 	"slices"
@@ -28,14 +31,14 @@ type CreatePack struct {
 	URL     string   `json:"url" validate:"required,min=3,max=20,nospaces,notblank,alpha" msg:"URL must be between 3 and 20 characters without spaces and must be alphabetic"`
 	Short   string   `json:"short" validate:"required,min=10,max=100" msg:"Description must be between 10 and 100 characters"`
 	Tags    []string `json:"tags" validate:"required,unique,min=1,max=5,dive,min=3,max=30,notblank,nonvulgar" msg:"There must be between 1 and 5 tags without duplicates" amsg:"Each tag must be between 3 and 30 characters and alphabetic"`
-	Bots    []string `json:"bots" validate:"omitempty,unique,max=10,dive,numeric" msg:"There can be at most 10 bots without duplicates"`
-	Servers []string `json:"servers" validate:"omitempty,unique,max=10,dive,numeric" msg:"There can be at most 10 servers without duplicates"`
+	Bots    []string `json:"bots" validate:"omitempty,unique,max=50,dive,numeric" msg:"There can be at most 50 bots without duplicates"`
+	Servers []string `json:"servers" validate:"omitempty,unique,max=50,dive,numeric" msg:"There can be at most 50 servers without duplicates"`
 }
 
 func Docs() *docs.Doc {
 	return &docs.Doc{
 		Summary:     "Create Pack",
-		Description: "Creates a pack. Returns 204 on success",
+		Description: "Creates a pack. Packs can have up to 10 bots and 10 servers, or 50 of each for premium users. Returns 204 on success",
 		Req:         CreatePack{},
 		Resp:        types.ApiError{},
 		Params: []docs.Parameter{
@@ -71,6 +74,10 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.BadRequest("A pack must contain at least one bot or server")
 	}
 
+	if err := packassets.CheckItemLimit(d.Auth.ID, payload.Bots, payload.Servers); err != nil {
+		return resp.BadRequest(err.Error())
+	}
+
 	// Both columns are NOT NULL — a nil Go slice encodes as SQL NULL, so
 	// normalize an omitted field to an empty slice before it ever reaches a query.
 	if payload.Bots == nil {
@@ -99,6 +106,18 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.BadRequest("The chosen pack url is blacklisted")
 	}
 
+	tags, tagErr, err := taxonomy.Normalize(d.Context, "pack", payload.Tags, nil)
+
+	if err != nil {
+		return resp.Err("Failed to normalize tags", err)
+	}
+
+	if tagErr != nil {
+		return resp.BadRequest(tagErr.Error())
+	}
+
+	payload.Tags = tags
+
 	// Check that all bots exist
 	for _, bot := range payload.Bots {
 		botUser, err := dovewing.GetUser(d.Context, bot, state.DovewingPlatformDiscord)
@@ -158,5 +177,7 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.BadRequest(err.Error())
 	}
 
+	audit.Created(r, "add_pack", d.Auth, "pack", payload.URL)
+
 	return uapi.DefaultResponse(http.StatusNoContent)
 }
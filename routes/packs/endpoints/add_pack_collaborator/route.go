@@ -0,0 +1,132 @@
+// Package add_pack_collaborator implements PUT
+// /users/{uid}/packs/{id}/collaborators/{cid} — "Add Pack Collaborator".
+//
+// Lets another user edit, reorder and annotate a pack you own. Returns 204 on
+// success
+package add_pack_collaborator
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/audit"
+	"popplio/notifications"
+	"popplio/routes/packs/assets"
+	"popplio/state"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Add Pack Collaborator",
+		Description: fmt.Sprintf("Lets another user edit, reorder and annotate a pack you own. Only the owner can delete the pack or manage its collaborators. A pack can have up to %d collaborators. Returns 204 on success", assets.MaxCollaborators),
+		Resp:        types.ApiError{},
+		Params: []docs.Parameter{
+			{
+				Name:        "uid",
+				Description: "The user's ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "id",
+				Description: "The pack's URL",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "cid",
+				Description: "The collaborator's user ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var id = chi.URLParam(r, "id")
+	var collaboratorId = chi.URLParam(r, "cid")
+
+	owner, _, err := assets.Access(d.Context, id, d.Auth.ID)
+
+	if errors.Is(err, assets.ErrPackNotFound) {
+		return uapi.DefaultResponse(http.StatusNotFound)
+	}
+
+	if err != nil {
+		return resp.Err("Error while checking pack owner [db fetch]", err, zap.String("id", id))
+	}
+
+	if owner != d.Auth.ID {
+		return resp.Forbidden("Only the owner of this pack can add collaborators")
+	}
+
+	if collaboratorId == owner {
+		return resp.BadRequest("The owner of a pack can't be a collaborator on it")
+	}
+
+	var userExists bool
+
+	err = state.Pool.QueryRow(d.Context, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)", collaboratorId).Scan(&userExists)
+
+	if err != nil {
+		return resp.Err("Error while checking if user exists [db fetch]", err, zap.String("id", id), zap.String("cid", collaboratorId))
+	}
+
+	if !userExists {
+		return resp.NotFound("User not found")
+	}
+
+	var count int
+
+	err = state.Pool.QueryRow(d.Context, "SELECT COUNT(*) FROM pack_collaborators WHERE pack_url = $1 AND user_id != $2", id, collaboratorId).Scan(&count)
+
+	if err != nil {
+		return resp.Err("Error while counting pack collaborators [db fetch]", err, zap.String("id", id))
+	}
+
+	if count >= assets.MaxCollaborators {
+		return resp.BadRequest(fmt.Sprintf("A pack can have at most %d collaborators", assets.MaxCollaborators))
+	}
+
+	audit.Begin(r, "add_pack_collaborator", d.Auth, "pack", id)
+
+	tag, err := state.Pool.Exec(d.Context, "INSERT INTO pack_collaborators (pack_url, user_id, added_by) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", id, collaboratorId, d.Auth.ID)
+
+	if err != nil {
+		return resp.Err("Error while adding pack collaborator [db exec]", err, zap.String("id", id), zap.String("cid", collaboratorId))
+	}
+
+	// Adding someone who is already a collaborator changes nothing
+	if tag.RowsAffected() == 0 {
+		return uapi.DefaultResponse(http.StatusNoContent)
+	}
+
+	err = notifications.PushNotification(collaboratorId, types.Alert{
+		Type:    types.AlertTypeInfo,
+		URL:     pgtype.Text{String: state.Config.Sites.Frontend.Parse() + "/pack/" + id, Valid: true},
+		Title:   "Pack Collaborator",
+		Message: fmt.Sprintf("You can now edit the pack %s.", id),
+		AlertData: map[string]any{
+			"pack_url": id,
+		},
+	})
+
+	if err != nil {
+		state.Logger.Error("Error sending pack collaborator alert", zap.Error(err), zap.String("id", id), zap.String("cid", collaboratorId))
+	}
+
+	return uapi.DefaultResponse(http.StatusNoContent)
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"net/http"
 	"popplio/api/resp"
+	"popplio/audit"
 	"popplio/state"
 	"popplio/types"
 
@@ -64,6 +65,8 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Forbidden("You are not the owner of this pack")
 	}
 
+	audit.Begin(r, "delete_pack", d.Auth, "pack", id)
+
 	// Delete the pack
 	_, err = state.Pool.Exec(d.Context, "DELETE FROM packs WHERE url = $1", id)
 
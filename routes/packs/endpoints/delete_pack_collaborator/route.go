@@ -0,0 +1,86 @@
+// Package delete_pack_collaborator implements DELETE
+// /users/{uid}/packs/{id}/collaborators/{cid} — "Remove Pack Collaborator".
+//
+// Removes a collaborator from a pack you own, or leaves a pack you
+// collaborate on. Returns 204 on success
+package delete_pack_collaborator
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/audit"
+	"popplio/routes/packs/assets"
+	"popplio/state"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Remove Pack Collaborator",
+		Description: "Removes a collaborator from a pack you own, or leaves a pack you collaborate on if `cid` is your own ID. Returns 204 on success",
+		Resp:        types.ApiError{},
+		Params: []docs.Parameter{
+			{
+				Name:        "uid",
+				Description: "The user's ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "id",
+				Description: "The pack's URL",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "cid",
+				Description: "The collaborator's user ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var id = chi.URLParam(r, "id")
+	var collaboratorId = chi.URLParam(r, "cid")
+
+	owner, _, err := assets.Access(d.Context, id, d.Auth.ID)
+
+	if errors.Is(err, assets.ErrPackNotFound) {
+		return uapi.DefaultResponse(http.StatusNotFound)
+	}
+
+	if err != nil {
+		return resp.Err("Error while checking pack owner [db fetch]", err, zap.String("id", id))
+	}
+
+	if owner != d.Auth.ID && collaboratorId != d.Auth.ID {
+		return resp.Forbidden("Only the owner of this pack can remove other collaborators")
+	}
+
+	audit.Begin(r, "delete_pack_collaborator", d.Auth, "pack", id)
+
+	tag, err := state.Pool.Exec(d.Context, "DELETE FROM pack_collaborators WHERE pack_url = $1 AND user_id = $2", id, collaboratorId)
+
+	if err != nil {
+		return resp.Err("Error while removing pack collaborator [db exec]", err, zap.String("id", id), zap.String("cid", collaboratorId))
+	}
+
+	if tag.RowsAffected() == 0 {
+		return resp.NotFound("That user is not a collaborator on this pack")
+	}
+
+	return uapi.DefaultResponse(http.StatusNoContent)
+}
@@ -1,15 +1,18 @@
 // Package patch_pack implements PATCH /users/{uid}/packs/{id} — "Patch
 // Pack".
 //
-// Edits a pack you are owner of based on the URL only. Returns 204 on
-// success
+// Edits a pack you are the owner or a collaborator of based on the URL
+// only. Items are shown in the order given. Returns 204 on success
 package patch_pack
 
 import (
 	"errors"
 	"net/http"
 	"popplio/api/resp"
+	"popplio/audit"
+	"popplio/routes/packs/assets"
 	"popplio/state"
+	"popplio/taxonomy"
 	"popplio/types"
 
 	docs "github.com/infinitybotlist/eureka/doclib"
@@ -18,7 +21,6 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
-	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
@@ -28,14 +30,14 @@ type PatchPack struct {
 	Name    string   `json:"name" validate:"required,min=3,max=20" msg:"Name must be between 3 and 20 characters"`
 	Short   string   `json:"short" validate:"required,min=10,max=100" msg:"Description must be between 10 and 100 characters"`
 	Tags    []string `json:"tags" validate:"required,unique,min=1,max=5,dive,min=3,max=30,notblank,nonvulgar" msg:"There must be between 1 and 5 tags without duplicates" amsg:"Each tag must be between 3 and 30 characters and alphabetic"`
-	Bots    []string `json:"bots" validate:"omitempty,unique,max=10,dive,numeric" msg:"There can be at most 10 bots without duplicates"`
-	Servers []string `json:"servers" validate:"omitempty,unique,max=10,dive,numeric" msg:"There can be at most 10 servers without duplicates"`
+	Bots    []string `json:"bots" validate:"omitempty,unique,max=50,dive,numeric" msg:"There can be at most 50 bots without duplicates"`
+	Servers []string `json:"servers" validate:"omitempty,unique,max=50,dive,numeric" msg:"There can be at most 50 servers without duplicates"`
 }
 
 func Docs() *docs.Doc {
 	return &docs.Doc{
 		Summary:     "Patch Pack",
-		Description: "Edits a pack you are owner of based on the URL only. Returns 204 on success",
+		Description: "Edits a pack you are the owner or a collaborator of based on the URL only. Items are shown in the order given, and notes on items removed from the pack are dropped. Packs can have up to 10 bots and 10 servers, or 50 of each if their owner is premium. Returns 204 on success",
 		Req:         PatchPack{},
 		Resp:        types.ApiError{},
 		Params: []docs.Parameter{
@@ -76,12 +78,9 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 
 	var id = chi.URLParam(r, "id")
 
-	// Check that the pack exists and get its owner in one query
-	var owner string
+	owner, canEdit, err := assets.Access(d.Context, id, d.Auth.ID)
 
-	err = state.Pool.QueryRow(d.Context, "SELECT owner FROM packs WHERE url = $1", id).Scan(&owner)
-
-	if errors.Is(err, pgx.ErrNoRows) {
+	if errors.Is(err, assets.ErrPackNotFound) {
 		return uapi.DefaultResponse(http.StatusNotFound)
 	}
 
@@ -89,14 +88,41 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Err("Error while checking pack owner [db fetch]", err, zap.String("id", id))
 	}
 
-	if owner != d.Auth.ID {
-		return resp.Forbidden("You are not the owner of this pack")
+	if !canEdit {
+		return resp.Forbidden("You are not the owner or a collaborator of this pack")
+	}
+
+	var currentTags []string
+
+	err = state.Pool.QueryRow(d.Context, "SELECT tags FROM packs WHERE url = $1", id).Scan(&currentTags)
+
+	if err != nil {
+		return resp.Err("Error while getting pack tags [db fetch]", err, zap.String("id", id))
 	}
 
+	audit.Begin(r, "patch_pack", d.Auth, "pack", id)
+
 	if len(payload.Bots)+len(payload.Servers) == 0 {
 		return resp.BadRequest("A pack must contain at least one bot or server")
 	}
 
+	// The limit is the owner's, whoever is editing
+	if err := assets.CheckItemLimit(owner, payload.Bots, payload.Servers); err != nil {
+		return resp.BadRequest(err.Error())
+	}
+
+	tags, tagErr, err := taxonomy.Normalize(d.Context, "pack", payload.Tags, currentTags)
+
+	if err != nil {
+		return resp.Err("Failed to normalize tags", err)
+	}
+
+	if tagErr != nil {
+		return resp.BadRequest(tagErr.Error())
+	}
+
+	payload.Tags = tags
+
 	// Check that all bots exist. Anyone may add any existing bot/server to a
 	// pack — packs are curated lists, not something scoped to what the
 	// author owns.
@@ -143,5 +169,11 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Err("Error while updating pack [db exec]", err, zap.String("id", id))
 	}
 
+	err = assets.PruneNotes(d.Context, id, payload.Bots, payload.Servers)
+
+	if err != nil {
+		return resp.Err("Error while removing notes on removed items [db exec]", err, zap.String("id", id))
+	}
+
 	return uapi.DefaultResponse(http.StatusNoContent)
 }
@@ -0,0 +1,147 @@
+// Package reorder_pack_items implements PUT /users/{uid}/packs/{id}/order —
+// "Reorder Pack Items".
+//
+// Changes the order a pack's bots and servers are shown in. Returns 204 on
+// success
+package reorder_pack_items
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/audit"
+	"popplio/routes/packs/assets"
+	"popplio/state"
+	"popplio/types"
+	"slices"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+var compiledMessages = uapi.CompileValidationErrors(types.ReorderPackItems{})
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Reorder Pack Items",
+		Description: "Changes the order a pack's bots and servers are shown in. `bots` and `servers` must each list exactly the items already in the pack, or be omitted to keep their order. You must be the owner or a collaborator of the pack. Returns 204 on success",
+		Req:         types.ReorderPackItems{},
+		Resp:        types.ApiError{},
+		Params: []docs.Parameter{
+			{
+				Name:        "uid",
+				Description: "The user's ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "id",
+				Description: "The pack's URL",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+	}
+}
+
+// sameItems returns whether order lists exactly the items in current
+func sameItems(current, order []string) bool {
+	if len(current) != len(order) {
+		return false
+	}
+
+	for _, item := range order {
+		if !slices.Contains(current, item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var id = chi.URLParam(r, "id")
+
+	var payload types.ReorderPackItems
+
+	hresp, ok := uapi.MarshalReq(r, &payload)
+
+	if !ok {
+		return hresp
+	}
+
+	err := state.Validator.Struct(payload)
+
+	if err != nil {
+		return uapi.ValidatorErrorResponse(compiledMessages, err.(validator.ValidationErrors))
+	}
+
+	_, canEdit, err := assets.Access(d.Context, id, d.Auth.ID)
+
+	if errors.Is(err, assets.ErrPackNotFound) {
+		return uapi.DefaultResponse(http.StatusNotFound)
+	}
+
+	if err != nil {
+		return resp.Err("Error while checking pack owner [db fetch]", err, zap.String("id", id))
+	}
+
+	if !canEdit {
+		return resp.Forbidden("You are not the owner or a collaborator of this pack")
+	}
+
+	audit.Begin(r, "reorder_pack_items", d.Auth, "pack", id)
+
+	tx, err := state.Pool.Begin(d.Context)
+
+	if err != nil {
+		return resp.Err("Error beginning transaction", err, zap.String("id", id))
+	}
+
+	defer tx.Rollback(d.Context)
+
+	// Locked so the items can't change between checking and reordering them
+	var bots, servers []string
+
+	err = tx.QueryRow(d.Context, "SELECT bots, servers FROM packs WHERE url = $1 FOR UPDATE", id).Scan(&bots, &servers)
+
+	if err != nil {
+		return resp.Err("Error while getting pack items [db fetch]", err, zap.String("id", id))
+	}
+
+	if payload.Bots != nil {
+		if !sameItems(bots, payload.Bots) {
+			return resp.BadRequest("The new order of bots must list exactly the bots in the pack")
+		}
+
+		bots = payload.Bots
+	}
+
+	if payload.Servers != nil {
+		if !sameItems(servers, payload.Servers) {
+			return resp.BadRequest("The new order of servers must list exactly the servers in the pack")
+		}
+
+		servers = payload.Servers
+	}
+
+	_, err = tx.Exec(d.Context, "UPDATE packs SET bots = $1, servers = $2 WHERE url = $3", bots, servers, id)
+
+	if err != nil {
+		return resp.Err("Error while reordering pack [db exec]", err, zap.String("id", id))
+	}
+
+	err = tx.Commit(d.Context)
+
+	if err != nil {
+		return resp.Err("Error committing transaction", err, zap.String("id", id))
+	}
+
+	return uapi.DefaultResponse(http.StatusNoContent)
+}
@@ -0,0 +1,142 @@
+// Package set_pack_item_note implements PUT
+// /users/{uid}/packs/{id}/items/{target_type}/{target_id}/note — "Set Pack
+// Item Note".
+//
+// Sets or removes the note on one of a pack's bots or servers. Returns 204 on
+// success
+package set_pack_item_note
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/audit"
+	"popplio/routes/packs/assets"
+	"popplio/state"
+	"popplio/types"
+	"popplio/validators"
+	"strings"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+var compiledMessages = uapi.CompileValidationErrors(types.SetPackItemNote{})
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Set Pack Item Note",
+		Description: "Sets the note on one of a pack's bots or servers, such as why it was picked. An empty note removes it. You must be the owner or a collaborator of the pack. Returns 204 on success",
+		Req:         types.SetPackItemNote{},
+		Resp:        types.ApiError{},
+		Params: []docs.Parameter{
+			{
+				Name:        "uid",
+				Description: "The user's ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "id",
+				Description: "The pack's URL",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "target_type",
+				Description: "Whether the item is a bot or a server",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "target_id",
+				Description: "The ID of the bot or server",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var id = chi.URLParam(r, "id")
+	var targetType = validators.NormalizeTargetType(chi.URLParam(r, "target_type"))
+	var targetId = chi.URLParam(r, "target_id")
+
+	if targetType != "bot" && targetType != "server" {
+		return resp.BadRequest("Only bots and servers can be in a pack")
+	}
+
+	var payload types.SetPackItemNote
+
+	hresp, ok := uapi.MarshalReq(r, &payload)
+
+	if !ok {
+		return hresp
+	}
+
+	err := state.Validator.Struct(payload)
+
+	if err != nil {
+		return uapi.ValidatorErrorResponse(compiledMessages, err.(validator.ValidationErrors))
+	}
+
+	_, canEdit, err := assets.Access(d.Context, id, d.Auth.ID)
+
+	if errors.Is(err, assets.ErrPackNotFound) {
+		return uapi.DefaultResponse(http.StatusNotFound)
+	}
+
+	if err != nil {
+		return resp.Err("Error while checking pack owner [db fetch]", err, zap.String("id", id))
+	}
+
+	if !canEdit {
+		return resp.Forbidden("You are not the owner or a collaborator of this pack")
+	}
+
+	var pack types.BotPack
+
+	err = state.Pool.QueryRow(d.Context, "SELECT bots, servers FROM packs WHERE url = $1", id).Scan(&pack.Bots, &pack.Servers)
+
+	if err != nil {
+		return resp.Err("Error while getting pack items [db fetch]", err, zap.String("id", id))
+	}
+
+	if !assets.HasItem(&pack, targetType, targetId) {
+		return resp.NotFound("That " + targetType + " is not in this pack")
+	}
+
+	audit.Begin(r, "set_pack_item_note", d.Auth, "pack", id)
+
+	note := strings.TrimSpace(payload.Note)
+
+	if note == "" {
+		_, err = state.Pool.Exec(d.Context, "DELETE FROM pack_item_notes WHERE pack_url = $1 AND target_type = $2 AND target_id = $3", id, targetType, targetId)
+	} else {
+		_, err = state.Pool.Exec(
+			d.Context,
+			`INSERT INTO pack_item_notes (pack_url, target_type, target_id, note, updated_by) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (pack_url, target_type, target_id) DO UPDATE SET note = EXCLUDED.note, updated_by = EXCLUDED.updated_by, updated_at = NOW()`,
+			id,
+			targetType,
+			targetId,
+			note,
+			d.Auth.ID,
+		)
+	}
+
+	if err != nil {
+		return resp.Err("Error while setting pack item note [db exec]", err, zap.String("id", id), zap.String("targetType", targetType), zap.String("targetId", targetId))
+	}
+
+	return uapi.DefaultResponse(http.StatusNoContent)
+}
@@ -6,11 +6,15 @@ package packs
 import (
 	"popplio/api"
 	"popplio/routes/packs/endpoints/add_pack"
+	"popplio/routes/packs/endpoints/add_pack_collaborator"
 	"popplio/routes/packs/endpoints/delete_pack"
+	"popplio/routes/packs/endpoints/delete_pack_collaborator"
 	"popplio/routes/packs/endpoints/get_all_packs"
 	"popplio/routes/packs/endpoints/get_pack"
 	"popplio/routes/packs/endpoints/get_pack_seo"
 	"popplio/routes/packs/endpoints/patch_pack"
+	"popplio/routes/packs/endpoints/reorder_pack_items"
+	"popplio/routes/packs/endpoints/set_pack_item_note"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/infinitybotlist/eureka/uapi"
@@ -101,4 +105,72 @@ func (b Router) Routes(r *chi.Mux) {
 			api.PERMISSION_CHECK_KEY: nil, // No authorization is needed for this endpoint beyond defaults
 		},
 	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{uid}/packs/{id}/order",
+		OpId:    "reorder_pack_items",
+		Method:  uapi.PUT,
+		Docs:    reorder_pack_items.Docs,
+		Handler: reorder_pack_items.Route,
+		Auth: []uapi.AuthType{
+			{
+				URLVar: "uid",
+				Type:   api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // The endpoint itself handles authorization
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{uid}/packs/{id}/items/{target_type}/{target_id}/note",
+		OpId:    "set_pack_item_note",
+		Method:  uapi.PUT,
+		Docs:    set_pack_item_note.Docs,
+		Handler: set_pack_item_note.Route,
+		Auth: []uapi.AuthType{
+			{
+				URLVar: "uid",
+				Type:   api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // The endpoint itself handles authorization
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{uid}/packs/{id}/collaborators/{cid}",
+		OpId:    "add_pack_collaborator",
+		Method:  uapi.PUT,
+		Docs:    add_pack_collaborator.Docs,
+		Handler: add_pack_collaborator.Route,
+		Auth: []uapi.AuthType{
+			{
+				URLVar: "uid",
+				Type:   api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // The endpoint itself handles authorization
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{uid}/packs/{id}/collaborators/{cid}",
+		OpId:    "delete_pack_collaborator",
+		Method:  uapi.DELETE,
+		Docs:    delete_pack_collaborator.Docs,
+		Handler: delete_pack_collaborator.Route,
+		Auth: []uapi.AuthType{
+			{
+				URLVar: "uid",
+				Type:   api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // The endpoint itself handles authorization
+		},
+	}.Route(r)
 }
@@ -0,0 +1,126 @@
+// Package create_user_saved_search implements POST
+// /users/{id}/saved-searches — "Create User Saved Search".
+//
+// Saves a search, to be alerted when a newly listed bot or server matches it
+package create_user_saved_search
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"popplio/api/resp"
+	"popplio/db"
+	"popplio/notifications/savedsearches"
+	"popplio/state"
+	"popplio/taxonomy"
+	"popplio/types"
+
+	"github.com/go-playground/validator/v10"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+var (
+	savedSearchColsArr = db.GetCols(types.SavedSearch{})
+	savedSearchCols    = strings.Join(savedSearchColsArr, ",")
+
+	compiledMessages = uapi.CompileValidationErrors(types.CreateSavedSearch{})
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Create User Saved Search",
+		Description: "Saves a `POST /list/search` query under a name. Whenever a bot is approved or a server is added that matches it, the user is sent an alert (and a push notification or Discord DM, if enabled). Only bots and servers can be matched, and the query must have text or tags.\n\nA user may have up to " + strconv.Itoa(savedsearches.MaxPerUser) + " saved searches. A user is sent a limited number of saved search alerts a day, however many of their searches match, and each search alerts at most once per entity.",
+		Params: []docs.Parameter{
+			{
+				Name:        "id",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Req:  types.CreateSavedSearch{},
+		Resp: types.SavedSearch{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var payload types.CreateSavedSearch
+
+	hresp, ok := uapi.MarshalReq(r, &payload)
+
+	if !ok {
+		return hresp
+	}
+
+	err := state.Validator.Struct(payload)
+
+	if err != nil {
+		errs := err.(validator.ValidationErrors)
+		return uapi.ValidatorErrorResponse(compiledMessages, errs)
+	}
+
+	// Stored as tag IDs, as entities are tagged with, so the search matches
+	// them whatever names or aliases were given
+	payload.Query.TagFilter.Tags, err = taxonomy.Canonical(d.Context, payload.Query.TagFilter.Tags)
+
+	if err != nil {
+		return resp.Err("Error loading tag registry", err, zap.String("userID", d.Auth.ID))
+	}
+
+	if err := savedsearches.Validate(payload.Query); err != nil {
+		return resp.BadRequest(err.Error())
+	}
+
+	// Cursors and pagination have no meaning for a saved search
+	payload.Query.Cursors = types.SearchCursors{}
+	payload.Query.Limit = 0
+	payload.Query.Sort = ""
+	payload.Query.Facets = false
+
+	var count int64
+
+	err = state.Pool.QueryRow(d.Context, "SELECT COUNT(*) FROM saved_searches WHERE user_id = $1", d.Auth.ID).Scan(&count)
+
+	if err != nil {
+		return resp.Err("Error counting saved searches", err, zap.String("userID", d.Auth.ID))
+	}
+
+	if count >= savedsearches.MaxPerUser {
+		return resp.BadRequest("You can have at most " + strconv.Itoa(savedsearches.MaxPerUser) + " saved searches. Delete one to save another")
+	}
+
+	rows, err := state.Pool.Query(
+		d.Context,
+		"INSERT INTO saved_searches (user_id, name, query) VALUES ($1, $2, $3) RETURNING "+savedSearchCols,
+		d.Auth.ID,
+		payload.Name,
+		payload.Query,
+	)
+
+	if err != nil {
+		return resp.Err("Error saving search", err, zap.String("userID", d.Auth.ID))
+	}
+
+	search, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[types.SavedSearch])
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return resp.BadRequest("You already have a saved search with this name")
+	}
+
+	if err != nil {
+		return resp.Err("Error saving search [collect]", err, zap.String("userID", d.Auth.ID))
+	}
+
+	return uapi.HttpResponse{
+		Status: http.StatusCreated,
+		Json:   search,
+	}
+}
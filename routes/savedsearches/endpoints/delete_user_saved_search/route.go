@@ -0,0 +1,63 @@
+// Package delete_user_saved_search implements DELETE
+// /users/{id}/saved-searches/{search_id} — "Delete User Saved Search".
+//
+// Deletes a saved search. Returns 204 on success
+package delete_user_saved_search
+
+import (
+	"net/http"
+
+	"popplio/api/resp"
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Delete User Saved Search",
+		Description: "Deletes a saved search, which stops its alerts. Returns 204 on success",
+		Params: []docs.Parameter{
+			{
+				Name:        "id",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "search_id",
+				Description: "The ID of the saved search",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.ApiError{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	searchId := chi.URLParam(r, "search_id")
+
+	if _, err := uuid.Parse(searchId); err != nil {
+		return resp.BadRequest("Invalid saved search ID")
+	}
+
+	tag, err := state.Pool.Exec(d.Context, "DELETE FROM saved_searches WHERE id = $1 AND user_id = $2", searchId, d.Auth.ID)
+
+	if err != nil {
+		return resp.Err("Error deleting saved search", err, zap.String("userID", d.Auth.ID), zap.String("searchID", searchId))
+	}
+
+	if tag.RowsAffected() == 0 {
+		return resp.NotFound("Saved search not found")
+	}
+
+	return uapi.DefaultResponse(http.StatusNoContent)
+}
@@ -0,0 +1,65 @@
+// Package get_user_saved_searches implements GET /users/{id}/saved-searches —
+// "Get User Saved Searches".
+//
+// Gets a user's saved searches
+package get_user_saved_searches
+
+import (
+	"net/http"
+	"strings"
+
+	"popplio/api/resp"
+	"popplio/db"
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/go-chi/chi/v5"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var (
+	savedSearchColsArr = db.GetCols(types.SavedSearch{})
+	savedSearchCols    = strings.Join(savedSearchColsArr, ",")
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get User Saved Searches",
+		Description: "Gets a user's saved searches, oldest first",
+		Params: []docs.Parameter{
+			{
+				Name:        "id",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.SavedSearchList{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	id := chi.URLParam(r, "id")
+
+	rows, err := state.Pool.Query(d.Context, "SELECT "+savedSearchCols+" FROM saved_searches WHERE user_id = $1 ORDER BY created_at", id)
+
+	if err != nil {
+		return resp.Err("Error querying saved searches [db fetch]", err, zap.String("userID", id))
+	}
+
+	searches, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.SavedSearch])
+
+	if err != nil {
+		return resp.Err("Error querying saved searches [collect]", err, zap.String("userID", id))
+	}
+
+	return uapi.HttpResponse{
+		Json: types.SavedSearchList{
+			SavedSearches: searches,
+		},
+	}
+}
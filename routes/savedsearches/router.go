@@ -0,0 +1,76 @@
+// Package savedsearches mounts the "Saved Searches" group of API routes.
+//
+// These API endpoints are related to saved searches, which alert users to
+// newly listed bots and servers matching them
+package savedsearches
+
+import (
+	"popplio/api"
+	"popplio/routes/savedsearches/endpoints/create_user_saved_search"
+	"popplio/routes/savedsearches/endpoints/delete_user_saved_search"
+	"popplio/routes/savedsearches/endpoints/get_user_saved_searches"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/infinitybotlist/eureka/uapi"
+)
+
+const tagName = "Saved Searches"
+
+type Router struct{}
+
+func (b Router) Tag() (string, string) {
+	return tagName, "These API endpoints are related to saved searches, which alert users to newly listed bots and servers matching them"
+}
+
+func (b Router) Routes(r *chi.Mux) {
+	uapi.Route{
+		Pattern: "/users/{id}/saved-searches",
+		OpId:    "get_user_saved_searches",
+		Method:  uapi.GET,
+		Docs:    get_user_saved_searches.Docs,
+		Handler: get_user_saved_searches.Route,
+		Auth: []uapi.AuthType{
+			{
+				URLVar: "id",
+				Type:   api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // No authorization is needed for this endpoint beyond defaults
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{id}/saved-searches",
+		OpId:    "create_user_saved_search",
+		Method:  uapi.POST,
+		Docs:    create_user_saved_search.Docs,
+		Handler: create_user_saved_search.Route,
+		Auth: []uapi.AuthType{
+			{
+				URLVar: "id",
+				Type:   api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // No authorization is needed for this endpoint beyond defaults
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{id}/saved-searches/{search_id}",
+		OpId:    "delete_user_saved_search",
+		Method:  uapi.DELETE,
+		Docs:    delete_user_saved_search.Docs,
+		Handler: delete_user_saved_search.Route,
+		Auth: []uapi.AuthType{
+			{
+				URLVar: "id",
+				Type:   api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // No authorization is needed for this endpoint beyond defaults
+		},
+	}.Route(r)
+}
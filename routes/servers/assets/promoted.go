@@ -0,0 +1,59 @@
+package assets
+
+import (
+	"context"
+	"strings"
+
+	"popplio/db"
+	"popplio/promotions"
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var indexServerCols = strings.Join(db.GetCols(types.IndexServer{}), ",")
+
+// PromotedIndexServers returns the servers of placements in slot order,
+// resolved and with their promotion refs set. Servers that stopped being
+// listed since they were picked are left out.
+func PromotedIndexServers(ctx context.Context, placements []promotions.Placement) ([]types.IndexServer, error) {
+	ids := make([]string, len(placements))
+
+	for i, p := range placements {
+		ids[i] = p.TargetID
+	}
+
+	rows, err := state.Pool.Query(ctx, "SELECT "+indexServerCols+" FROM servers WHERE server_id = ANY($1) AND state = 'public' AND (type = 'approved' OR type = 'certified')", ids)
+
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.IndexServer])
+
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]types.IndexServer, len(found))
+
+	for _, s := range found {
+		byID[s.ServerID] = s
+	}
+
+	servers := make([]types.IndexServer, 0, len(placements))
+
+	for _, p := range placements {
+		if s, ok := byID[p.TargetID]; ok {
+			s.Promotion = p.Ref
+			servers = append(servers, s)
+		}
+	}
+
+	if err := ResolveIndexServers(ctx, servers); err != nil {
+		return nil, err
+	}
+
+	return servers, nil
+}
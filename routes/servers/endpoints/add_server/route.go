@@ -9,15 +9,18 @@ import (
 	"fmt"
 	"net/http"
 	"popplio/api/resp"
+	"popplio/audit"
 	"regexp"
 	"slices"
 	"strings"
 	"time"
 
 	"popplio/db"
+	"popplio/notifications/savedsearches"
 	"popplio/perms"
 	"popplio/routes/servers/assets"
 	"popplio/state"
+	"popplio/taxonomy"
 	"popplio/teams"
 	"popplio/types"
 	"popplio/validators"
@@ -120,6 +123,18 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.BadRequest(err.Error())
 	}
 
+	tags, tagErr, err := taxonomy.Normalize(d.Context, "server", payload.Tags, nil)
+
+	if err != nil {
+		return resp.Err("Failed to normalize tags", err)
+	}
+
+	if tagErr != nil {
+		return resp.BadRequest(tagErr.Error())
+	}
+
+	payload.Tags = tags
+
 	invite, err := assets.ResolveInvite(d.Context, payload.Invite)
 
 	if err != nil {
@@ -243,6 +258,8 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Err("Error while committing transaction", err, zap.String("userID", d.Auth.ID), zap.String("serverID", payload.ServerID))
 	}
 
+	audit.Created(r, "add_server", d.Auth, "server", payload.ServerID)
+
 	_, err = state.Discord.Rest().CreateMessage(state.Config.Channels.ModLogs, discord.MessageCreate{
 		Content: state.Config.Meta.UrgentMentions,
 		Embeds: []discord.Embed{
@@ -274,5 +291,11 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		state.Logger.Error("Error while sending server logs message", zap.Error(err), zap.String("userID", d.Auth.ID), zap.String("serverID", payload.ServerID))
 	}
 
+	err = savedsearches.Enqueue(d.Context, "server", payload.ServerID)
+
+	if err != nil {
+		state.Logger.Error("Error while queueing server for saved search alerts", zap.Error(err), zap.String("userID", d.Auth.ID), zap.String("serverID", payload.ServerID))
+	}
+
 	return uapi.DefaultResponse(http.StatusNoContent)
 }
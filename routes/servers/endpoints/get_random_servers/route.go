@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"popplio/api/resp"
 	"popplio/db"
+	"popplio/promotions"
 	"popplio/routes/servers/assets"
 	"popplio/state"
 	"popplio/types"
@@ -26,15 +27,36 @@ var (
 func Docs() *docs.Doc {
 	return &docs.Doc{
 		Summary:     "Get Random Servers",
-		Description: "Returns a list of servers from the database in random order",
+		Description: "Returns a list of servers from the database in random order. The first servers are promoted premium servers picked by the promotion engine, and have a `promotion` to pass as the `promo` query parameter of Get Server when opening their page.",
 		Resp: types.RandomServers{
 			Servers: []types.IndexServer{},
 		},
 	}
 }
 
+// count is how many servers are returned, including promoted ones
+const count = 3
+
 func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
-	rows, err := state.Pool.Query(d.Context, "SELECT "+indexServerCols+" FROM servers WHERE (type = 'approved' OR type = 'certified') AND state = 'public' ORDER BY RANDOM() LIMIT 3")
+	placements, err := promotions.Pick(d.Context, r, promotions.SurfaceServerRandom)
+
+	if err != nil {
+		return resp.Err("Failed to pick promoted servers", err)
+	}
+
+	promoted, err := assets.PromotedIndexServers(d.Context, placements)
+
+	if err != nil {
+		return resp.ErrBody("Error resolving promoted indexserver", "An error occurred while resolving index server.", err)
+	}
+
+	promotedIDs := make([]string, len(promoted))
+
+	for i, s := range promoted {
+		promotedIDs[i] = s.ServerID
+	}
+
+	rows, err := state.Pool.Query(d.Context, "SELECT "+indexServerCols+" FROM servers WHERE (type = 'approved' OR type = 'certified') AND state = 'public' AND server_id <> ALL($1) ORDER BY RANDOM() LIMIT $2", promotedIDs, count-len(promoted))
 
 	if err != nil {
 		return resp.Err("Failed to query servers [db query]", err)
@@ -53,7 +75,7 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 
 	return uapi.HttpResponse{
 		Json: types.RandomServers{
-			Servers: servers,
+			Servers: append(promoted, servers...),
 		},
 	}
 }
@@ -4,14 +4,15 @@
 package get_server
 
 import (
-	"crypto/sha256"
 	"errors"
-	"fmt"
 	"net/http"
+	"popplio/analytics"
 	"popplio/api/resp"
 	"strings"
 
 	"popplio/db"
+	"popplio/longdesc"
+	"popplio/promotions"
 	"popplio/state"
 	"popplio/teams/resolvers"
 	"popplio/types"
@@ -50,7 +51,7 @@ func Docs() *docs.Doc {
 				Name: "target",
 				Description: `The target page of the request if any. 
 				
-If target is 'page', then unique clicks will be counted based on a SHA-256 hashed IP
+If target is 'page', then a view will be counted, and unique views counted based on a SHA-256 hashed IP
 
 If target is 'invite', then the invite will be counted as a click
 
@@ -63,9 +64,30 @@ Officially recognized targets:
 				In:       "query",
 				Schema:   docs.IdSchema,
 			},
+			{
+				Name:        "referrer",
+				Description: "The URL or host the visitor came from, counted with 'page' targets. Defaults to the Referer header",
+				Required:    false,
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "vanity",
+				Description: "Set to true if the visitor came through the vanity URL, counted with 'page' targets",
+				Required:    false,
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "promo",
+				Description: "The `promotion` of the promoted slot the visitor came from, if any. Counted as a click-through with 'page' targets",
+				Required:    false,
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
 			{
 				Name:        "include",
-				Description: "What extra fields to include, comma-seperated.\n`long` => server long description",
+				Description: "What extra fields to include, comma-seperated.\n`long` => server long description\n`long_html` => server long description rendered to sanitized HTML",
 				Required:    false,
 				In:          "query",
 				Schema:      docs.IdSchema,
@@ -83,58 +105,37 @@ Officially recognized targets:
 }
 
 func handleAnalytics(r *http.Request, id, target string) error {
+	var kind analytics.Kind
+
 	switch target {
 	case "page":
-		// Get IP from request and hash it
-		hashedIp := fmt.Sprintf("%x", sha256.Sum256([]byte(r.RemoteAddr)))
-
-		// Create transaction
-		tx, err := state.Pool.Begin(state.Context)
-
-		if err != nil {
-			return fmt.Errorf("error creating transaction: %w", err)
-		}
-
-		defer tx.Rollback(state.Context)
-
-		_, err = tx.Exec(state.Context, "UPDATE servers SET clicks = clicks + 1 WHERE server_id = $1", id)
-
-		if err != nil {
-			return fmt.Errorf("error updating clicks count: %w", err)
-		}
-
-		// Check if the IP has already clicked the server by checking the unique_clicks row
-		var hasClicked bool
-
-		err = tx.QueryRow(state.Context, "SELECT $1 = ANY(unique_clicks) FROM servers WHERE server_id = $2", hashedIp, id).Scan(&hasClicked)
-
-		if err != nil {
-			return fmt.Errorf("error checking for any unique clicks from this user: %w", err)
-		}
+		kind = analytics.KindView
+	case "invite":
+		kind = analytics.KindInvite
+	default:
+		return nil
+	}
 
-		if !hasClicked {
-			// If not, add it to the array
-			state.Logger.Debug("Adding new unique click for user during handleAnalytics", zap.Error(err), zap.String("id", id), zap.String("target", target), zap.String("targetType", "bot"))
-			_, err = tx.Exec(state.Context, "UPDATE servers SET unique_clicks = array_append(unique_clicks, $1) WHERE server_id = $2", hashedIp, id)
+	referrer := r.URL.Query().Get("referrer")
 
-			if err != nil {
-				return fmt.Errorf("error adding new unique click for user: %w", err)
-			}
-		}
+	if referrer == "" {
+		referrer = r.Referer()
+	}
 
-		// Commit transaction
-		err = tx.Commit(state.Context)
+	err := analytics.Record(state.Context, r, analytics.Click{
+		TargetType: "server",
+		TargetID:   id,
+		Kind:       kind,
+		Referrer:   referrer,
+		ViaVanity:  r.URL.Query().Get("vanity") == "true",
+	})
 
-		if err != nil {
-			return fmt.Errorf("error committing transaction: %w", err)
-		}
-	case "invite":
-		// Update clicks
-		_, err := state.Pool.Exec(state.Context, "UPDATE servers SET invite_clicks = invite_clicks + 1 WHERE server_id = $1", id)
+	if err != nil {
+		return err
+	}
 
-		if err != nil {
-			return fmt.Errorf("error updating invite clicks: %w", err)
-		}
+	if promo := r.URL.Query().Get("promo"); promo != "" && kind == analytics.KindView {
+		return promotions.RecordClick(state.Context, r, "server", id, promo)
 	}
 
 	return nil
@@ -193,8 +194,7 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 
 	server.TeamOwner = &eto
 
-	var uniqueClicks int64
-	err = state.Pool.QueryRow(d.Context, "SELECT cardinality(unique_clicks) AS unique_clicks FROM servers WHERE server_id = $1", server.ServerID).Scan(&uniqueClicks)
+	uniqueClicks, err := analytics.UniqueViews(d.Context, "server", server.ServerID)
 
 	if err != nil {
 		return resp.Err("Error while getting unique clicks", err, zap.String("id", id), zap.String("target", target))
@@ -250,6 +250,19 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 				}
 
 				server.Long = long
+			case "long_html":
+				var long string
+				err := state.Pool.QueryRow(d.Context, "SELECT long FROM servers WHERE server_id = $1", server.ServerID).Scan(&long)
+
+				if err != nil {
+					return resp.Err("Error while getting server long description [db fetch]", err, zap.String("id", id), zap.String("target", target), zap.String("serverID", server.ServerID))
+				}
+
+				server.LongHTML, err = longdesc.Get(d.Context, long)
+
+				if err != nil {
+					return resp.Err("Error while rendering server long description", err, zap.String("id", id), zap.String("target", target), zap.String("serverID", server.ServerID))
+				}
 			}
 		}
 	}
@@ -0,0 +1,85 @@
+// Package get_server_analytics implements GET /servers/{id}/analytics — "Get Server
+// Analytics".
+//
+// Gets the server's daily page views, unique visitors and invite clicks
+package get_server_analytics
+
+import (
+	"errors"
+	"net/http"
+
+	"popplio/analytics"
+	"popplio/api/resp"
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/go-chi/chi/v5"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get Server Analytics",
+		Description: "Gets the server's daily page views, unique visitors and invite clicks, with views broken down by referrer and by whether they came through the vanity URL. Days are UTC, oldest first, and a range covers at most 366 days.",
+		Resp:        types.ViewAnalytics{},
+		Params: []docs.Parameter{
+			{
+				Name:        "id",
+				Description: "The server's ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "from",
+				Description: "The first day of the range, as YYYY-MM-DD. Defaults to 29 days before `to`",
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "to",
+				Description: "The last day of the range, as YYYY-MM-DD. Defaults to today",
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+		},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	id := chi.URLParam(r, "id")
+
+	from, to, err := analytics.ParseQuery(r.URL.Query())
+
+	if err != nil {
+		return resp.BadRequest(err.Error())
+	}
+
+	var exists bool
+	err = state.Pool.QueryRow(d.Context, "SELECT true FROM servers WHERE server_id = $1", id).Scan(&exists)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uapi.DefaultResponse(http.StatusNotFound)
+	}
+
+	if err != nil {
+		return resp.Err("Error while checking server exists", err, zap.String("serverID", id))
+	}
+
+	days, err := analytics.Series(d.Context, "server", id, from, to)
+
+	if err != nil {
+		return resp.Err("Error while fetching view analytics", err, zap.String("serverID", id))
+	}
+
+	return uapi.HttpResponse{
+		Json: types.ViewAnalytics{
+			From: from.Format("2006-01-02"),
+			To:   to.Format("2006-01-02"),
+			Days: days,
+		},
+	}
+}
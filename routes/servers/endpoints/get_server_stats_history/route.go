@@ -0,0 +1,92 @@
+// Package get_server_stats_history implements GET /servers/{id}/stats/history —
+// "Get Server Stats History".
+//
+// Gets the server's member counts over time
+package get_server_stats_history
+
+import (
+	"errors"
+	"net/http"
+
+	"popplio/api/resp"
+	"popplio/state"
+	"popplio/statshistory"
+	"popplio/types"
+
+	"github.com/go-chi/chi/v5"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get Server Stats History",
+		Description: "Gets the server's member counts over time, oldest first and at most 5000 points. Member counts are refreshed hourly.\n\nRaw points are kept for 7 days, hourly averages for 90 days and daily averages forever. If `resolution` is not given, the finest one still kept for all of the range is used.",
+		Resp:        types.StatsHistory{},
+		Params: []docs.Parameter{
+			{
+				Name:        "id",
+				Description: "The server's ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "from",
+				Description: "The start of the range as an RFC 3339 timestamp. Defaults to 7 days before `to`",
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "to",
+				Description: "The end of the range as an RFC 3339 timestamp. Defaults to now",
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "resolution",
+				Description: "One of raw, hourly or daily",
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+		},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	id := chi.URLParam(r, "id")
+
+	resolution, from, to, err := statshistory.ParseQuery(r.URL.Query())
+
+	if err != nil {
+		return resp.BadRequest(err.Error())
+	}
+
+	var exists bool
+	err = state.Pool.QueryRow(d.Context, "SELECT true FROM servers WHERE server_id = $1", id).Scan(&exists)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uapi.DefaultResponse(http.StatusNotFound)
+	}
+
+	if err != nil {
+		return resp.Err("Error while checking server exists", err, zap.String("serverID", id))
+	}
+
+	points, err := statshistory.History(d.Context, "server", id, resolution, from, to)
+
+	if err != nil {
+		return resp.Err("Error while fetching stats history", err, zap.String("serverID", id))
+	}
+
+	return uapi.HttpResponse{
+		Json: types.StatsHistory{
+			Resolution: resolution,
+			From:       from,
+			To:         to,
+			Points:     points,
+		},
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"popplio/db"
+	"popplio/promotions"
 	"popplio/routes/servers/assets"
 	"popplio/state"
 	"popplio/types"
@@ -30,7 +31,7 @@ var (
 func Docs() *docs.Doc {
 	return &docs.Doc{
 		Summary:     "Get Servers Index",
-		Description: "Gets the index of the server-side of the list. Returns a ``ListIndexServer`` object",
+		Description: "Gets the index of the server-side of the list. Returns a ``ListIndexServer`` object.\n\nThe premium servers are picked by the promotion engine, which rotates every premium server through the row weighted by plan. Each has a `promotion` to pass as the `promo` query parameter of Get Server when opening its page.",
 		Resp:        types.ListIndexServer{},
 	}
 }
@@ -48,12 +49,12 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Err("Error while processing certified servers", err)
 	}
 
-	// Premium Servers
-	premRows, err := state.Pool.Query(d.Context, "SELECT "+indexServersCols+" FROM servers WHERE state = 'public' AND premium = true ORDER BY approximate_votes  DESC LIMIT 9")
+	// Premium Servers, rotated by the promotion engine
+	placements, err := promotions.Pick(d.Context, r, promotions.SurfaceServerIndex)
 	if err != nil {
-		return resp.Err("Error while getting premium servers", err)
+		return resp.Err("Error while picking premium servers", err)
 	}
-	listIndex.Premium, err = processRow(d.Context, premRows)
+	listIndex.Premium, err = assets.PromotedIndexServers(d.Context, placements)
 	if err != nil {
 		return resp.Err("Error while processing premium servers", err)
 	}
@@ -6,10 +6,12 @@
 package patch_server_settings
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"popplio/api/resp"
 	"popplio/state"
+	"popplio/taxonomy"
 	"popplio/types"
 	"popplio/validators"
 	"reflect"
@@ -19,6 +21,7 @@ import (
 	"github.com/disgoorg/disgo/discord"
 	docs "github.com/infinitybotlist/eureka/doclib"
 	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 
 	"github.com/go-chi/chi/v5"
@@ -100,6 +103,30 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.BadRequest(err.Error())
 	}
 
+	var currentTags []string
+
+	err = state.Pool.QueryRow(d.Context, "SELECT tags FROM servers WHERE server_id = $1", id).Scan(&currentTags)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uapi.DefaultResponse(http.StatusNotFound)
+	}
+
+	if err != nil {
+		return resp.Err("Failed to get current tags", err, zap.String("id", id))
+	}
+
+	tags, tagErr, err := taxonomy.Normalize(d.Context, "server", payload.Tags, currentTags)
+
+	if err != nil {
+		return resp.Err("Failed to normalize tags", err)
+	}
+
+	if tagErr != nil {
+		return resp.BadRequest(tagErr.Error())
+	}
+
+	payload.Tags = tags
+
 	// Update the bot
 	// Get the arguments to pass when adding the bot
 	serverArgs := updateServerArgs(payload)
@@ -11,8 +11,11 @@ import (
 	"popplio/routes/servers/endpoints/get_all_servers"
 	"popplio/routes/servers/endpoints/get_random_servers"
 	"popplio/routes/servers/endpoints/get_server"
+	"popplio/routes/servers/endpoints/get_server_analytics"
 	"popplio/routes/servers/endpoints/get_server_meta"
+	"popplio/routes/servers/endpoints/get_server_promotion_report"
 	"popplio/routes/servers/endpoints/get_server_seo"
+	"popplio/routes/servers/endpoints/get_server_stats_history"
 	"popplio/routes/servers/endpoints/get_servers_index"
 	"popplio/routes/servers/endpoints/patch_server_settings"
 
@@ -108,6 +111,49 @@ func (b Router) Routes(r *chi.Mux) {
 		Handler: get_server_seo.Route,
 	}.Route(r)
 
+	uapi.Route{
+		Pattern: "/servers/{id}/analytics",
+		OpId:    "get_server_analytics",
+		Method:  uapi.GET,
+		Docs:    get_server_analytics.Docs,
+		Handler: get_server_analytics.Route,
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/servers/{id}/promotion-report",
+		OpId:    "get_server_promotion_report",
+		Method:  uapi.GET,
+		Docs:    get_server_promotion_report.Docs,
+		Handler: get_server_promotion_report.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type: api.TargetTypeUser,
+			},
+			{
+				Type: api.TargetTypeTeam,
+			},
+			{
+				Type: api.TargetTypeServer,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: api.PermissionCheck{
+				NeededPermission: api.Needs(perms.EntityEditServers),
+				GetTarget: func(d uapi.Route, r *http.Request, authData uapi.AuthData) (string, string) {
+					return api.TargetTypeServer, chi.URLParam(r, "id")
+				},
+			},
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/servers/{id}/stats/history",
+		OpId:    "get_server_stats_history",
+		Method:  uapi.GET,
+		Docs:    get_server_stats_history.Docs,
+		Handler: get_server_stats_history.Route,
+	}.Route(r)
+
 	uapi.Route{
 		Pattern: "/servers/{id}/settings",
 		OpId:    "patch_server_settings",
@@ -0,0 +1,75 @@
+// Package accept_team_invite implements POST
+// /users/{uid}/team-invites/{invite}/accept — "Accept Team Invite".
+//
+// Accepts a team invite, joining the team with the invite's permissions.
+// Returns a 204 on success
+package accept_team_invite
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/audit"
+	"popplio/teams"
+	"popplio/types"
+
+	"github.com/google/uuid"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Accept Team Invite",
+		Description: "Accepts a team invite, joining the team with the invite's permissions. Returns a 204 on success",
+		Params: []docs.Parameter{
+			{
+				Name:        "uid",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "invite",
+				Description: "The ID of an invite sent to the user, or the code of an invite link",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.ApiError{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var invite = chi.URLParam(r, "invite")
+
+	// The team is found up front so it can be snapshotted for the audit log
+	// before the user joins it
+	lookup := teams.InviteLink
+
+	if _, err := uuid.Parse(invite); err == nil {
+		lookup = teams.GetInvite
+	}
+
+	if inv, err := lookup(d.Context, invite); err == nil && inv != nil {
+		audit.Begin(r, "accept_team_invite", d.Auth, "team", inv.TeamID)
+	}
+
+	_, err := teams.AcceptInvite(d.Context, d.Auth.ID, invite)
+
+	switch {
+	case errors.Is(err, teams.ErrInviteNotFound):
+		return resp.NotFound("Invite not found, or it has expired")
+	case errors.Is(err, teams.ErrAlreadyMember), errors.Is(err, teams.ErrInviteStale):
+		return resp.BadRequest(err.Error())
+	case err != nil:
+		return resp.Err("Error accepting invite", err, zap.String("uid", d.Auth.ID), zap.String("invite", invite))
+	}
+
+	return uapi.DefaultResponse(http.StatusNoContent)
+}
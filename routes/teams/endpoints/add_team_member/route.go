@@ -1,7 +1,9 @@
 // Package add_team_member implements PUT /teams/{tid}/members — "Add Team
 // Member".
 //
-// Adds a member to a team. Returns a 204 on success
+// Invites a user to a team. They only become a member once they accept the
+// invite. Inviting a user again replaces their pending invite. Returns the
+// invite on success
 package add_team_member
 
 import (
@@ -11,18 +13,22 @@ import (
 	"popplio/state"
 	"popplio/teams"
 	"popplio/types"
+	"time"
 
 	docs "github.com/infinitybotlist/eureka/doclib"
 	"github.com/infinitybotlist/eureka/uapi"
 	"go.uber.org/zap"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
 )
 
+var compiledMessages = uapi.CompileValidationErrors(types.AddTeamMember{})
+
 func Docs() *docs.Doc {
 	return &docs.Doc{
 		Summary:     "Add Team Member",
-		Description: "Adds a member to a team. Returns a 204 on success",
+		Description: "Invites a user to a team. They only become a member once they accept the invite, which expires after `expires_in_hours` (7 days by default). Inviting a user again replaces their pending invite. Returns the invite on success",
 		Params: []docs.Parameter{
 			{
 				Name:        "tid",
@@ -33,7 +39,7 @@ func Docs() *docs.Doc {
 			},
 		},
 		Req:  types.AddTeamMember{},
-		Resp: types.ApiError{},
+		Resp: types.TeamInvite{},
 	}
 }
 
@@ -48,6 +54,12 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return hresp
 	}
 
+	err := state.Validator.Struct(payload)
+
+	if err != nil {
+		return uapi.ValidatorErrorResponse(compiledMessages, err.(validator.ValidationErrors))
+	}
+
 	// Ensure manager has perms to edit member permissions etc.
 	managerPerms, err := teams.GetEntityPerms(d.Context, d.Auth.ID, "team", teamId)
 
@@ -75,31 +87,23 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Forbidden("You do not have permission to give out permissions: " + err.Error())
 	}
 
-	tx, err := state.Pool.Begin(d.Context)
-
-	if err != nil {
-		return resp.Err("Error starting transaction", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
-	}
-
-	defer tx.Rollback(d.Context)
-
 	// Check if user exists on IBL
 	var userExists bool
 
-	err = tx.QueryRow(d.Context, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)", payload.UserID).Scan(&userExists)
+	err = state.Pool.QueryRow(d.Context, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)", payload.UserID).Scan(&userExists)
 
 	if err != nil {
 		return resp.Err("Error checking if user exists", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
 	}
 
 	if !userExists {
-		return resp.BadRequest("User must login here at least once before you can add them")
+		return resp.BadRequest("User must login here at least once before you can invite them")
 	}
 
 	// Check that they aren't already a member
 	var memberExists bool
 
-	err = tx.QueryRow(d.Context, "SELECT EXISTS(SELECT 1 FROM team_members WHERE team_id = $1 AND user_id = $2)", teamId, payload.UserID).Scan(&memberExists)
+	err = state.Pool.QueryRow(d.Context, "SELECT EXISTS(SELECT 1 FROM team_members WHERE team_id = $1 AND user_id = $2)", teamId, payload.UserID).Scan(&memberExists)
 
 	if err != nil {
 		return resp.Err("Error checking if user is already a member", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
@@ -109,17 +113,26 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.BadRequest("User is already a member of this team")
 	}
 
-	_, err = tx.Exec(d.Context, "INSERT INTO team_members (team_id, user_id, flags) VALUES ($1, $2, $3)", teamId, payload.UserID, payload.Perms)
+	expiry := teams.DefaultInviteExpiry
+
+	if payload.ExpiresInHours > 0 {
+		expiry = time.Duration(payload.ExpiresInHours) * time.Hour
+	}
+
+	id, err := teams.Invite(d.Context, teamId, d.Auth.ID, payload.UserID, payload.Perms, expiry)
 
 	if err != nil {
-		return resp.Err("Error adding member", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+		return resp.Err("Error creating invite", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
 	}
 
-	err = tx.Commit(d.Context)
+	invite, err := teams.GetInvite(d.Context, id)
 
 	if err != nil {
-		return resp.Err("Error committing transaction", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+		return resp.Err("Error getting invite", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
 	}
 
-	return uapi.DefaultResponse(http.StatusNoContent)
+	return uapi.HttpResponse{
+		Status: http.StatusCreated,
+		Json:   invite,
+	}
 }
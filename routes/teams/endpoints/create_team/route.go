@@ -6,8 +6,10 @@ package create_team
 import (
 	"net/http"
 	"popplio/api/resp"
+	"popplio/audit"
 	"popplio/perms"
 	"popplio/state"
+	"popplio/taxonomy"
 	"popplio/types"
 	"popplio/validators"
 	"strings"
@@ -74,7 +76,18 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 	}
 
 	if payload.Tags != nil {
-		tagList := *payload.Tags
+		tags, tagErr, err := taxonomy.Normalize(d.Context, "team", *payload.Tags, nil)
+
+		if err != nil {
+			return resp.Err("Failed to normalize tags", err, zap.String("user_id", d.Auth.ID))
+		}
+
+		if tagErr != nil {
+			return resp.BadRequest(tagErr.Error())
+		}
+
+		payload.Tags = &tags
+		tagList := tags
 
 		for _, tag := range tagList {
 			if cases.Lower(language.English).String(tag) == "nsfw" {
@@ -160,6 +173,8 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Err("Error committing transaction", err, zap.String("user_id", d.Auth.ID))
 	}
 
+	audit.Created(r, "create_team", d.Auth, "team", teamId)
+
 	return uapi.HttpResponse{
 		Status: http.StatusCreated,
 		Json: types.CreateTeamResponse{
@@ -0,0 +1,95 @@
+// Package create_team_invite_link implements POST /teams/{tid}/invite-links
+// — "Create Team Invite Link".
+//
+// Creates a shareable link anyone can join the team through, with the given
+// permissions. Returns the invite link on success
+package create_team_invite_link
+
+import (
+	"net/http"
+	"popplio/api/resp"
+	"popplio/state"
+	"popplio/teams"
+	"popplio/types"
+	"time"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+var compiledMessages = uapi.CompileValidationErrors(types.CreateTeamInviteLink{})
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Create Team Invite Link",
+		Description: "Creates a shareable link anyone can join the team through, with the given permissions, until it expires or has been used `max_uses` times. Returns the invite link on success",
+		Params: []docs.Parameter{
+			{
+				Name:        "tid",
+				Description: "Team ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Req:  types.CreateTeamInviteLink{},
+		Resp: types.TeamInvite{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var teamId = chi.URLParam(r, "tid")
+
+	var payload types.CreateTeamInviteLink
+
+	hresp, ok := uapi.MarshalReq(r, &payload)
+
+	if !ok {
+		return hresp
+	}
+
+	err := state.Validator.Struct(payload)
+
+	if err != nil {
+		return uapi.ValidatorErrorResponse(compiledMessages, err.(validator.ValidationErrors))
+	}
+
+	for _, perm := range payload.Perms {
+		if !teams.IsValidPerm(perm) {
+			return resp.BadRequest("Invalid permission: " + perm)
+		}
+	}
+
+	// Anyone with the link joins with these permissions, so the manager must
+	// be able to give them out
+	if err = teams.CanGrant(d.Context, d.Auth.ID, teamId, payload.Perms); err != nil {
+		return resp.Forbidden("You do not have permission to give out permissions: " + err.Error())
+	}
+
+	expiry := teams.DefaultInviteExpiry
+
+	if payload.ExpiresInHours > 0 {
+		expiry = time.Duration(payload.ExpiresInHours) * time.Hour
+	}
+
+	id, _, err := teams.CreateInviteLink(d.Context, teamId, d.Auth.ID, payload.Perms, expiry, payload.MaxUses)
+
+	if err != nil {
+		return resp.Err("Error creating invite link", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	invite, err := teams.GetInvite(d.Context, id)
+
+	if err != nil {
+		return resp.Err("Error getting invite link", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	return uapi.HttpResponse{
+		Status: http.StatusCreated,
+		Json:   invite,
+	}
+}
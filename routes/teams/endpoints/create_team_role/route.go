@@ -0,0 +1,155 @@
+// Package create_team_role implements POST /teams/{tid}/roles — "Create Team
+// Role".
+//
+// Creates a team role below the manager's most senior role. Returns the role
+// on success
+package create_team_role
+
+import (
+	"net/http"
+	"popplio/api/resp"
+	"popplio/perms"
+	"popplio/state"
+	"popplio/teams"
+	"popplio/types"
+	"strconv"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+var compiledMessages = uapi.CompileValidationErrors(types.CreateEditTeamRole{})
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Create Team Role",
+		Description: "Creates a team role. Roles can only be created below the manager's most senior role, and with permissions the manager has. A team can have at most " + strconv.Itoa(teams.MaxRoles) + " roles. Returns the role on success",
+		Params: []docs.Parameter{
+			{
+				Name:        "tid",
+				Description: "Team ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Req:  types.CreateEditTeamRole{},
+		Resp: types.TeamRole{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var teamId = chi.URLParam(r, "tid")
+
+	var payload types.CreateEditTeamRole
+
+	hresp, ok := uapi.MarshalReq(r, &payload)
+
+	if !ok {
+		return hresp
+	}
+
+	err := state.Validator.Struct(payload)
+
+	if err != nil {
+		return uapi.ValidatorErrorResponse(compiledMessages, err.(validator.ValidationErrors))
+	}
+
+	if err := teams.ValidateRolePerms(payload.Perms); err != nil {
+		return resp.BadRequest(err.Error())
+	}
+
+	manager, _, err := teams.LoadMember(d.Context, teamId, d.Auth.ID)
+
+	if err != nil {
+		return resp.Err("Error getting manager perms", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	// This is equivalent to going from no perms to the role's permset
+	if err = perms.CheckPatch(manager.Resolve(), perms.Entity.NewSet(), perms.Entity.ResolveStrings(payload.Perms)); err != nil {
+		return resp.Forbidden("You do not have permission to give out permissions: " + err.Error())
+	}
+
+	tx, err := state.Pool.Begin(d.Context)
+
+	if err != nil {
+		return resp.Err("Error starting transaction", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	defer tx.Rollback(d.Context)
+
+	// Positions are shifted below, so role changes to a team are serialized
+	if _, err = tx.Exec(d.Context, "SELECT 1 FROM teams WHERE id = $1 FOR UPDATE", teamId); err != nil {
+		return resp.Err("Error locking team", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	var count int32
+	var nameTaken bool
+
+	err = tx.QueryRow(d.Context, "SELECT COUNT(*), COALESCE(bool_or(name = $2), false) FROM team_roles WHERE team_id = $1", teamId, payload.Name).Scan(&count, &nameTaken)
+
+	if err != nil {
+		return resp.Err("Error getting team roles", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	if count >= teams.MaxRoles {
+		return resp.BadRequest("A team can have at most " + strconv.Itoa(teams.MaxRoles) + " roles")
+	}
+
+	if nameTaken {
+		return resp.BadRequest("A role with this name already exists")
+	}
+
+	// Default to the bottom, and don't leave gaps
+	position := count
+
+	if payload.Position != nil && *payload.Position < count {
+		position = *payload.Position
+	}
+
+	if position <= manager.Rank() {
+		return resp.Forbidden("You can only create roles below your most senior role")
+	}
+
+	_, err = tx.Exec(d.Context, "UPDATE team_roles SET position = position + 1 WHERE team_id = $1 AND position >= $2", teamId, position)
+
+	if err != nil {
+		return resp.Err("Error shifting role positions", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	rows, err := tx.Query(
+		d.Context,
+		`INSERT INTO team_roles (team_id, name, color, position, perms) VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, team_id, name, color, position, perms, created_at, updated_at`,
+		teamId,
+		payload.Name,
+		pgtype.Text{String: payload.Color, Valid: payload.Color != ""},
+		position,
+		payload.Perms,
+	)
+
+	if err != nil {
+		return resp.Err("Error creating role", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	role, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[types.TeamRole])
+
+	if err != nil {
+		return resp.Err("Error creating role", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	if err = tx.Commit(d.Context); err != nil {
+		return resp.Err("Error committing transaction", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	return uapi.HttpResponse{
+		Status: http.StatusCreated,
+		Json:   role,
+	}
+}
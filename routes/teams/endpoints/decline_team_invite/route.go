@@ -0,0 +1,59 @@
+// Package decline_team_invite implements POST
+// /users/{uid}/team-invites/{iid}/decline — "Decline Team Invite".
+//
+// Declines a team invite sent to the user. Returns a 204 on success
+package decline_team_invite
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/teams"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Decline Team Invite",
+		Description: "Declines a team invite sent to the user, letting whoever sent it know. Returns a 204 on success",
+		Params: []docs.Parameter{
+			{
+				Name:        "uid",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "iid",
+				Description: "Invite ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.ApiError{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var inviteId = chi.URLParam(r, "iid")
+
+	err := teams.DeclineInvite(d.Context, d.Auth.ID, inviteId)
+
+	if errors.Is(err, teams.ErrInviteNotFound) {
+		return resp.NotFound("Invite not found, or it has expired")
+	}
+
+	if err != nil {
+		return resp.Err("Error declining invite", err, zap.String("uid", d.Auth.ID), zap.String("iid", inviteId))
+	}
+
+	return uapi.DefaultResponse(http.StatusNoContent)
+}
@@ -19,7 +19,7 @@ import (
 func Docs() *docs.Doc {
 	return &docs.Doc{
 		Summary:     "Delete Team",
-		Description: "Deletes the team. Requires the 'Owner' permission. Returns a 204 on success",
+		Description: "Deletes the team, which must have no bots or servers. `POST /teams/{tid}/dissolve` deletes a team that still has some. Requires the 'Owner' permission. Returns a 204 on success",
 		Params: []docs.Parameter{
 			{
 				Name:        "tid",
@@ -42,6 +42,8 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Err("Error beginning transaction", err, zap.String("tid", teamId))
 	}
 
+	defer tx.Rollback(d.Context)
+
 	var botCount int
 
 	err = tx.QueryRow(d.Context, "SELECT COUNT(*) FROM bots WHERE team_owner = $1", teamId).Scan(&botCount)
@@ -51,7 +53,7 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 	}
 
 	if botCount > 0 {
-		return resp.BadRequest("You cannot delete a team with bots in it")
+		return resp.BadRequest("You cannot delete a team with bots in it. Use POST /teams/{tid}/dissolve to move or release them as the team is deleted")
 	}
 
 	var serverCount int
@@ -63,7 +65,7 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 	}
 
 	if serverCount > 0 {
-		return resp.BadRequest("You cannot delete a team with servers in it")
+		return resp.BadRequest("You cannot delete a team with servers in it. Use POST /teams/{tid}/dissolve to move or release them as the team is deleted")
 	}
 
 	_, err = tx.Exec(d.Context, "DELETE FROM team_members WHERE team_id = $1", teamId)
@@ -0,0 +1,59 @@
+// Package delete_team_invite implements DELETE /teams/{tid}/invites/{iid} —
+// "Delete Team Invite".
+//
+// Revokes an invite or invite link of the team. Returns a 204 on success
+package delete_team_invite
+
+import (
+	"net/http"
+	"popplio/api/resp"
+	"popplio/teams"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Delete Team Invite",
+		Description: "Revokes an invite or invite link of the team. Returns a 204 on success",
+		Params: []docs.Parameter{
+			{
+				Name:        "tid",
+				Description: "Team ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "iid",
+				Description: "Invite ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.ApiError{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var teamId = chi.URLParam(r, "tid")
+	var inviteId = chi.URLParam(r, "iid")
+
+	found, err := teams.RevokeInvite(d.Context, teamId, inviteId)
+
+	if err != nil {
+		return resp.Err("Error revoking invite", err, zap.String("tid", teamId), zap.String("iid", inviteId))
+	}
+
+	if !found {
+		return resp.NotFound("Invite not found")
+	}
+
+	return uapi.DefaultResponse(http.StatusNoContent)
+}
@@ -0,0 +1,118 @@
+// Package delete_team_role implements DELETE /teams/{tid}/roles/{rid} —
+// "Delete Team Role".
+//
+// Deletes a team role below the manager's most senior role, taking it away
+// from every member who has it. Returns a 204 on success
+package delete_team_role
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/perms"
+	"popplio/state"
+	"popplio/teams"
+	"popplio/types"
+
+	"github.com/google/uuid"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Delete Team Role",
+		Description: "Deletes a team role, taking it away from every member who has it. Only roles below the manager's most senior role, and with only permissions the manager has, can be deleted. Returns a 204 on success",
+		Params: []docs.Parameter{
+			{
+				Name:        "tid",
+				Description: "Team ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "rid",
+				Description: "Role ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.ApiError{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var teamId = chi.URLParam(r, "tid")
+	var roleId = chi.URLParam(r, "rid")
+
+	if _, err := uuid.Parse(roleId); err != nil {
+		return resp.NotFound("Role not found")
+	}
+
+	manager, _, err := teams.LoadMember(d.Context, teamId, d.Auth.ID)
+
+	if err != nil {
+		return resp.Err("Error getting manager perms", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	tx, err := state.Pool.Begin(d.Context)
+
+	if err != nil {
+		return resp.Err("Error starting transaction", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	defer tx.Rollback(d.Context)
+
+	// Positions are shifted below, so role changes to a team are serialized
+	if _, err = tx.Exec(d.Context, "SELECT 1 FROM teams WHERE id = $1 FOR UPDATE", teamId); err != nil {
+		return resp.Err("Error locking team", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	var (
+		oldPerms []string
+		position int32
+	)
+
+	err = tx.QueryRow(d.Context, "SELECT perms, position FROM team_roles WHERE team_id = $1 AND id = $2", teamId, roleId).Scan(&oldPerms, &position)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return resp.NotFound("Role not found")
+	}
+
+	if err != nil {
+		return resp.Err("Error getting role", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("rid", roleId))
+	}
+
+	if position <= manager.Rank() {
+		return resp.Forbidden("You can only delete roles below your most senior role")
+	}
+
+	// Deleting the role takes its permissions away from its members
+	if err = perms.CheckPatch(manager.Resolve(), perms.Entity.ResolveStrings(oldPerms), perms.Entity.NewSet()); err != nil {
+		return resp.Forbidden("You do not have permission to edit the following perms: " + err.Error())
+	}
+
+	if _, err = tx.Exec(d.Context, "DELETE FROM team_roles WHERE id = $1", roleId); err != nil {
+		return resp.Err("Error deleting role", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("rid", roleId))
+	}
+
+	if _, err = tx.Exec(d.Context, "UPDATE team_members SET roles = array_remove(roles, $1) WHERE team_id = $2 AND $1 = ANY(roles)", roleId, teamId); err != nil {
+		return resp.Err("Error removing role from members", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("rid", roleId))
+	}
+
+	if _, err = tx.Exec(d.Context, "UPDATE team_roles SET position = position - 1 WHERE team_id = $1 AND position > $2", teamId, position); err != nil {
+		return resp.Err("Error shifting role positions", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("rid", roleId))
+	}
+
+	if err = tx.Commit(d.Context); err != nil {
+		return resp.Err("Error committing transaction", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("rid", roleId))
+	}
+
+	return uapi.DefaultResponse(http.StatusNoContent)
+}
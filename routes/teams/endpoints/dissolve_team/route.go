@@ -0,0 +1,169 @@
+// Package dissolve_team implements POST /teams/{tid}/dissolve — "Dissolve
+// Team".
+//
+// Deletes a team that still has bots or servers, moving them to a member or
+// another team, or releasing them from the list, in the same transaction.
+// Requires the 'Owner' permission. Returns what was moved and released on
+// success
+package dissolve_team
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/audit"
+	"popplio/state"
+	"popplio/transfers"
+	"popplio/types"
+	"popplio/validators"
+	"popplio/webhooks/core/drivers"
+	"popplio/webhooks/events"
+
+	"github.com/disgoorg/disgo/discord"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+var compiledMessages = uapi.CompileValidationErrors(types.DissolveTeam{})
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary: "Dissolve Team",
+		Description: `Deletes a team that still has bots or servers, which ` + "`DELETE /teams/{tid}`" + ` refuses to do. Requires the 'Owner' permission.
+
+Where the team's bots and servers go is chosen separately, and is required for each of them the team has any of:
+
+- ` + "`user`" + ` gives them to a member of the team. Only bots can be owned by a user
+- ` + "`team`" + ` moves them to another team, which you must be able to add them to
+- ` + "`release`" + ` removes them from the list
+
+Everything is moved or released, and the team deleted, in one transaction. Moved bots and servers have their API sessions revoked, as the team's members hold their tokens, and are sent an ` + "`OWNER_CHANGE`" + ` webhook. Packs belong to users rather than teams, so they are unaffected.
+
+Returns what was moved and released on success`,
+		Params: []docs.Parameter{
+			{
+				Name:        "tid",
+				Description: "Team ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Req:  types.DissolveTeam{},
+		Resp: types.DissolvedTeam{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var teamId = chi.URLParam(r, "tid")
+
+	var payload types.DissolveTeam
+
+	hresp, ok := uapi.MarshalReq(r, &payload)
+
+	if !ok {
+		return hresp
+	}
+
+	err := state.Validator.Struct(payload)
+
+	if err != nil {
+		return uapi.ValidatorErrorResponse(compiledMessages, err.(validator.ValidationErrors))
+	}
+
+	// Snapshotted for the audit log before they change hands
+	bots, servers, err := transfers.TeamEntities(d.Context, teamId)
+
+	if err != nil {
+		return resp.Err("Error getting team entities", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	for _, id := range bots {
+		audit.Begin(r, "dissolve_team", d.Auth, "bot", id)
+	}
+
+	for _, id := range servers {
+		audit.Begin(r, "dissolve_team", d.Auth, "server", id)
+	}
+
+	res, err := transfers.DissolveTeam(d.Context, d.Auth.ID, teamId, payload.Bots, payload.Servers)
+
+	switch {
+	case errors.Is(err, transfers.ErrTeamNotFound):
+		return resp.NotFound("Team not found")
+	case errors.Is(err, transfers.ErrInvalidDestination):
+		return resp.BadRequest(err.Error())
+	case err != nil:
+		return resp.Err("Error dissolving team", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	for _, moved := range []struct {
+		targetType string
+		ids        []string
+		dest       *types.TeamEntityDestination
+	}{
+		{"bot", res.MovedBots, payload.Bots},
+		{"server", res.MovedServers, payload.Servers},
+	} {
+		for _, id := range moved.ids {
+			err = drivers.Send(drivers.With{
+				Data: events.WebhookOwnerChangeData{
+					OldOwnerType: "team",
+					OldOwnerID:   teamId,
+					NewOwnerType: moved.dest.Type,
+					NewOwnerID:   moved.dest.ID,
+					Reason:       "team_dissolved",
+				},
+				UserID:     d.Auth.ID,
+				TargetType: moved.targetType,
+				TargetID:   id,
+			})
+
+			if err != nil {
+				state.Logger.Error("Error sending owner change webhook", zap.Error(err), zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("targetType", moved.targetType), zap.String("targetID", id))
+			}
+		}
+	}
+
+	_, err = state.Discord.Rest().CreateMessage(state.Config.Channels.ModLogs, discord.MessageCreate{
+		Embeds: []discord.Embed{
+			{
+				Title: "Team Dissolved",
+				Color: 0xff0000,
+				Fields: []discord.EmbedField{
+					{
+						Name:   "Team ID",
+						Value:  teamId,
+						Inline: validators.TruePtr,
+					},
+					{
+						Name:   "Deleter",
+						Value:  fmt.Sprintf("<@%s>", d.Auth.ID),
+						Inline: validators.TruePtr,
+					},
+					{
+						Name:  "Bots",
+						Value: fmt.Sprintf("%d moved, %d released", len(res.MovedBots), len(res.ReleasedBots)),
+					},
+					{
+						Name:  "Servers",
+						Value: fmt.Sprintf("%d moved, %d released", len(res.MovedServers), len(res.ReleasedServers)),
+					},
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		state.Logger.Error("Error sending team dissolve mod log", zap.Error(err), zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	return uapi.HttpResponse{
+		Json: res,
+	}
+}
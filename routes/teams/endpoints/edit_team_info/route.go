@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"popplio/api/resp"
 	"popplio/state"
+	"popplio/taxonomy"
 	"popplio/types"
 	"popplio/validators"
 	"popplio/webhooks/core/drivers"
@@ -108,6 +109,18 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 	}
 
 	if payload.Tags != nil {
+		tags, tagErr, err := taxonomy.Normalize(d.Context, "team", *payload.Tags, oldTags)
+
+		if err != nil {
+			return resp.Err("Failed to normalize tags", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+		}
+
+		if tagErr != nil {
+			return resp.BadRequest(tagErr.Error())
+		}
+
+		payload.Tags = &tags
+
 		_, err = tx.Exec(d.Context, "UPDATE teams SET tags = $1 WHERE id = $2", payload.Tags, teamId)
 
 		if err != nil {
@@ -1,7 +1,7 @@
 // Package edit_team_member implements PATCH /teams/{tid}/members/{mid} —
 // "Edit Team Member Permissions".
 //
-// Edits a members permissions on a team. Returns a 204 on success
+// Edits a members permissions and roles on a team. Returns a 204 on success
 package edit_team_member
 
 import (
@@ -11,12 +11,14 @@ import (
 	"popplio/state"
 	"popplio/teams"
 	"popplio/types"
+	"slices"
 
 	docs "github.com/infinitybotlist/eureka/doclib"
 	"github.com/infinitybotlist/eureka/uapi"
 	"go.uber.org/zap"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 var globalOwner = perms.EntityOwner
@@ -24,7 +26,7 @@ var globalOwner = perms.EntityOwner
 func Docs() *docs.Doc {
 	return &docs.Doc{
 		Summary:     "Edit Team Member Permissions",
-		Description: "Edits a members permissions on a team. Returns a 204 on success",
+		Description: "Edits a members permissions and roles on a team. Roles at or above the manager's most senior role can't be given out or taken away. Returns a 204 on success",
 		Params: []docs.Parameter{
 			{
 				Name:        "tid",
@@ -92,29 +94,86 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 
 	defer tx.Rollback(d.Context)
 
-	if payload.Perms != nil {
-		// Get the old permissions of the user
-		currentUserPerms, err := teams.GetEntityPerms(d.Context, userId, "team", teamId)
+	if payload.Perms != nil || payload.Roles != nil {
+		// Get where the manager's and the user's permissions come from
+		managerGrants, _, err := teams.LoadMember(d.Context, teamId, d.Auth.ID)
+
+		if err != nil {
+			return resp.ErrDetail("Error getting manager perms", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("mid", userId))
+		}
+
+		current, _, err := teams.LoadMember(d.Context, teamId, userId)
 
 		if err != nil {
 			return resp.ErrDetail("Error getting old perms", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("mid", userId))
 		}
 
-		// Perform initial checks
-		for _, perm := range *payload.Perms {
-			if !teams.IsValidPerm(perm) {
-				return resp.BadRequest("Invalid permission: " + perm)
+		// Members above the manager's own rank can't have their permissions
+		// or roles changed by them at all
+		if userId != d.Auth.ID && !managerGrants.CanManage(current) {
+			return resp.Forbidden("You can't edit the permissions of members ranked above you")
+		}
+
+		currentUserPerms := current.Resolve()
+		next := current
+
+		if payload.Perms != nil {
+			// Perform initial checks
+			for _, perm := range *payload.Perms {
+				if !teams.IsValidPerm(perm) {
+					return resp.BadRequest("Invalid permission: " + perm)
+				}
+			}
+
+			next.Extras = perms.ParseStrings(*payload.Perms)
+		}
+
+		if payload.Roles != nil {
+			for _, roleId := range *payload.Roles {
+				if _, err := uuid.Parse(roleId); err != nil {
+					return resp.BadRequest("Invalid role ID: " + roleId)
+				}
+			}
+
+			roles, err := teams.GetRoles(d.Context, teamId)
+
+			if err != nil {
+				return resp.Err("Error getting team roles", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("mid", userId))
+			}
+
+			next.Roles = []perms.Role{}
+
+			for _, role := range roles {
+				had := slices.ContainsFunc(current.Roles, func(r perms.Role) bool { return r.ID == role.ID })
+				has := slices.Contains(*payload.Roles, role.ID)
+
+				// Roles at or above the manager's own can't be given out or taken away
+				if had != has && role.Position <= managerGrants.Rank() {
+					return resp.Forbidden("You can only give out and take away roles below your most senior role: " + role.Name)
+				}
+
+				if has {
+					next.Roles = append(next.Roles, perms.Role{
+						ID:    role.ID,
+						Name:  role.Name,
+						Index: role.Position,
+						Perms: perms.ParseStrings(role.Perms),
+					})
+				}
+			}
+
+			for _, roleId := range *payload.Roles {
+				if !slices.ContainsFunc(next.Roles, func(r perms.Role) bool { return r.ID == roleId }) {
+					return resp.BadRequest("Role not found: " + roleId)
+				}
 			}
 		}
 
-		// Resolve the permissions
-		//
-		// Teams have no position hierarchy, so a member's flags are a single
-		// flat source of permissions
-		newPermsResolved := perms.Entity.ResolveStrings(*payload.Perms)
+		// Resolve the permissions, the roles plus the flags
+		newPermsResolved := next.Resolve()
 
 		// First ensure that the manager can set these permissions
-		if err = perms.CheckPatch(managerPerms, currentUserPerms, newPermsResolved); err != nil {
+		if err = perms.CheckPatch(managerGrants.Resolve(), currentUserPerms, newPermsResolved); err != nil {
 			return resp.Forbidden("You do not have permission to set these permissions.")
 		}
 
@@ -133,7 +192,13 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 			}
 		}
 
-		_, err = tx.Exec(d.Context, "UPDATE team_members SET flags = $1 WHERE team_id = $2 AND user_id = $3", payload.Perms, teamId, userId)
+		roleIds := make([]string, 0, len(next.Roles))
+
+		for _, role := range next.Roles {
+			roleIds = append(roleIds, role.ID)
+		}
+
+		_, err = tx.Exec(d.Context, "UPDATE team_members SET flags = $1, roles = $2::text[]::uuid[] WHERE team_id = $3 AND user_id = $4", perms.Strings(next.Extras), roleIds, teamId, userId)
 
 		if err != nil {
 			return resp.Err("Error updating perms", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("mid", userId))
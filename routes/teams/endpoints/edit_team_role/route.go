@@ -0,0 +1,196 @@
+// Package edit_team_role implements PATCH /teams/{tid}/roles/{rid} — "Edit
+// Team Role".
+//
+// Edits a team role below the manager's most senior role. Returns the role on
+// success
+package edit_team_role
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/perms"
+	"popplio/state"
+	"popplio/teams"
+	"popplio/types"
+
+	"github.com/google/uuid"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+var compiledMessages = uapi.CompileValidationErrors(types.CreateEditTeamRole{})
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Edit Team Role",
+		Description: "Edits a team role. Only roles below the manager's most senior role can be edited or moved, and only permissions the manager has can be added or removed. Returns the role on success",
+		Params: []docs.Parameter{
+			{
+				Name:        "tid",
+				Description: "Team ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "rid",
+				Description: "Role ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Req:  types.CreateEditTeamRole{},
+		Resp: types.TeamRole{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var teamId = chi.URLParam(r, "tid")
+	var roleId = chi.URLParam(r, "rid")
+
+	if _, err := uuid.Parse(roleId); err != nil {
+		return resp.NotFound("Role not found")
+	}
+
+	var payload types.CreateEditTeamRole
+
+	hresp, ok := uapi.MarshalReq(r, &payload)
+
+	if !ok {
+		return hresp
+	}
+
+	err := state.Validator.Struct(payload)
+
+	if err != nil {
+		return uapi.ValidatorErrorResponse(compiledMessages, err.(validator.ValidationErrors))
+	}
+
+	if err := teams.ValidateRolePerms(payload.Perms); err != nil {
+		return resp.BadRequest(err.Error())
+	}
+
+	manager, _, err := teams.LoadMember(d.Context, teamId, d.Auth.ID)
+
+	if err != nil {
+		return resp.Err("Error getting manager perms", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	tx, err := state.Pool.Begin(d.Context)
+
+	if err != nil {
+		return resp.Err("Error starting transaction", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	defer tx.Rollback(d.Context)
+
+	// Positions are shifted below, so role changes to a team are serialized
+	if _, err = tx.Exec(d.Context, "SELECT 1 FROM teams WHERE id = $1 FOR UPDATE", teamId); err != nil {
+		return resp.Err("Error locking team", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	var (
+		oldPerms    []string
+		oldPosition int32
+		count       int32
+	)
+
+	err = tx.QueryRow(
+		d.Context,
+		"SELECT perms, position, (SELECT COUNT(*) FROM team_roles WHERE team_id = $1) FROM team_roles WHERE team_id = $1 AND id = $2",
+		teamId,
+		roleId,
+	).Scan(&oldPerms, &oldPosition, &count)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return resp.NotFound("Role not found")
+	}
+
+	if err != nil {
+		return resp.Err("Error getting role", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("rid", roleId))
+	}
+
+	managerRank := manager.Rank()
+
+	if oldPosition <= managerRank {
+		return resp.Forbidden("You can only edit roles below your most senior role")
+	}
+
+	if err = perms.CheckPatch(manager.Resolve(), perms.Entity.ResolveStrings(oldPerms), perms.Entity.ResolveStrings(payload.Perms)); err != nil {
+		return resp.Forbidden("You do not have permission to edit the following perms: " + err.Error())
+	}
+
+	var nameTaken bool
+
+	err = tx.QueryRow(d.Context, "SELECT EXISTS(SELECT 1 FROM team_roles WHERE team_id = $1 AND name = $2 AND id != $3)", teamId, payload.Name, roleId).Scan(&nameTaken)
+
+	if err != nil {
+		return resp.Err("Error checking role name", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("rid", roleId))
+	}
+
+	if nameTaken {
+		return resp.BadRequest("A role with this name already exists")
+	}
+
+	position := oldPosition
+
+	if payload.Position != nil {
+		position = min(*payload.Position, count-1)
+	}
+
+	if position <= managerRank {
+		return resp.Forbidden("You can only move roles below your most senior role")
+	}
+
+	if position != oldPosition {
+		// Take the role out of the order, then make room for it again
+		_, err = tx.Exec(d.Context, "UPDATE team_roles SET position = position - 1 WHERE team_id = $1 AND position > $2", teamId, oldPosition)
+
+		if err != nil {
+			return resp.Err("Error shifting role positions", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("rid", roleId))
+		}
+
+		_, err = tx.Exec(d.Context, "UPDATE team_roles SET position = position + 1 WHERE team_id = $1 AND position >= $2 AND id != $3", teamId, position, roleId)
+
+		if err != nil {
+			return resp.Err("Error shifting role positions", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("rid", roleId))
+		}
+	}
+
+	rows, err := tx.Query(
+		d.Context,
+		`UPDATE team_roles SET name = $1, color = $2, position = $3, perms = $4, updated_at = NOW() WHERE id = $5
+		RETURNING id, team_id, name, color, position, perms, created_at, updated_at`,
+		payload.Name,
+		pgtype.Text{String: payload.Color, Valid: payload.Color != ""},
+		position,
+		payload.Perms,
+		roleId,
+	)
+
+	if err != nil {
+		return resp.Err("Error updating role", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("rid", roleId))
+	}
+
+	role, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[types.TeamRole])
+
+	if err != nil {
+		return resp.Err("Error updating role", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("rid", roleId))
+	}
+
+	if err = tx.Commit(d.Context); err != nil {
+		return resp.Err("Error committing transaction", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("rid", roleId))
+	}
+
+	return uapi.HttpResponse{
+		Json: role,
+	}
+}
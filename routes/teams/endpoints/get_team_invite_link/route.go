@@ -0,0 +1,54 @@
+// Package get_team_invite_link implements GET /team-invites/{code} — "Get
+// Team Invite Link".
+//
+// Gets an invite link by its code, to show who is being invited to what
+// before accepting it
+package get_team_invite_link
+
+import (
+	"net/http"
+	"popplio/api/resp"
+	"popplio/teams"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get Team Invite Link",
+		Description: "Gets an invite link by its code, to show who is being invited to what before accepting it. Accept it with the code through Accept Team Invite",
+		Params: []docs.Parameter{
+			{
+				Name:        "code",
+				Description: "The invite link's code",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.TeamInvite{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var code = chi.URLParam(r, "code")
+
+	invite, err := teams.InviteLink(d.Context, code)
+
+	if err != nil {
+		return resp.Err("Error getting invite link", err, zap.String("code", code))
+	}
+
+	if invite == nil {
+		return resp.NotFound("Invite not found, or it has expired")
+	}
+
+	return uapi.HttpResponse{
+		Json: invite,
+	}
+}
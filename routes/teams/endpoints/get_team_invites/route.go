@@ -0,0 +1,51 @@
+// Package get_team_invites implements GET /teams/{tid}/invites — "Get Team
+// Invites".
+//
+// Gets the team's pending invites and invite links
+package get_team_invites
+
+import (
+	"net/http"
+	"popplio/api/resp"
+	"popplio/teams"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get Team Invites",
+		Description: "Gets the team's pending invites and invite links, newest first. Expired and used up invites are not included",
+		Params: []docs.Parameter{
+			{
+				Name:        "tid",
+				Description: "Team ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.TeamInviteList{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var teamId = chi.URLParam(r, "tid")
+
+	invites, err := teams.TeamInvites(d.Context, teamId)
+
+	if err != nil {
+		return resp.Err("Error getting team invites", err, zap.String("tid", teamId))
+	}
+
+	return uapi.HttpResponse{
+		Json: types.TeamInviteList{
+			Invites: invites,
+		},
+	}
+}
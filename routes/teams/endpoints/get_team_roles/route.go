@@ -0,0 +1,56 @@
+// Package get_team_roles implements GET /teams/{tid}/roles — "Get Team
+// Roles".
+//
+// Gets the team's roles, most senior first
+package get_team_roles
+
+import (
+	"net/http"
+	"popplio/api/resp"
+	"popplio/teams"
+	"popplio/types"
+
+	"github.com/google/uuid"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get Team Roles",
+		Description: "Gets the team's roles, most senior first",
+		Params: []docs.Parameter{
+			{
+				Name:        "tid",
+				Description: "Team ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.TeamRoleList{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var teamId = chi.URLParam(r, "tid")
+
+	if _, err := uuid.Parse(teamId); err != nil {
+		return resp.BadRequest("Invalid team ID")
+	}
+
+	roles, err := teams.GetRoles(d.Context, teamId)
+
+	if err != nil {
+		return resp.Err("Error getting team roles", err, zap.String("tid", teamId))
+	}
+
+	return uapi.HttpResponse{
+		Json: types.TeamRoleList{
+			Roles: roles,
+		},
+	}
+}
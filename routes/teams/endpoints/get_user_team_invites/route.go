@@ -0,0 +1,47 @@
+// Package get_user_team_invites implements GET /users/{uid}/team-invites —
+// "Get User Team Invites".
+//
+// Gets the pending team invites sent to the user
+package get_user_team_invites
+
+import (
+	"net/http"
+	"popplio/api/resp"
+	"popplio/teams"
+	"popplio/types"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get User Team Invites",
+		Description: "Gets the pending team invites sent to the user, newest first",
+		Params: []docs.Parameter{
+			{
+				Name:        "uid",
+				Description: "User ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Resp: types.TeamInviteList{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	invites, err := teams.UserInvites(d.Context, d.Auth.ID)
+
+	if err != nil {
+		return resp.Err("Error getting team invites", err, zap.String("uid", d.Auth.ID))
+	}
+
+	return uapi.HttpResponse{
+		Json: types.TeamInviteList{
+			Invites: invites,
+		},
+	}
+}
@@ -0,0 +1,90 @@
+// Package transfer_team_ownership implements POST
+// /teams/{tid}/transfer-ownership — "Transfer Team Ownership".
+//
+// Hands ownership of a team over to another of its members. Requires the
+// 'Owner' permission. Returns a 204 on success
+package transfer_team_ownership
+
+import (
+	"errors"
+	"net/http"
+	"popplio/api/resp"
+	"popplio/state"
+	"popplio/transfers"
+	"popplio/types"
+	"popplio/webhooks/core/drivers"
+	"popplio/webhooks/events"
+
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+var compiledMessages = uapi.CompileValidationErrors(types.TransferTeamOwnership{})
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary: "Transfer Team Ownership",
+		Description: `Hands ownership of a team over to another of its members, who is given the 'Owner' permission in place of the user making the request. Requires the 'Owner' permission.
+
+The old owner stays on as a member with their other permissions and roles, or leaves the team if ` + "`leave`" + ` is set. Sends a ` + "`TEAM_OWNERSHIP_TRANSFER`" + ` webhook. Returns a 204 on success`,
+		Params: []docs.Parameter{
+			{
+				Name:        "tid",
+				Description: "Team ID",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+		},
+		Req:  types.TransferTeamOwnership{},
+		Resp: types.ApiError{},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	var teamId = chi.URLParam(r, "tid")
+
+	var payload types.TransferTeamOwnership
+
+	hresp, ok := uapi.MarshalReq(r, &payload)
+
+	if !ok {
+		return hresp
+	}
+
+	err := state.Validator.Struct(payload)
+
+	if err != nil {
+		return uapi.ValidatorErrorResponse(compiledMessages, err.(validator.ValidationErrors))
+	}
+
+	err = transfers.TransferTeamOwnership(d.Context, teamId, d.Auth.ID, payload.UserID, payload.Leave)
+
+	switch {
+	case errors.Is(err, transfers.ErrNotMember), errors.Is(err, transfers.ErrAlreadyTeamOwner):
+		return resp.BadRequest(err.Error())
+	case err != nil:
+		return resp.Err("Error transferring team ownership", err, zap.String("uid", d.Auth.ID), zap.String("tid", teamId), zap.String("newOwner", payload.UserID))
+	}
+
+	err = drivers.Send(drivers.With{
+		Data: events.WebhookTeamOwnershipTransferData{
+			OldOwner: d.Auth.ID,
+			NewOwner: payload.UserID,
+			Left:     payload.Leave,
+		},
+		UserID:     d.Auth.ID,
+		TargetType: "team",
+		TargetID:   teamId,
+	})
+
+	if err != nil {
+		state.Logger.Error("Error sending team ownership transfer webhook", zap.Error(err), zap.String("uid", d.Auth.ID), zap.String("tid", teamId))
+	}
+
+	return uapi.DefaultResponse(http.StatusNoContent)
+}
@@ -7,16 +7,29 @@ import (
 	"net/http"
 	"popplio/api"
 	"popplio/perms"
+	"popplio/routes/teams/endpoints/accept_team_invite"
 	"popplio/routes/teams/endpoints/add_team_member"
 	"popplio/routes/teams/endpoints/create_team"
+	"popplio/routes/teams/endpoints/create_team_invite_link"
+	"popplio/routes/teams/endpoints/create_team_role"
+	"popplio/routes/teams/endpoints/decline_team_invite"
 	"popplio/routes/teams/endpoints/delete_team"
+	"popplio/routes/teams/endpoints/delete_team_invite"
 	"popplio/routes/teams/endpoints/delete_team_member"
+	"popplio/routes/teams/endpoints/delete_team_role"
+	"popplio/routes/teams/endpoints/dissolve_team"
 	"popplio/routes/teams/endpoints/edit_team_info"
 	"popplio/routes/teams/endpoints/edit_team_member"
+	"popplio/routes/teams/endpoints/edit_team_role"
 	"popplio/routes/teams/endpoints/get_entity_permissions"
 	"popplio/routes/teams/endpoints/get_team"
+	"popplio/routes/teams/endpoints/get_team_invite_link"
+	"popplio/routes/teams/endpoints/get_team_invites"
 	"popplio/routes/teams/endpoints/get_team_permissions"
+	"popplio/routes/teams/endpoints/get_team_roles"
 	"popplio/routes/teams/endpoints/get_team_seo"
+	"popplio/routes/teams/endpoints/get_user_team_invites"
+	"popplio/routes/teams/endpoints/transfer_team_ownership"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/infinitybotlist/eureka/uapi"
@@ -129,6 +142,49 @@ func (b Router) Routes(r *chi.Mux) {
 		},
 	}.Route(r)
 
+	uapi.Route{
+		Pattern: "/teams/{tid}/dissolve",
+		OpId:    "dissolve_team",
+		Method:  uapi.POST,
+		Docs:    dissolve_team.Docs,
+		Handler: dissolve_team.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type: api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: api.PermissionCheck{
+				// Like deleting a team, dissolving one is Owner-only
+				NeededPermission: api.Needs(perms.EntityOwner),
+				GetTarget: func(d uapi.Route, r *http.Request, authData uapi.AuthData) (string, string) {
+					return api.TargetTypeTeam, chi.URLParam(r, "tid")
+				},
+			},
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/teams/{tid}/transfer-ownership",
+		OpId:    "transfer_team_ownership",
+		Method:  uapi.POST,
+		Docs:    transfer_team_ownership.Docs,
+		Handler: transfer_team_ownership.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type: api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: api.PermissionCheck{
+				NeededPermission: api.Needs(perms.EntityOwner),
+				GetTarget: func(d uapi.Route, r *http.Request, authData uapi.AuthData) (string, string) {
+					return api.TargetTypeTeam, chi.URLParam(r, "tid")
+				},
+			},
+		},
+	}.Route(r)
+
 	uapi.Route{
 		Pattern: "/teams/{tid}/members",
 		OpId:    "add_team_member",
@@ -200,4 +256,216 @@ func (b Router) Routes(r *chi.Mux) {
 			},
 		},
 	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/teams/{tid}/invite-links",
+		OpId:    "create_team_invite_link",
+		Method:  uapi.POST,
+		Docs:    create_team_invite_link.Docs,
+		Handler: create_team_invite_link.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type: api.TargetTypeUser,
+			},
+			{
+				Type: api.TargetTypeTeam,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: api.PermissionCheck{
+				NeededPermission: api.Needs(perms.EntityAddMembers),
+				GetTarget: func(d uapi.Route, r *http.Request, authData uapi.AuthData) (string, string) {
+					return api.TargetTypeTeam, chi.URLParam(r, "tid")
+				},
+			},
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/teams/{tid}/invites",
+		OpId:    "get_team_invites",
+		Method:  uapi.GET,
+		Docs:    get_team_invites.Docs,
+		Handler: get_team_invites.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type: api.TargetTypeUser,
+			},
+			{
+				Type: api.TargetTypeTeam,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: api.PermissionCheck{
+				NeededPermission: api.Needs(perms.EntityAddMembers),
+				GetTarget: func(d uapi.Route, r *http.Request, authData uapi.AuthData) (string, string) {
+					return api.TargetTypeTeam, chi.URLParam(r, "tid")
+				},
+			},
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/teams/{tid}/invites/{iid}",
+		OpId:    "delete_team_invite",
+		Method:  uapi.DELETE,
+		Docs:    delete_team_invite.Docs,
+		Handler: delete_team_invite.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type: api.TargetTypeUser,
+			},
+			{
+				Type: api.TargetTypeTeam,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: api.PermissionCheck{
+				NeededPermission: api.Needs(perms.EntityAddMembers),
+				GetTarget: func(d uapi.Route, r *http.Request, authData uapi.AuthData) (string, string) {
+					return api.TargetTypeTeam, chi.URLParam(r, "tid")
+				},
+			},
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{uid}/team-invites",
+		OpId:    "get_user_team_invites",
+		Method:  uapi.GET,
+		Docs:    get_user_team_invites.Docs,
+		Handler: get_user_team_invites.Route,
+		Auth: []uapi.AuthType{
+			{
+				URLVar: "uid",
+				Type:   api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // No authorization is needed for this endpoint beyond defaults
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{uid}/team-invites/{invite}/accept",
+		OpId:    "accept_team_invite",
+		Method:  uapi.POST,
+		Docs:    accept_team_invite.Docs,
+		Handler: accept_team_invite.Route,
+		Auth: []uapi.AuthType{
+			{
+				URLVar: "uid",
+				Type:   api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // No authorization is needed for this endpoint beyond defaults
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/users/{uid}/team-invites/{iid}/decline",
+		OpId:    "decline_team_invite",
+		Method:  uapi.POST,
+		Docs:    decline_team_invite.Docs,
+		Handler: decline_team_invite.Route,
+		Auth: []uapi.AuthType{
+			{
+				URLVar: "uid",
+				Type:   api.TargetTypeUser,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: nil, // No authorization is needed for this endpoint beyond defaults
+		},
+	}.Route(r)
+
+	// Intentionally without authentication, so invite links can be previewed
+	uapi.Route{
+		Pattern: "/team-invites/{code}",
+		OpId:    "get_team_invite_link",
+		Method:  uapi.GET,
+		Docs:    get_team_invite_link.Docs,
+		Handler: get_team_invite_link.Route,
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/teams/{tid}/roles",
+		OpId:    "get_team_roles",
+		Method:  uapi.GET,
+		Docs:    get_team_roles.Docs,
+		Handler: get_team_roles.Route,
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/teams/{tid}/roles",
+		OpId:    "create_team_role",
+		Method:  uapi.POST,
+		Docs:    create_team_role.Docs,
+		Handler: create_team_role.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type: api.TargetTypeUser,
+			},
+			{
+				Type: api.TargetTypeTeam,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: api.PermissionCheck{
+				NeededPermission: api.Needs(perms.EntityManageRoles),
+				GetTarget: func(d uapi.Route, r *http.Request, authData uapi.AuthData) (string, string) {
+					return api.TargetTypeTeam, chi.URLParam(r, "tid")
+				},
+			},
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/teams/{tid}/roles/{rid}",
+		OpId:    "edit_team_role",
+		Method:  uapi.PATCH,
+		Docs:    edit_team_role.Docs,
+		Handler: edit_team_role.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type: api.TargetTypeUser,
+			},
+			{
+				Type: api.TargetTypeTeam,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: api.PermissionCheck{
+				NeededPermission: api.Needs(perms.EntityManageRoles),
+				GetTarget: func(d uapi.Route, r *http.Request, authData uapi.AuthData) (string, string) {
+					return api.TargetTypeTeam, chi.URLParam(r, "tid")
+				},
+			},
+		},
+	}.Route(r)
+
+	uapi.Route{
+		Pattern: "/teams/{tid}/roles/{rid}",
+		OpId:    "delete_team_role",
+		Method:  uapi.DELETE,
+		Docs:    delete_team_role.Docs,
+		Handler: delete_team_role.Route,
+		Auth: []uapi.AuthType{
+			{
+				Type: api.TargetTypeUser,
+			},
+			{
+				Type: api.TargetTypeTeam,
+			},
+		},
+		ExtData: map[string]any{
+			api.PERMISSION_CHECK_KEY: api.PermissionCheck{
+				NeededPermission: api.Needs(perms.EntityManageRoles),
+				GetTarget: func(d uapi.Route, r *http.Request, authData uapi.AuthData) (string, string) {
+					return api.TargetTypeTeam, chi.URLParam(r, "tid")
+				},
+			},
+		},
+	}.Route(r)
 }
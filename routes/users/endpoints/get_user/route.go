@@ -165,8 +165,8 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		user.UserTeams = append(user.UserTeams, eto)
 	}
 
-	// Packs
-	packsRows, err := state.Pool.Query(d.Context, "SELECT "+packCols+" FROM packs WHERE owner = $1 ORDER BY created_at DESC", user.ID)
+	// Packs, including those the user collaborates on
+	packsRows, err := state.Pool.Query(d.Context, "SELECT "+packCols+" FROM packs WHERE owner = $1 OR url IN (SELECT pack_url FROM pack_collaborators WHERE user_id = $1) ORDER BY created_at DESC", user.ID)
 
 	if err != nil {
 		return resp.Err("Error while getting user packs [db fetch]", err, zap.String("userID", user.ID))
@@ -17,6 +17,7 @@ import (
 	"popplio/votes"
 	"popplio/webhooks/core/drivers"
 	"popplio/webhooks/events"
+	"popplio/widgets"
 
 	"github.com/disgoorg/disgo/discord"
 	docs "github.com/infinitybotlist/eureka/doclib"
@@ -184,6 +185,10 @@ func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
 		return resp.Err("Failed to commit transaction", err, zap.String("userId", d.Auth.ID), zap.String("targetId", targetId), zap.String("targetType", targetType))
 	}
 
+	if err := widgets.Invalidate(d.Context, targetType, targetId); err != nil {
+		state.Logger.Error("Failed to invalidate widgets", zap.Error(err), zap.String("targetId", targetId), zap.String("targetType", targetType))
+	}
+
 	// Fetch user info to log it to server
 	go func() {
 		defer func() {
@@ -0,0 +1,117 @@
+// Package get_widget implements GET /{target_type}/{target_id}/widget.{format}
+// — "Get Widget".
+//
+// Gets an embeddable status widget of a bot, server or team
+package get_widget
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"popplio/api/resp"
+	"popplio/validators"
+	"popplio/widgets"
+
+	"github.com/go-chi/chi/v5"
+	docs "github.com/infinitybotlist/eureka/doclib"
+	"github.com/infinitybotlist/eureka/uapi"
+	"go.uber.org/zap"
+)
+
+func Docs() *docs.Doc {
+	return &docs.Doc{
+		Summary:     "Get Widget",
+		Description: "Gets an embeddable status widget of a bot, server or team, for READMEs and websites. `widget.svg` is an SVG and `widget.png` the same widget drawn at twice the size. Widgets are cached for up to 15 minutes, or until the entity posts stats or is voted for, and support `If-None-Match`.",
+		Params: []docs.Parameter{
+			{
+				Name:        "target_type",
+				Description: "The target type of the entity, one of bots, servers or teams",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "target_id",
+				Description: "The ID of the entity",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "format",
+				Description: "svg or png",
+				Required:    true,
+				In:          "path",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "layout",
+				Description: "badge (a single stat), compact (one line) or card. Defaults to card",
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "theme",
+				Description: "dark or light. Defaults to dark",
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+			{
+				Name:        "stat",
+				Description: "The stat a badge shows. votes, servers or shards for bots, votes, members or online for servers, and votes, bots or servers for teams. Defaults to votes",
+				In:          "query",
+				Schema:      docs.IdSchema,
+			},
+		},
+	}
+}
+
+func Route(d uapi.RouteData, r *http.Request) uapi.HttpResponse {
+	targetId := chi.URLParam(r, "target_id")
+	targetType := validators.NormalizeTargetType(chi.URLParam(r, "target_type"))
+
+	switch targetType {
+	case "bot", "server", "team":
+	default:
+		return resp.BadRequest("Widgets are only available for bots, servers and teams")
+	}
+
+	opts, err := widgets.ParseOptions(targetType, chi.URLParam(r, "format"), r.URL.Query())
+
+	if err != nil {
+		return resp.BadRequest(err.Error())
+	}
+
+	widget, err := widgets.Get(d.Context, targetType, targetId, opts)
+
+	if errors.Is(err, widgets.ErrNotFound) {
+		return uapi.DefaultResponse(http.StatusNotFound)
+	}
+
+	if err != nil {
+		return resp.Err("Error while rendering widget", err, zap.String("targetID", targetId), zap.String("targetType", targetType))
+	}
+
+	etag := widgets.ETag(widget)
+
+	headers := map[string]string{
+		"Content-Type":  opts.Format.ContentType(),
+		"Cache-Control": "public, max-age=300",
+		"ETag":          etag,
+	}
+
+	for _, tag := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if strings.TrimSpace(tag) == etag {
+			return uapi.HttpResponse{
+				Status:  http.StatusNotModified,
+				Headers: headers,
+			}
+		}
+	}
+
+	return uapi.HttpResponse{
+		Bytes:   widget,
+		Headers: headers,
+	}
+}
@@ -0,0 +1,31 @@
+// Package widgets mounts the "Widgets" group of API routes.
+//
+// These API endpoints are related to embeddable status widgets on IBL
+package widgets
+
+import (
+	"popplio/routes/widgets/endpoints/get_widget"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/infinitybotlist/eureka/uapi"
+)
+
+const (
+	tagName = "Widgets"
+)
+
+type Router struct{}
+
+func (b Router) Tag() (string, string) {
+	return tagName, "These API endpoints are related to embeddable status widgets on IBL"
+}
+
+func (b Router) Routes(r *chi.Mux) {
+	uapi.Route{
+		Pattern: "/{target_type}/{target_id}/widget.{format}",
+		OpId:    "get_widget",
+		Method:  uapi.GET,
+		Docs:    get_widget.Docs,
+		Handler: get_widget.Route,
+	}.Route(r)
+}
@@ -0,0 +1,183 @@
+// Package statshistory records bot and server stats over time, so their
+// growth can be charted.
+//
+// Samples are written at minute resolution by RecordBot (from POST
+// /bots/stats) and RecordServer (from the server_member_sync task). Downsample
+// rolls them up into hourly and daily averages and expires old ones: raw
+// samples are kept for 7 days, hourly ones for 90, and daily ones forever.
+package statshistory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"popplio/db"
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	RawRetention    = 7 * 24 * time.Hour
+	HourlyRetention = 90 * 24 * time.Hour
+
+	// MaxPoints caps how many points one history query returns
+	MaxPoints = 5000
+)
+
+// RecordBot samples the bot's current stats. Omitted stats keep their last
+// posted value on the bots row, so the sample is read from there rather than
+// from the post.
+func RecordBot(ctx context.Context, c db.Conn, botID string) error {
+	_, err := c.Exec(
+		ctx,
+		`INSERT INTO entity_stats (target_type, target_id, resolution, bucket, servers, shards, users)
+		SELECT 'bot', bot_id, 'raw', date_trunc('minute', NOW()), servers, shards, users FROM bots WHERE bot_id = $1
+		ON CONFLICT (target_type, target_id, resolution, bucket) DO UPDATE SET
+			servers = EXCLUDED.servers, shards = EXCLUDED.shards, users = EXCLUDED.users`,
+		botID,
+	)
+
+	return err
+}
+
+// RecordServer samples the server's current member counts.
+func RecordServer(ctx context.Context, c db.Conn, serverID string) error {
+	_, err := c.Exec(
+		ctx,
+		`INSERT INTO entity_stats (target_type, target_id, resolution, bucket, total_members, online_members)
+		SELECT 'server', server_id, 'raw', date_trunc('minute', NOW()), total_members, online_members FROM servers WHERE server_id = $1
+		ON CONFLICT (target_type, target_id, resolution, bucket) DO UPDATE SET
+			total_members = EXCLUDED.total_members, online_members = EXCLUDED.online_members`,
+		serverID,
+	)
+
+	return err
+}
+
+// rollup averages every bucket of resolution from into one of resolution to,
+// for buckets of to (unit: a date_trunc field) starting after since. Only
+// complete buckets are rolled up, and rolling one up again replaces it, so
+// the lookback can safely overlap the previous run.
+func rollup(ctx context.Context, from, to, unit string, since time.Duration) error {
+	_, err := state.Pool.Exec(
+		ctx,
+		`INSERT INTO entity_stats (target_type, target_id, resolution, bucket, servers, shards, users, total_members, online_members)
+		SELECT target_type, target_id, $2, date_trunc($3, bucket),
+			ROUND(AVG(servers)), ROUND(AVG(shards)), ROUND(AVG(users)), ROUND(AVG(total_members)), ROUND(AVG(online_members))
+		FROM entity_stats
+		WHERE resolution = $1 AND bucket >= date_trunc($3, NOW() - $4::interval) AND bucket < date_trunc($3, NOW())
+		GROUP BY target_type, target_id, date_trunc($3, bucket)
+		ON CONFLICT (target_type, target_id, resolution, bucket) DO UPDATE SET
+			servers = EXCLUDED.servers, shards = EXCLUDED.shards, users = EXCLUDED.users,
+			total_members = EXCLUDED.total_members, online_members = EXCLUDED.online_members`,
+		from,
+		to,
+		unit,
+		since,
+	)
+
+	return err
+}
+
+// Downsample rolls recent samples up into hourly and daily averages, then
+// deletes raw and hourly samples past their retention.
+func Downsample(ctx context.Context) error {
+	// The lookbacks cover a day of missed runs
+	if err := rollup(ctx, "raw", "hourly", "hour", 24*time.Hour); err != nil {
+		return fmt.Errorf("rolling up hourly: %w", err)
+	}
+
+	if err := rollup(ctx, "hourly", "daily", "day", 48*time.Hour); err != nil {
+		return fmt.Errorf("rolling up daily: %w", err)
+	}
+
+	_, err := state.Pool.Exec(ctx, "DELETE FROM entity_stats WHERE resolution = 'raw' AND bucket < NOW() - $1::interval", RawRetention)
+
+	if err != nil {
+		return fmt.Errorf("expiring raw: %w", err)
+	}
+
+	_, err = state.Pool.Exec(ctx, "DELETE FROM entity_stats WHERE resolution = 'hourly' AND bucket < NOW() - $1::interval", HourlyRetention)
+
+	if err != nil {
+		return fmt.Errorf("expiring hourly: %w", err)
+	}
+
+	return nil
+}
+
+// PickResolution returns the finest resolution still kept for all of
+// [from, to), so a range is never silently cut short.
+func PickResolution(from time.Time) types.StatsResolution {
+	switch age := time.Since(from); {
+	case age <= RawRetention:
+		return types.StatsResolutionRaw
+	case age <= HourlyRetention:
+		return types.StatsResolutionHourly
+	default:
+		return types.StatsResolutionDaily
+	}
+}
+
+// History returns the samples of an entity at resolution in [from, to),
+// oldest first and capped at MaxPoints.
+func History(ctx context.Context, targetType, targetID string, resolution types.StatsResolution, from, to time.Time) ([]types.StatsPoint, error) {
+	rows, err := state.Pool.Query(
+		ctx,
+		`SELECT bucket, servers, shards, users, total_members, online_members FROM entity_stats
+		WHERE target_type = $1 AND target_id = $2 AND resolution = $3 AND bucket >= $4 AND bucket < $5
+		ORDER BY bucket LIMIT $6`,
+		targetType,
+		targetID,
+		resolution,
+		from,
+		to,
+		MaxPoints,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[types.StatsPoint])
+}
+
+// ParseQuery reads the from, to and resolution query parameters of a history
+// endpoint. from and to are RFC 3339 and default to the last 7 days, and
+// resolution defaults to PickResolution. Errors are user facing.
+func ParseQuery(q url.Values) (resolution types.StatsResolution, from, to time.Time, err error) {
+	to = time.Now()
+
+	if v := q.Get("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			return "", from, to, errors.New("to must be an RFC 3339 timestamp")
+		}
+	}
+
+	from = to.Add(-RawRetention)
+
+	if v := q.Get("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			return "", from, to, errors.New("from must be an RFC 3339 timestamp")
+		}
+	}
+
+	if !from.Before(to) {
+		return "", from, to, errors.New("from must be before to")
+	}
+
+	switch resolution = types.StatsResolution(q.Get("resolution")); resolution {
+	case "":
+		resolution = PickResolution(from)
+	case types.StatsResolutionRaw, types.StatsResolutionHourly, types.StatsResolutionDaily:
+	default:
+		return "", from, to, errors.New("resolution must be one of raw, hourly or daily")
+	}
+
+	return resolution, from, to, nil
+}
@@ -0,0 +1,385 @@
+package taxonomy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"popplio/state"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// ErrUser is wrapped by errors that are the fault of the request, and are
+// safe to show to staff as is
+var ErrUser = errors.New("invalid tag operation")
+
+func userErr(format string, a ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrUser}, a...)...)
+}
+
+// entityTables are the tables whose tags columns hold tag IDs
+var entityTables = []string{"bots", "servers", "teams", "packs"}
+
+// retagSavedSearches rewrites the tag filters of saved searches to tag IDs,
+// which is what they are matched against entities as. exp/tagtaxonomy.sql
+// does the same to every saved search.
+const retagSavedSearches = `UPDATE saved_searches SET query = jsonb_set(query, '{tags,tags}', to_jsonb(canonical_tags(ARRAY(SELECT jsonb_array_elements_text(query->'tags'->'tags')))))`
+
+// Edit is a tag being created or edited by staff
+type Edit struct {
+	// ID is the tag being edited. When creating, it defaults to the slug of
+	// Name
+	ID          string
+	Name        string
+	Description string
+	Icon        string
+	TargetTypes []string
+
+	// Aliases replace the tag's aliases
+	Aliases []string
+}
+
+// validate normalizes e, checking aliases against the registry as it would be
+// with e saved
+func (e *Edit) validate(ctx context.Context, tx pgx.Tx) error {
+	if e.Name == "" {
+		return userErr("a tag must have a name")
+	}
+
+	if e.ID == "" {
+		e.ID = Slug(e.Name)
+	}
+
+	if e.ID == "" || e.ID != Slug(e.ID) {
+		return userErr("tag ID %q must be a lowercase slug of letters, digits and dashes", e.ID)
+	}
+
+	if len(e.TargetTypes) == 0 {
+		e.TargetTypes = TargetTypes
+	}
+
+	for _, tt := range e.TargetTypes {
+		if !slices.Contains(TargetTypes, tt) {
+			return userErr("target type %q must be one of %v", tt, TargetTypes)
+		}
+	}
+
+	aliases := make([]string, 0, len(e.Aliases))
+
+	for _, a := range e.Aliases {
+		a = Slug(a)
+
+		if a == "" || a == e.ID || slices.Contains(aliases, a) {
+			continue
+		}
+
+		var owner string
+		err := tx.QueryRow(ctx, "SELECT id FROM tags WHERE id = $1 UNION ALL SELECT tag_id FROM tag_aliases WHERE alias = $1 AND tag_id != $2", a, e.ID).Scan(&owner)
+
+		if err == nil {
+			if owner == a {
+				return userErr("%q is a tag itself, merge it into %q instead", a, e.ID)
+			}
+
+			return userErr("%q is already an alias of %q", a, owner)
+		}
+
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		aliases = append(aliases, a)
+	}
+
+	e.Aliases = aliases
+
+	return nil
+}
+
+func setAliases(ctx context.Context, tx pgx.Tx, id string, aliases []string) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM tag_aliases WHERE tag_id = $1", id); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, "INSERT INTO tag_aliases (alias, tag_id) SELECT unnest($1::TEXT[]), $2", aliases, id)
+	return err
+}
+
+// Create adds a tag to the registry, returning its ID
+func Create(ctx context.Context, e Edit) (string, error) {
+	tx, err := state.Pool.Begin(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := e.validate(ctx, tx); err != nil {
+		return "", err
+	}
+
+	var alias string
+	err = tx.QueryRow(ctx, "SELECT tag_id FROM tag_aliases WHERE alias = $1", e.ID).Scan(&alias)
+
+	if err == nil {
+		return "", userErr("%q is already an alias of %q", e.ID, alias)
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return "", err
+	}
+
+	tag, err := tx.Exec(
+		ctx,
+		"INSERT INTO tags (id, name, description, icon, target_types) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (id) DO NOTHING",
+		e.ID,
+		e.Name,
+		e.Description,
+		e.Icon,
+		e.TargetTypes,
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return "", userErr("tag %q already exists", e.ID)
+	}
+
+	if err := setAliases(ctx, tx, e.ID, e.Aliases); err != nil {
+		return "", err
+	}
+
+	return e.ID, tx.Commit(ctx)
+}
+
+// Update edits a tag. Its ID can only be changed by renaming it.
+func Update(ctx context.Context, e Edit) error {
+	if e.ID == "" {
+		return userErr("the ID of the tag to edit must be given")
+	}
+
+	tx, err := state.Pool.Begin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := e.validate(ctx, tx); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(
+		ctx,
+		"UPDATE tags SET name = $2, description = $3, icon = $4, target_types = $5, updated_at = NOW() WHERE id = $1",
+		e.ID,
+		e.Name,
+		e.Description,
+		e.Icon,
+		e.TargetTypes,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return userErr("tag %q does not exist", e.ID)
+	}
+
+	if err := setAliases(ctx, tx, e.ID, e.Aliases); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// merge makes from an alias of into, rewriting every entity tagged with from
+func merge(ctx context.Context, tx pgx.Tx, from, into string) error {
+	if _, err := tx.Exec(ctx, "UPDATE tag_aliases SET tag_id = $2 WHERE tag_id = $1", from, into); err != nil {
+		return fmt.Errorf("moving aliases: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM tags WHERE id = $1", from); err != nil {
+		return fmt.Errorf("deleting tag: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "INSERT INTO tag_aliases (alias, tag_id) VALUES ($1, $2)", from, into); err != nil {
+		return fmt.Errorf("adding alias: %w", err)
+	}
+
+	for _, table := range entityTables {
+		if _, err := tx.Exec(ctx, "UPDATE "+table+" SET tags = canonical_tags(tags) WHERE $1 = ANY(tags)", from); err != nil {
+			return fmt.Errorf("retagging %s: %w", table, err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, retagSavedSearches+" WHERE query->'tags'->'tags' ? $1", from); err != nil {
+		return fmt.Errorf("retagging saved searches: %w", err)
+	}
+
+	return nil
+}
+
+func exists(ctx context.Context, tx pgx.Tx, id string) error {
+	var ok bool
+
+	if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM tags WHERE id = $1)", id).Scan(&ok); err != nil {
+		return err
+	}
+
+	if !ok {
+		return userErr("tag %q does not exist", id)
+	}
+
+	return nil
+}
+
+// Merge merges the tag from into the tag into. Entities tagged with from are
+// retagged with into, and from and its aliases become aliases of into.
+func Merge(ctx context.Context, from, into string) error {
+	if from == into {
+		return userErr("a tag can't be merged into itself")
+	}
+
+	tx, err := state.Pool.Begin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	for _, id := range []string{from, into} {
+		if err := exists(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	if err := merge(ctx, tx, from, into); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	invalidate(ctx)
+
+	return nil
+}
+
+// Rename renames a tag, changing its ID to the slug of name. Entities are
+// retagged and the old ID becomes an alias, so clients still sending it keep
+// working. It returns the new ID.
+func Rename(ctx context.Context, id, name string) (string, error) {
+	newID := Slug(name)
+
+	if newID == "" {
+		return "", userErr("a tag must have a name")
+	}
+
+	tx, err := state.Pool.Begin(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := exists(ctx, tx, id); err != nil {
+		return "", err
+	}
+
+	if newID == id {
+		if _, err := tx.Exec(ctx, "UPDATE tags SET name = $2, updated_at = NOW() WHERE id = $1", id, name); err != nil {
+			return "", err
+		}
+
+		return id, tx.Commit(ctx)
+	}
+
+	var taken bool
+
+	if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM tags WHERE id = $1)", newID).Scan(&taken); err != nil {
+		return "", err
+	}
+
+	if taken {
+		return "", userErr("tag %q already exists, merge into it instead", newID)
+	}
+
+	// The new ID may have been an alias of this tag, but not of another
+	tag, err := tx.Exec(ctx, "DELETE FROM tag_aliases WHERE alias = $1 AND tag_id = $2", newID, id)
+
+	if err != nil {
+		return "", err
+	}
+
+	if tag.RowsAffected() == 0 {
+		var owner string
+		err := tx.QueryRow(ctx, "SELECT tag_id FROM tag_aliases WHERE alias = $1", newID).Scan(&owner)
+
+		if err == nil {
+			return "", userErr("%q is already an alias of %q", newID, owner)
+		}
+
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return "", err
+		}
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO tags (id, name, description, icon, target_types, retired, created_at)
+		SELECT $2, $3, description, icon, target_types, retired, created_at FROM tags WHERE id = $1`,
+		id,
+		newID,
+		name,
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := merge(ctx, tx, id, newID); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+
+	invalidate(ctx)
+
+	return newID, nil
+}
+
+// SetRetired retires a tag or brings it back
+func SetRetired(ctx context.Context, id string, retired bool) error {
+	tag, err := state.Pool.Exec(ctx, "UPDATE tags SET retired = $2, updated_at = NOW() WHERE id = $1", id, retired)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return userErr("tag %q does not exist", id)
+	}
+
+	return nil
+}
+
+// invalidate drops cached tag usage after entities were retagged
+func invalidate(ctx context.Context) {
+	if err := state.Redis.Del(ctx, ListCacheKey).Err(); err != nil {
+		state.Logger.Error("Failed to invalidate tag list cache", zap.Error(err))
+	}
+}
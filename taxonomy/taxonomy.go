@@ -0,0 +1,202 @@
+// Package taxonomy is the tag registry that the tags of bots, servers, teams
+// and packs are normalized against.
+//
+// Each tag has an ID, the slug of its name, which is what entities store, and
+// may have aliases. Adding a tag accepts its ID, any of its aliases or any
+// spelling of either that slugs to them, so "Music Bot" and "music_bot" both
+// become "music-bot" and an alias such as "musica" becomes "music". A tag may
+// be limited to some target types, and retired tags are kept by entities
+// already using them but can't be newly added.
+//
+// Staff curate the registry from the panel (see ops.go), where merging a tag
+// into another rewrites every entity using it and leaves it behind as an
+// alias.
+package taxonomy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ListCacheKey is where GET /list/tags caches tag usage, which changes to the
+// registry invalidate
+const ListCacheKey = "list_tags"
+
+// TargetTypes are the target types tags can be applicable to
+var TargetTypes = []string{"bot", "server", "team", "pack"}
+
+// Slug returns the ID a tag named tag would have. It must match the tag_slug
+// SQL function.
+func Slug(tag string) string {
+	var b strings.Builder
+
+	dash := false
+
+	for _, r := range strings.ToLower(tag) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+
+		dash = true
+	}
+
+	return b.String()
+}
+
+// Registry is a snapshot of the tag registry
+type Registry struct {
+	tags    map[string]types.Tag
+	aliases map[string]string
+}
+
+// NewRegistry returns a registry of tags
+func NewRegistry(tags []types.Tag) *Registry {
+	reg := &Registry{
+		tags:    make(map[string]types.Tag, len(tags)),
+		aliases: map[string]string{},
+	}
+
+	for _, t := range tags {
+		reg.tags[t.ID] = t
+
+		for _, a := range t.Aliases {
+			reg.aliases[a] = t.ID
+		}
+	}
+
+	return reg
+}
+
+// List returns every tag of the registry, by ID
+func List(ctx context.Context) ([]types.Tag, error) {
+	rows, err := state.Pool.Query(
+		ctx,
+		`SELECT id, name, description, icon, target_types, retired, created_at, updated_at,
+		COALESCE((SELECT array_agg(alias ORDER BY alias) FROM tag_aliases WHERE tag_id = tags.id), '{}') AS aliases
+		FROM tags ORDER BY id`,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[types.Tag])
+}
+
+// Load returns the current registry
+func Load(ctx context.Context) (*Registry, error) {
+	tags, err := List(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("loading tags: %w", err)
+	}
+
+	return NewRegistry(tags), nil
+}
+
+// Resolve returns the tag that tag is, or is an alias of
+func (reg *Registry) Resolve(tag string) (types.Tag, bool) {
+	id := Slug(tag)
+
+	if canonical, ok := reg.aliases[id]; ok {
+		id = canonical
+	}
+
+	t, ok := reg.tags[id]
+	return t, ok
+}
+
+// Canonical returns the IDs tags are stored as on entities, for filtering by
+// them: the tag each is or is an alias of, or else its slug, the same as the
+// canonical_tags SQL function. Duplicates and tags with an empty slug are
+// dropped. Unlike Normalize, unknown tags are not an error.
+func (reg *Registry) Canonical(tags []string) []string {
+	canonical := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		id := Slug(tag)
+
+		if t, ok := reg.Resolve(tag); ok {
+			id = t.ID
+		}
+
+		if id != "" && !slices.Contains(canonical, id) {
+			canonical = append(canonical, id)
+		}
+	}
+
+	return canonical
+}
+
+// Normalize returns the IDs of tags, which are being set on an entity of
+// targetType whose tags are currently current. Duplicates are dropped. Errors
+// are user facing.
+func (reg *Registry) Normalize(targetType string, tags, current []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		t, ok := reg.Resolve(tag)
+
+		if !ok {
+			return nil, fmt.Errorf("%q is not a known tag, see GET /list/tags/registry for the tags that can be used", tag)
+		}
+
+		if t.Retired && !slices.Contains(current, t.ID) {
+			return nil, fmt.Errorf("The %q tag has been retired and can no longer be added", t.Name)
+		}
+
+		if !slices.Contains(t.TargetTypes, targetType) {
+			return nil, fmt.Errorf("The %q tag can't be used on a %s", t.Name, targetType)
+		}
+
+		if !slices.Contains(normalized, t.ID) {
+			normalized = append(normalized, t.ID)
+		}
+	}
+
+	return normalized, nil
+}
+
+// Normalize loads the registry and normalizes tags against it, see
+// Registry.Normalize. userErr is user facing, while err is a failure to load
+// the registry.
+func Normalize(ctx context.Context, targetType string, tags, current []string) (normalized []string, userErr, err error) {
+	reg, err := Load(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	normalized, userErr = reg.Normalize(targetType, tags, current)
+	return normalized, userErr, nil
+}
+
+// Canonical loads the registry and returns the IDs of tags, see
+// Registry.Canonical. The registry is only loaded if there are tags.
+func Canonical(ctx context.Context, tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return []string{}, nil
+	}
+
+	reg, err := Load(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reg.Canonical(tags), nil
+}
@@ -0,0 +1,72 @@
+package taxonomy
+
+import (
+	"slices"
+	"testing"
+
+	"popplio/types"
+)
+
+func TestSlug(t *testing.T) {
+	cases := map[string]string{
+		"music":        "music",
+		"Music Bot":    "music-bot",
+		"  music_bot ": "music-bot",
+		"--Anime!!--":  "anime",
+		"Música":       "música",
+		"24/7":         "24-7",
+		"!!!":          "",
+	}
+
+	for in, want := range cases {
+		if got := Slug(in); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	reg := NewRegistry([]types.Tag{
+		{ID: "music", Name: "Music", TargetTypes: TargetTypes, Aliases: []string{"musica", "music-bot"}},
+		{ID: "moderation", Name: "Moderation", TargetTypes: []string{"bot"}},
+		{ID: "roleplay", Name: "Roleplay", TargetTypes: TargetTypes, Retired: true},
+	})
+
+	got, err := reg.Normalize("bot", []string{"Music Bot", "musica", "Moderation"}, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"music", "moderation"}; !slices.Equal(got, want) {
+		t.Errorf("Normalize = %v, want %v", got, want)
+	}
+
+	if _, err := reg.Normalize("bot", []string{"gaming"}, nil); err == nil {
+		t.Error("unknown tag was accepted")
+	}
+
+	if _, err := reg.Normalize("server", []string{"moderation"}, nil); err == nil {
+		t.Error("bot only tag was accepted on a server")
+	}
+
+	if _, err := reg.Normalize("bot", []string{"roleplay"}, nil); err == nil {
+		t.Error("retired tag was newly added")
+	}
+
+	if _, err := reg.Normalize("bot", []string{"Roleplay"}, []string{"roleplay"}); err != nil {
+		t.Errorf("retired tag already in use was rejected: %v", err)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	reg := NewRegistry([]types.Tag{
+		{ID: "music", Name: "Music", TargetTypes: TargetTypes, Aliases: []string{"music-bot"}},
+	})
+
+	got := reg.Canonical([]string{"Music Bot", "music", "Gaming", "!!!"})
+
+	if want := []string{"music", "gaming"}; !slices.Equal(got, want) {
+		t.Errorf("Canonical = %v, want %v", got, want)
+	}
+}
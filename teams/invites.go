@@ -0,0 +1,366 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"popplio/arcadia/impls"
+	"popplio/notifications"
+	"popplio/perms"
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/google/uuid"
+	"github.com/infinitybotlist/eureka/dovewing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultInviteExpiry is how long an invite lasts when no expiry is given
+	DefaultInviteExpiry = 7 * 24 * time.Hour
+
+	// inviteCodeLength is the length of invite link codes
+	inviteCodeLength = 16
+)
+
+var (
+	// ErrInviteNotFound is returned when an invite doesn't exist, has expired,
+	// has been used up or isn't for the user
+	ErrInviteNotFound = errors.New("invite not found, or it has expired")
+
+	// ErrAlreadyMember is returned when accepting an invite to a team the user
+	// is already a member of
+	ErrAlreadyMember = errors.New("you are already a member of this team")
+
+	// ErrInviteStale is returned when the member who created an invite can no
+	// longer add members with its permissions, so it can't be accepted
+	ErrInviteStale = errors.New("the member who sent this invite can no longer add members with its permissions")
+)
+
+const inviteCols = `i.id::text AS id, i.team_id::text AS team_id, t.name AS team_name, i.user_id, i.code, i.flags,
+	i.invited_by, i.max_uses, i.uses, i.created_at, i.expires_at`
+
+// InviteURL returns the shareable URL of an invite link
+func InviteURL(code string) string {
+	return state.Config.Sites.Frontend.Parse() + "/team/invite/" + code
+}
+
+// CanGrant returns an error if userID may not add members to a team with
+// flags. Invites are checked both when created and when accepted, so an
+// invite from a member who has since lost their permissions can't be used.
+func CanGrant(ctx context.Context, userID, teamID string, flags []string) error {
+	managerPerms, err := GetEntityPerms(ctx, userID, "team", teamID)
+
+	if err != nil {
+		return err
+	}
+
+	if !managerPerms.Has(perms.EntityAddMembers) {
+		return fmt.Errorf("missing permission %s", perms.EntityAddMembers)
+	}
+
+	// Equivalent to going from no perms to the invite's permset
+	return perms.CheckPatch(managerPerms, perms.Entity.NewSet(), perms.Entity.ResolveStrings(flags))
+}
+
+func listInvites(ctx context.Context, where string, args ...any) ([]types.TeamInvite, error) {
+	rows, err := state.Pool.Query(
+		ctx,
+		"SELECT "+inviteCols+" FROM team_invites i JOIN teams t ON t.id = i.team_id WHERE "+where+` AND i.expires_at > NOW()
+		AND (i.max_uses IS NULL OR i.uses < i.max_uses) ORDER BY i.created_at DESC`,
+		args...,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	invites, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.TeamInvite])
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range invites {
+		invites[i].InvitedByUser, err = dovewing.GetUser(ctx, invites[i].InvitedBy, state.DovewingPlatformDiscord)
+
+		if err != nil {
+			return nil, fmt.Errorf("getting inviter: %w", err)
+		}
+
+		if invites[i].Code.Valid {
+			invites[i].URL = InviteURL(invites[i].Code.String)
+		}
+	}
+
+	return invites, nil
+}
+
+// TeamInvites returns a team's pending invites and invite links
+func TeamInvites(ctx context.Context, teamID string) ([]types.TeamInvite, error) {
+	return listInvites(ctx, "i.team_id = $1", teamID)
+}
+
+// UserInvites returns the pending invites sent to a user
+func UserInvites(ctx context.Context, userID string) ([]types.TeamInvite, error) {
+	return listInvites(ctx, "i.user_id = $1", userID)
+}
+
+func firstInvite(invites []types.TeamInvite, err error) (*types.TeamInvite, error) {
+	if err != nil || len(invites) == 0 {
+		return nil, err
+	}
+
+	return &invites[0], nil
+}
+
+// GetInvite returns a pending invite by its ID, or nil if there is none
+func GetInvite(ctx context.Context, id string) (*types.TeamInvite, error) {
+	return firstInvite(listInvites(ctx, "i.id = $1", id))
+}
+
+// InviteLink returns a usable invite link by its code, or nil if there is none
+func InviteLink(ctx context.Context, code string) (*types.TeamInvite, error) {
+	return firstInvite(listInvites(ctx, "i.code = $1", code))
+}
+
+// Invite invites a user to a team, replacing any invite they already have to
+// it, and alerts them. It returns the ID of the invite.
+func Invite(ctx context.Context, teamID, invitedBy, userID string, flags []string, expiry time.Duration) (string, error) {
+	var id string
+	var teamName string
+
+	err := state.Pool.QueryRow(
+		ctx,
+		`INSERT INTO team_invites (team_id, user_id, flags, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (team_id, user_id) WHERE user_id IS NOT NULL DO UPDATE SET
+			id = uuid_generate_v4(),
+			flags = EXCLUDED.flags,
+			invited_by = EXCLUDED.invited_by,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		RETURNING id::text, (SELECT name FROM teams WHERE id = $1)`,
+		teamID,
+		userID,
+		flags,
+		invitedBy,
+		time.Now().Add(expiry),
+	).Scan(&id, &teamName)
+
+	if err != nil {
+		return "", err
+	}
+
+	notify(userID, types.Alert{
+		Type:    types.AlertTypeInfo,
+		URL:     pgtype.Text{String: state.Config.Sites.Frontend.Parse() + "/team/" + teamID, Valid: true},
+		Title:   "Team Invite",
+		Message: fmt.Sprintf("You have been invited to join the team %s. Accept or decline the invite from your team invites.", teamName),
+		AlertData: map[string]any{
+			"team_id":   teamID,
+			"invite_id": id,
+		},
+	})
+
+	return id, nil
+}
+
+// CreateInviteLink creates a shareable invite link to a team, returning its ID
+// and code. maxUses of 0 allows any number of uses until it expires.
+func CreateInviteLink(ctx context.Context, teamID, invitedBy string, flags []string, expiry time.Duration, maxUses int) (string, string, error) {
+	// Codes are bearer secrets, so come from crypto/rand
+	code := impls.GenRandom(inviteCodeLength)
+
+	var id string
+	err := state.Pool.QueryRow(
+		ctx,
+		"INSERT INTO team_invites (team_id, code, flags, invited_by, max_uses, expires_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id::text",
+		teamID,
+		code,
+		flags,
+		invitedBy,
+		pgtype.Int4{Int32: int32(maxUses), Valid: maxUses > 0},
+		time.Now().Add(expiry),
+	).Scan(&id)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return id, code, nil
+}
+
+// AcceptInvite adds a user to a team through an invite, given either the ID
+// of an invite sent to them or the code of an invite link. It returns the ID
+// of the team joined.
+func AcceptInvite(ctx context.Context, userID, idOrCode string) (string, error) {
+	tx, err := state.Pool.Begin(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer tx.Rollback(ctx)
+
+	// Invite links have codes rather than IDs, and anyone may use them
+	var where = "code = $1 AND user_id IS NULL"
+	var args = []any{idOrCode}
+
+	if _, err := uuid.Parse(idOrCode); err == nil {
+		where = "id = $1 AND user_id = $2"
+		args = append(args, userID)
+	}
+
+	var (
+		id        string
+		teamID    string
+		flags     []string
+		invitedBy string
+		isLink    bool
+	)
+
+	err = tx.QueryRow(
+		ctx,
+		`SELECT id::text, team_id::text, flags, invited_by, user_id IS NULL FROM team_invites
+		WHERE `+where+` AND expires_at > NOW() AND (max_uses IS NULL OR uses < max_uses) FOR UPDATE`,
+		args...,
+	).Scan(&id, &teamID, &flags, &invitedBy, &isLink)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrInviteNotFound
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	var isMember bool
+
+	if err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM team_members WHERE team_id = $1 AND user_id = $2)", teamID, userID).Scan(&isMember); err != nil {
+		return "", err
+	}
+
+	if isMember {
+		return "", ErrAlreadyMember
+	}
+
+	if err := CanGrant(ctx, invitedBy, teamID, flags); err != nil {
+		return "", ErrInviteStale
+	}
+
+	if _, err := tx.Exec(ctx, "INSERT INTO team_members (team_id, user_id, flags, service) VALUES ($1, $2, $3, 'api/team_invite')", teamID, userID, flags); err != nil {
+		return "", err
+	}
+
+	if isLink {
+		_, err = tx.Exec(ctx, "UPDATE team_invites SET uses = uses + 1 WHERE id = $1", id)
+	} else {
+		_, err = tx.Exec(ctx, "DELETE FROM team_invites WHERE id = $1", id)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	// An invite sent to the user is moot once they join through a link
+	if _, err := tx.Exec(ctx, "DELETE FROM team_invites WHERE team_id = $1 AND user_id = $2", teamID, userID); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+
+	notifyInviter(ctx, invitedBy, userID, teamID, "Team Invite Accepted", "%s has accepted your invite to join the team %s.")
+
+	return teamID, nil
+}
+
+// DeclineInvite declines an invite sent to a user, alerting whoever sent it
+func DeclineInvite(ctx context.Context, userID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrInviteNotFound
+	}
+
+	var teamID, invitedBy string
+	err := state.Pool.QueryRow(
+		ctx,
+		"DELETE FROM team_invites WHERE id = $1 AND user_id = $2 AND expires_at > NOW() RETURNING team_id::text, invited_by",
+		id,
+		userID,
+	).Scan(&teamID, &invitedBy)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrInviteNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	notifyInviter(ctx, invitedBy, userID, teamID, "Team Invite Declined", "%s has declined your invite to join the team %s.")
+
+	return nil
+}
+
+// RevokeInvite deletes one of a team's invites or invite links, reporting
+// whether it existed
+func RevokeInvite(ctx context.Context, teamID, id string) (bool, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return false, nil
+	}
+
+	tag, err := state.Pool.Exec(ctx, "DELETE FROM team_invites WHERE id = $1 AND team_id = $2", id, teamID)
+
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ExpireInvites deletes invites that have expired or been used up
+func ExpireInvites(ctx context.Context) error {
+	_, err := state.Pool.Exec(ctx, "DELETE FROM team_invites WHERE expires_at <= NOW() OR (max_uses IS NOT NULL AND uses >= max_uses)")
+	return err
+}
+
+func notifyInviter(ctx context.Context, invitedBy, userID, teamID, title, message string) {
+	var teamName string
+
+	if err := state.Pool.QueryRow(ctx, "SELECT name FROM teams WHERE id = $1", teamID).Scan(&teamName); err != nil {
+		state.Logger.Error("Failed to get team for invite alert", zap.Error(err), zap.String("tid", teamID))
+		return
+	}
+
+	name := userID
+	user, err := dovewing.GetUser(ctx, userID, state.DovewingPlatformDiscord)
+
+	if err != nil {
+		state.Logger.Error("Failed to get user for invite alert", zap.Error(err), zap.String("uid", userID))
+	} else {
+		name = user.Username
+	}
+
+	notify(invitedBy, types.Alert{
+		Type:    types.AlertTypeInfo,
+		URL:     pgtype.Text{String: state.Config.Sites.Frontend.Parse() + "/team/" + teamID, Valid: true},
+		Title:   title,
+		Message: fmt.Sprintf(message, name, teamName),
+		AlertData: map[string]any{
+			"team_id": teamID,
+			"user_id": userID,
+		},
+	})
+}
+
+func notify(userID string, alert types.Alert) {
+	if err := notifications.PushNotification(userID, alert); err != nil {
+		state.Logger.Error("Failed to send team invite alert", zap.Error(err), zap.String("uid", userID))
+	}
+}
@@ -15,7 +15,6 @@ import (
 	"popplio/perms"
 	"popplio/state"
 
-	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -90,22 +89,12 @@ func GetEntityPerms(ctx context.Context, userId, targetType, targetId string) (p
 	}
 
 	// Handle teams
-	if _, err := uuid.Parse(teamId); err != nil {
-		return perms.Set{}, fmt.Errorf("invalid team id")
-	}
-
-	// Get the team member from the team
-	var teamPerms []string
-	err := state.Pool.QueryRow(ctx, "SELECT flags FROM team_members WHERE team_id = $1 AND user_id = $2", teamId, userId).Scan(&teamPerms)
-
-	if errors.Is(err, pgx.ErrNoRows) {
-		return perms.Set{}, nil
-	}
+	g, _, err := LoadMember(ctx, teamId, userId)
 
 	if err != nil {
-		return perms.Set{}, fmt.Errorf("error finding team member: %v", err)
+		return perms.Set{}, err
 	}
 
-	// Teams have no roles, so a member's flags are the whole source
-	return perms.Entity.ResolveStrings(teamPerms), nil
+	// A member's roles plus their flags
+	return g.Resolve(), nil
 }
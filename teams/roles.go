@@ -0,0 +1,145 @@
+package teams
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"popplio/perms"
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// MaxRoles is how many roles a team can have
+const MaxRoles = 25
+
+const memberQuery = `SELECT
+	tm.flags,
+	COALESCE((
+		SELECT json_agg(json_build_object('id', tr.id::text, 'name', tr.name, 'index', tr.position, 'perms', tr.perms) ORDER BY tr.position)
+		FROM team_roles tr
+		WHERE tr.id = ANY(tm.roles) AND tr.team_id = tm.team_id
+	), '[]'::json)
+FROM team_members tm
+WHERE tm.team_id = $1 AND tm.user_id = $2`
+
+// MemberGrants is where a team member's permissions come from: the team's
+// roles they have been given, plus the extras in their flags.
+type MemberGrants struct {
+	Roles  []perms.Role
+	Extras []perms.Perm
+}
+
+// Resolve is every permission the member has
+func (g MemberGrants) Resolve() perms.Set {
+	return perms.Entity.Resolve(g.Roles, g.Extras...)
+}
+
+// Rank is the member's seniority: the position of their most senior role,
+// lower being more senior. Owners outrank every role and a member with no
+// roles ranks below them all.
+func (g MemberGrants) Rank() int32 {
+	if perms.Entity.NewSet(g.Extras...).Has(perms.EntityOwner) {
+		return perms.OwnerRank
+	}
+
+	rank := perms.NoRank
+
+	for _, r := range g.Roles {
+		rank = min(rank, r.Index)
+	}
+
+	return rank
+}
+
+// CanManage is whether the member may change target's permissions and roles
+// at all, which they may unless target outranks them. Members of the same
+// rank, such as two members with no roles or two owners, are left to
+// perms.CheckPatch.
+func (g MemberGrants) CanManage(target MemberGrants) bool {
+	return target.Rank() >= g.Rank()
+}
+
+type teamRoleJSON struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	Index int32    `json:"index"`
+	Perms []string `json:"perms"`
+}
+
+// LoadMember returns where a team member's permissions come from. A user who
+// isn't a member of the team has no grants, and ok is false.
+func LoadMember(ctx context.Context, teamID, userID string) (g MemberGrants, ok bool, err error) {
+	if _, err := uuid.Parse(teamID); err != nil {
+		return MemberGrants{}, false, fmt.Errorf("invalid team id")
+	}
+
+	var (
+		flags []string
+		roles []byte
+	)
+
+	err = state.Pool.QueryRow(ctx, memberQuery, teamID, userID).Scan(&flags, &roles)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return MemberGrants{}, false, nil
+	}
+
+	if err != nil {
+		return MemberGrants{}, false, fmt.Errorf("error finding team member: %v", err)
+	}
+
+	var rows []teamRoleJSON
+
+	if err := json.Unmarshal(roles, &rows); err != nil {
+		return MemberGrants{}, false, fmt.Errorf("error finding team member roles: %v", err)
+	}
+
+	g = MemberGrants{
+		Roles:  make([]perms.Role, 0, len(rows)),
+		Extras: perms.ParseStrings(flags),
+	}
+
+	for _, row := range rows {
+		g.Roles = append(g.Roles, perms.Role{
+			ID:    row.ID,
+			Name:  row.Name,
+			Index: row.Index,
+			Perms: perms.ParseStrings(row.Perms),
+		})
+	}
+
+	return g, true, nil
+}
+
+// GetRoles returns a team's roles, most senior first
+func GetRoles(ctx context.Context, teamID string) ([]types.TeamRole, error) {
+	rows, err := state.Pool.Query(ctx, "SELECT id, team_id, name, color, position, perms, created_at, updated_at FROM team_roles WHERE team_id = $1 ORDER BY position", teamID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[types.TeamRole])
+}
+
+// ValidateRolePerms checks the permissions of a role. Owner can only be given
+// to members directly, so that a team's owners are always its members with
+// Owner in their flags.
+func ValidateRolePerms(list []string) error {
+	for _, p := range list {
+		if !IsValidPerm(p) {
+			return fmt.Errorf("invalid permission: %s", p)
+		}
+
+		if perms.Perm(p) == perms.EntityOwner {
+			return fmt.Errorf("the %s permission can only be given to members directly, not through a role", perms.EntityOwner)
+		}
+	}
+
+	return nil
+}
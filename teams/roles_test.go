@@ -0,0 +1,86 @@
+package teams
+
+import (
+	"testing"
+
+	"popplio/perms"
+)
+
+func TestMemberGrantsResolve(t *testing.T) {
+	g := MemberGrants{
+		Roles: []perms.Role{
+			{ID: "a", Index: 2, Perms: []perms.Perm{perms.EntityEditBots}},
+			{ID: "b", Index: 5, Perms: []perms.Perm{perms.EntityAddBots}},
+		},
+		Extras: []perms.Perm{perms.EntityViewWebhooks},
+	}
+
+	set := g.Resolve()
+
+	if !set.HasAll(perms.EntityEditBots, perms.EntityAddBots, perms.EntityViewWebhooks) {
+		t.Errorf("expected the roles' permissions and the extras, got %v", set.Strings())
+	}
+
+	if set.Has(perms.EntityDeleteBots) {
+		t.Error("a permission from no source should not resolve")
+	}
+}
+
+func TestMemberGrantsRank(t *testing.T) {
+	cases := []struct {
+		name string
+		g    MemberGrants
+		want int32
+	}{
+		{"no roles", MemberGrants{}, perms.NoRank},
+		{"most senior role", MemberGrants{Roles: []perms.Role{{Index: 4}, {Index: 1}, {Index: 3}}}, 1},
+		{"owner", MemberGrants{Roles: []perms.Role{{Index: 0}}, Extras: []perms.Perm{perms.EntityOwner}}, perms.OwnerRank},
+	}
+
+	for _, c := range cases {
+		if got := c.g.Rank(); got != c.want {
+			t.Errorf("%s: expected rank %d, got %d", c.name, c.want, got)
+		}
+	}
+}
+
+func TestMemberGrantsCanManage(t *testing.T) {
+	roleless := MemberGrants{Extras: []perms.Perm{perms.EntityEditMembers}}
+	owner := MemberGrants{Extras: []perms.Perm{perms.EntityOwner}}
+	senior := MemberGrants{Roles: []perms.Role{{Index: 1}}}
+	junior := MemberGrants{Roles: []perms.Role{{Index: 3}}}
+
+	cases := []struct {
+		name            string
+		manager, target MemberGrants
+		want            bool
+	}{
+		{"role-less manager, role-less member", roleless, MemberGrants{}, true},
+		{"owner, co-owner", owner, owner, true},
+		{"same role", junior, junior, true},
+		{"senior role, junior role", senior, junior, true},
+		{"junior role, senior role", junior, senior, false},
+		{"role-less manager, member with a role", roleless, junior, false},
+		{"senior role, owner", senior, owner, false},
+	}
+
+	for _, c := range cases {
+		if got := c.manager.CanManage(c.target); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestValidateRolePerms(t *testing.T) {
+	if err := ValidateRolePerms([]string{string(perms.EntityEditBots)}); err != nil {
+		t.Errorf("expected a valid permission to pass, got %v", err)
+	}
+
+	if err := ValidateRolePerms([]string{string(perms.EntityOwner)}); err == nil {
+		t.Error("expected owner to be refused on a role")
+	}
+
+	if err := ValidateRolePerms([]string{"bot.add"}); err == nil {
+		t.Error("expected an undeclared permission to be refused")
+	}
+}
@@ -0,0 +1,562 @@
+// Package transfers moves entities between owners with the recipient's
+// consent.
+//
+// An owner proposes a transfer, which the new owner has until it expires to
+// accept or decline. Nothing changes hands until they accept, and a transfer
+// is void if the entity has changed hands, or its proposer lost the right to
+// transfer it, in the meantime.
+package transfers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"popplio/api"
+	"popplio/notifications"
+	"popplio/perms"
+	"popplio/state"
+	"popplio/teams"
+	"popplio/types"
+	"popplio/validators"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/google/uuid"
+	"github.com/infinitybotlist/eureka/dovewing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// DefaultBotTransferExpiry is how long a bot transfer lasts when no expiry is
+// given
+const DefaultBotTransferExpiry = 72 * time.Hour
+
+var (
+	// ErrTransferNotFound is returned when a transfer doesn't exist, has
+	// expired or can't be accepted by the user
+	ErrTransferNotFound = errors.New("transfer not found, or it has expired")
+
+	// ErrNewOwnerNotFound is returned when proposing a transfer to a user or
+	// team that doesn't exist
+	ErrNewOwnerNotFound = errors.New("the new owner does not exist")
+
+	// ErrAlreadyOwner is returned when proposing a transfer to whoever already
+	// owns the bot
+	ErrAlreadyOwner = errors.New("the bot is already owned by the new owner")
+
+	// ErrTransferStale is returned when accepting a transfer of a bot that has
+	// changed hands, or whose proposer can no longer transfer it, since the
+	// transfer was proposed
+	ErrTransferStale = errors.New("the bot has changed hands, or whoever proposed the transfer can no longer transfer it")
+)
+
+const botTransferCols = `t.id::text AS id, t.bot_id, t.from_user, t.from_team::text AS from_team, t.to_user,
+	t.to_team::text AS to_team, t.proposed_by, t.created_at, t.expires_at`
+
+// CanTransferBot returns an error if userID may not transfer a bot. Only its
+// owner, or an owner of the team that owns it, may.
+func CanTransferBot(ctx context.Context, userID, botID string) error {
+	entityPerms, err := teams.GetEntityPerms(ctx, userID, "bot", botID)
+
+	if err != nil {
+		return err
+	}
+
+	if !entityPerms.Has(perms.EntityOwner) {
+		return fmt.Errorf("missing permission %s", perms.EntityOwner)
+	}
+
+	return nil
+}
+
+// canReceiveBot returns an error if userID may not accept or decline a bot
+// transfer for its recipient. A team's bots are added by its members who can
+// add bots, so the same members decide on transfers to it.
+func canReceiveBot(ctx context.Context, userID string, t *types.BotTransfer) error {
+	if t.ToUser.Valid {
+		if t.ToUser.String != userID {
+			return ErrTransferNotFound
+		}
+
+		return nil
+	}
+
+	entityPerms, err := teams.GetEntityPerms(ctx, userID, "team", t.ToTeam.String)
+
+	if err != nil || !entityPerms.Has(perms.EntityAddBots) {
+		return ErrTransferNotFound
+	}
+
+	return nil
+}
+
+func listBotTransfers(ctx context.Context, where string, args ...any) ([]types.BotTransfer, error) {
+	rows, err := state.Pool.Query(
+		ctx,
+		"SELECT "+botTransferCols+" FROM bot_transfers t WHERE "+where+" AND t.expires_at > NOW() ORDER BY t.created_at DESC",
+		args...,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transfers, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.BotTransfer])
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range transfers {
+		transfers[i].Bot, err = dovewing.GetUser(ctx, transfers[i].BotID, state.DovewingPlatformDiscord)
+
+		if err != nil {
+			return nil, fmt.Errorf("getting bot: %w", err)
+		}
+
+		transfers[i].ProposedByUser, err = dovewing.GetUser(ctx, transfers[i].ProposedBy, state.DovewingPlatformDiscord)
+
+		if err != nil {
+			return nil, fmt.Errorf("getting proposer: %w", err)
+		}
+	}
+
+	return transfers, nil
+}
+
+// GetBotTransfer returns a pending bot transfer by its ID, or nil if there is
+// none
+func GetBotTransfer(ctx context.Context, id string) (*types.BotTransfer, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+
+	transfers, err := listBotTransfers(ctx, "t.id = $1", id)
+
+	if err != nil || len(transfers) == 0 {
+		return nil, err
+	}
+
+	return &transfers[0], nil
+}
+
+// UserBotTransfers returns the bot transfers a user can accept or decline, and
+// those they have proposed
+func UserBotTransfers(ctx context.Context, userID string) (*types.BotTransferList, error) {
+	candidates, err := listBotTransfers(
+		ctx,
+		"(t.to_user = $1 OR t.to_team IN (SELECT team_id FROM team_members WHERE user_id = $1))",
+		userID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := &types.BotTransferList{
+		Incoming: []types.BotTransfer{},
+	}
+
+	for i := range candidates {
+		if canReceiveBot(ctx, userID, &candidates[i]) == nil {
+			list.Incoming = append(list.Incoming, candidates[i])
+		}
+	}
+
+	list.Outgoing, err = listBotTransfers(ctx, "t.proposed_by = $1", userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ProposeBotTransfer proposes transferring a bot to a user or team, replacing
+// any transfer of it already pending, and alerts the new owner. The caller
+// must have checked CanTransferBot.
+func ProposeBotTransfer(ctx context.Context, botID, proposedBy, toType, toID string, expiry time.Duration) (*types.BotTransfer, error) {
+	var owner, teamOwner pgtype.Text
+
+	err := state.Pool.QueryRow(ctx, "SELECT owner, team_owner::text FROM bots WHERE bot_id = $1", botID).Scan(&owner, &teamOwner)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var toUser, toTeam pgtype.Text
+	var exists bool
+
+	switch toType {
+	case "user":
+		if owner.Valid && owner.String == toID {
+			return nil, ErrAlreadyOwner
+		}
+
+		toUser = pgtype.Text{String: toID, Valid: true}
+		err = state.Pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)", toID).Scan(&exists)
+	case "team":
+		if _, err := uuid.Parse(toID); err != nil {
+			return nil, ErrNewOwnerNotFound
+		}
+
+		if teamOwner.Valid && teamOwner.String == toID {
+			return nil, ErrAlreadyOwner
+		}
+
+		toTeam = pgtype.Text{String: toID, Valid: true}
+		err = state.Pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM teams WHERE id = $1)", toID).Scan(&exists)
+	default:
+		return nil, fmt.Errorf("invalid new owner type %q", toType)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, ErrNewOwnerNotFound
+	}
+
+	var id string
+	err = state.Pool.QueryRow(
+		ctx,
+		`INSERT INTO bot_transfers (bot_id, from_user, from_team, to_user, to_team, proposed_by, expires_at)
+		VALUES ($1, $2, $3::text::uuid, $4, $5::text::uuid, $6, $7)
+		ON CONFLICT (bot_id) DO UPDATE SET
+			id = uuid_generate_v4(),
+			from_user = EXCLUDED.from_user,
+			from_team = EXCLUDED.from_team,
+			to_user = EXCLUDED.to_user,
+			to_team = EXCLUDED.to_team,
+			proposed_by = EXCLUDED.proposed_by,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		RETURNING id::text`,
+		botID,
+		owner,
+		teamOwner,
+		toUser,
+		toTeam,
+		proposedBy,
+		time.Now().Add(expiry),
+	).Scan(&id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := GetBotTransfer(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if t == nil {
+		return nil, ErrTransferNotFound
+	}
+
+	for _, userID := range recipients(ctx, t) {
+		notify(userID, types.Alert{
+			Type:    types.AlertTypeInfo,
+			URL:     pgtype.Text{String: state.Config.Sites.Frontend.Parse() + "/bots/" + botID, Valid: true},
+			Title:   "Bot Transfer",
+			Message: fmt.Sprintf("%s wants to transfer the bot %s to %s. Accept or decline it from your bot transfers before it expires.", t.ProposedByUser.Username, t.Bot.Username, ownerName(ctx, toUser, toTeam, "you")),
+			AlertData: map[string]any{
+				"bot_id":      botID,
+				"transfer_id": id,
+			},
+		})
+	}
+
+	return t, nil
+}
+
+// CancelBotTransfer deletes the pending transfer of a bot, reporting whether
+// there was one
+func CancelBotTransfer(ctx context.Context, botID string) (bool, error) {
+	tag, err := state.Pool.Exec(ctx, "DELETE FROM bot_transfers WHERE bot_id = $1", botID)
+
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// AcceptBotTransfer transfers a bot to its new owner on behalf of userID.
+//
+// The bot's API sessions are revoked, as its previous owners hold their
+// tokens, and its webhooks are marked broken so they stop sending to the
+// previous owners' endpoints until the new owner edits them. Both parties and
+// the mod log are told.
+func AcceptBotTransfer(ctx context.Context, userID, id string) (*types.BotTransfer, error) {
+	t, err := GetBotTransfer(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if t == nil {
+		return nil, ErrTransferNotFound
+	}
+
+	if err := canReceiveBot(ctx, userID, t); err != nil {
+		return nil, err
+	}
+
+	tx, err := state.Pool.Begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	// Locked so the transfer can't be accepted twice, or the bot moved by
+	// anything else, at once
+	_, err = tx.Exec(ctx, "SELECT 1 FROM bot_transfers WHERE id = $1 FOR UPDATE", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stillPending bool
+
+	if err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM bot_transfers WHERE id = $1 AND expires_at > NOW())", id).Scan(&stillPending); err != nil {
+		return nil, err
+	}
+
+	if !stillPending {
+		return nil, ErrTransferNotFound
+	}
+
+	var owner, teamOwner pgtype.Text
+
+	if err := tx.QueryRow(ctx, "SELECT owner, team_owner::text FROM bots WHERE bot_id = $1 FOR UPDATE", t.BotID).Scan(&owner, &teamOwner); err != nil {
+		return nil, err
+	}
+
+	if owner != t.FromUser || teamOwner != t.FromTeam || CanTransferBot(ctx, t.ProposedBy, t.BotID) != nil {
+		if _, err := tx.Exec(ctx, "DELETE FROM bot_transfers WHERE id = $1", id); err != nil {
+			return nil, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrTransferStale
+	}
+
+	if t.ToUser.Valid {
+		_, err = tx.Exec(ctx, "UPDATE bots SET owner = $1, team_owner = NULL WHERE bot_id = $2", t.ToUser.String, t.BotID)
+	} else {
+		_, err = tx.Exec(ctx, "UPDATE bots SET team_owner = $1, owner = NULL WHERE bot_id = $2", t.ToTeam.String, t.BotID)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM api_sessions WHERE target_type = 'bot' AND target_id = $1", t.BotID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE webhooks SET broken = true WHERE target_type = 'bot' AND target_id = $1", t.BotID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM bot_transfers WHERE id = $1", id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	api.InvalidateSessionCache(ctx, api.TargetTypeBot, t.BotID)
+
+	from := ownerName(ctx, t.FromUser, t.FromTeam, "")
+	to := ownerName(ctx, t.ToUser, t.ToTeam, "")
+
+	for _, uid := range uniq(append(recipients(ctx, t), t.ProposedBy)) {
+		notify(uid, types.Alert{
+			Type:    types.AlertTypeSuccess,
+			URL:     pgtype.Text{String: state.Config.Sites.Frontend.Parse() + "/bots/" + t.BotID, Valid: true},
+			Title:   "Bot Transferred",
+			Message: fmt.Sprintf("The bot %s has been transferred from %s to %s. Its API tokens have been revoked and its webhooks paused until they are edited.", t.Bot.Username, from, to),
+			AlertData: map[string]any{
+				"bot_id":      t.BotID,
+				"transfer_id": id,
+			},
+		})
+	}
+
+	_, err = state.Discord.Rest().CreateMessage(state.Config.Channels.ModLogs, discord.MessageCreate{
+		Embeds: []discord.Embed{
+			{
+				URL:   state.Config.Sites.Frontend.Parse() + "/bots/" + t.BotID,
+				Title: "Bot Ownership Transferred!",
+				Fields: []discord.EmbedField{
+					{
+						Name:   "Bot",
+						Value:  fmt.Sprintf("<@%s>", t.BotID),
+						Inline: validators.TruePtr,
+					},
+					{
+						Name:   "Proposed By",
+						Value:  fmt.Sprintf("<@%s>", t.ProposedBy),
+						Inline: validators.TruePtr,
+					},
+					{
+						Name:   "Accepted By",
+						Value:  fmt.Sprintf("<@%s>", userID),
+						Inline: validators.TruePtr,
+					},
+					{
+						Name:  "Old Owner",
+						Value: ownerMention(t.FromUser, t.FromTeam),
+					},
+					{
+						Name:  "New Owner",
+						Value: ownerMention(t.ToUser, t.ToTeam),
+					},
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		state.Logger.Error("Failed to send bot transfer mod log", zap.Error(err), zap.String("botID", t.BotID))
+	}
+
+	return t, nil
+}
+
+// DeclineBotTransfer declines a bot transfer on behalf of userID, alerting
+// whoever proposed it
+func DeclineBotTransfer(ctx context.Context, userID, id string) error {
+	t, err := GetBotTransfer(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if t == nil {
+		return ErrTransferNotFound
+	}
+
+	if err := canReceiveBot(ctx, userID, t); err != nil {
+		return err
+	}
+
+	tag, err := state.Pool.Exec(ctx, "DELETE FROM bot_transfers WHERE id = $1", id)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrTransferNotFound
+	}
+
+	notify(t.ProposedBy, types.Alert{
+		Type:    types.AlertTypeInfo,
+		URL:     pgtype.Text{String: state.Config.Sites.Frontend.Parse() + "/bots/" + t.BotID, Valid: true},
+		Title:   "Bot Transfer Declined",
+		Message: fmt.Sprintf("%s has declined the transfer of the bot %s.", ownerName(ctx, t.ToUser, t.ToTeam, ""), t.Bot.Username),
+		AlertData: map[string]any{
+			"bot_id":      t.BotID,
+			"transfer_id": id,
+		},
+	})
+
+	return nil
+}
+
+// ExpireBotTransfers deletes bot transfers that have expired
+func ExpireBotTransfers(ctx context.Context) error {
+	_, err := state.Pool.Exec(ctx, "DELETE FROM bot_transfers WHERE expires_at <= NOW()")
+	return err
+}
+
+// recipients are the users alerted about a transfer to its new owner: the
+// user, or the owners of the team
+func recipients(ctx context.Context, t *types.BotTransfer) []string {
+	if t.ToUser.Valid {
+		return []string{t.ToUser.String}
+	}
+
+	rows, err := state.Pool.Query(ctx, "SELECT user_id FROM team_members WHERE team_id = $1 AND $2 = ANY(flags)", t.ToTeam.String, string(perms.EntityOwner))
+
+	if err != nil {
+		state.Logger.Error("Failed to get team owners for bot transfer alert", zap.Error(err), zap.String("tid", t.ToTeam.String))
+		return nil
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+
+	if err != nil {
+		state.Logger.Error("Failed to get team owners for bot transfer alert", zap.Error(err), zap.String("tid", t.ToTeam.String))
+		return nil
+	}
+
+	return ids
+}
+
+// ownerName describes a user or team in an alert, with self standing in for
+// a user being told about themselves
+func ownerName(ctx context.Context, user, team pgtype.Text, self string) string {
+	if team.Valid {
+		var name string
+
+		if err := state.Pool.QueryRow(ctx, "SELECT name FROM teams WHERE id = $1", team.String).Scan(&name); err != nil {
+			return "a team"
+		}
+
+		return "the team " + name
+	}
+
+	if self != "" {
+		return self
+	}
+
+	u, err := dovewing.GetUser(ctx, user.String, state.DovewingPlatformDiscord)
+
+	if err != nil {
+		return user.String
+	}
+
+	return u.Username
+}
+
+func ownerMention(user, team pgtype.Text) string {
+	if team.Valid {
+		return fmt.Sprintf("[View Team](%s/team/%s)", state.Config.Sites.Frontend.Parse(), team.String)
+	}
+
+	return fmt.Sprintf("<@%s>", user.String)
+}
+
+func uniq(ids []string) []string {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+
+	return out
+}
+
+func notify(userID string, alert types.Alert) {
+	if err := notifications.PushNotification(userID, alert); err != nil {
+		state.Logger.Error("Failed to send transfer alert", zap.Error(err), zap.String("uid", userID))
+	}
+}
@@ -0,0 +1,378 @@
+package transfers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"popplio/api"
+	"popplio/perms"
+	"popplio/state"
+	"popplio/teams"
+	"popplio/types"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrNotMember is returned when handing a team over to a user who isn't
+	// a member of it
+	ErrNotMember = errors.New("the new owner must be a member of the team")
+
+	// ErrAlreadyTeamOwner is returned when handing a team over to one of its
+	// owners
+	ErrAlreadyTeamOwner = errors.New("the new owner is already an owner of the team")
+
+	// ErrTeamNotFound is returned when dissolving a team that doesn't exist
+	ErrTeamNotFound = errors.New("team not found")
+
+	// ErrInvalidDestination is wrapped by the errors returned when the
+	// destination of a dissolved team's bots or servers can't be used
+	ErrInvalidDestination = errors.New("invalid destination")
+)
+
+// TransferTeamOwnership makes toUserID, a member of the team, an owner of it
+// in place of fromUserID, who either stays on as a member with their other
+// permissions or leaves the team.
+func TransferTeamOwnership(ctx context.Context, teamID, fromUserID, toUserID string, leave bool) error {
+	if fromUserID == toUserID {
+		return ErrAlreadyTeamOwner
+	}
+
+	tx, err := state.Pool.Begin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	// Locked so two owners can't hand the team over to each other at once
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM teams WHERE id = $1 FOR UPDATE", teamID); err != nil {
+		return err
+	}
+
+	var fromFlags, toFlags []string
+
+	err = tx.QueryRow(ctx, "SELECT flags FROM team_members WHERE team_id = $1 AND user_id = $2", teamID, fromUserID).Scan(&fromFlags)
+
+	if err != nil {
+		return fmt.Errorf("error finding current owner: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, "SELECT flags FROM team_members WHERE team_id = $1 AND user_id = $2", teamID, toUserID).Scan(&toFlags)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotMember
+	}
+
+	if err != nil {
+		return fmt.Errorf("error finding new owner: %w", err)
+	}
+
+	// Owner can only be given directly, so it is always in an owner's flags
+	if perms.Entity.NewSet(perms.ParseStrings(toFlags)...).Has(perms.EntityOwner) {
+		return ErrAlreadyTeamOwner
+	}
+
+	newToFlags := append(perms.ParseStrings(toFlags), perms.EntityOwner)
+
+	if _, err := tx.Exec(ctx, "UPDATE team_members SET flags = $1 WHERE team_id = $2 AND user_id = $3", perms.Strings(newToFlags), teamID, toUserID); err != nil {
+		return err
+	}
+
+	if leave {
+		_, err = tx.Exec(ctx, "DELETE FROM team_members WHERE team_id = $1 AND user_id = $2", teamID, fromUserID)
+	} else {
+		var newFromFlags []perms.Perm
+
+		for _, p := range perms.ParseStrings(fromFlags) {
+			if p != perms.EntityOwner {
+				newFromFlags = append(newFromFlags, p)
+			}
+		}
+
+		_, err = tx.Exec(ctx, "UPDATE team_members SET flags = $1 WHERE team_id = $2 AND user_id = $3", perms.Strings(newFromFlags), teamID, fromUserID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var teamName string
+
+	if err := tx.QueryRow(ctx, "SELECT name FROM teams WHERE id = $1", teamID).Scan(&teamName); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	notify(toUserID, types.Alert{
+		Type:    types.AlertTypeInfo,
+		URL:     pgtype.Text{String: state.Config.Sites.Frontend.Parse() + "/team/" + teamID, Valid: true},
+		Title:   "Team Ownership Transferred",
+		Message: fmt.Sprintf("You are now an owner of the team %s.", teamName),
+		AlertData: map[string]any{
+			"team_id": teamID,
+		},
+	})
+
+	return nil
+}
+
+// TeamEntities returns the IDs of the bots and servers a team owns
+func TeamEntities(ctx context.Context, teamID string) (bots, servers []string, err error) {
+	rows, err := state.Pool.Query(ctx, "SELECT bot_id FROM bots WHERE team_owner = $1", teamID)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bots, err = pgx.CollectRows(rows, pgx.RowTo[string])
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err = state.Pool.Query(ctx, "SELECT server_id FROM servers WHERE team_owner = $1", teamID)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	servers, err = pgx.CollectRows(rows, pgx.RowTo[string])
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return bots, servers, nil
+}
+
+// checkDestination returns an error wrapping ErrInvalidDestination if
+// userID may not send a dissolved team's entities of targetType to dest. A
+// user destination must be a member of the team, so nothing is handed to
+// someone with no part in it, and only bots can be owned by a user. A team
+// destination must be one userID can add them to.
+func checkDestination(ctx context.Context, userID, teamID, targetType string, dest *types.TeamEntityDestination) error {
+	switch dest.Type {
+	case "release":
+		return nil
+	case "user":
+		if targetType != "bot" {
+			return fmt.Errorf("%w: %ss can only be owned by a team", ErrInvalidDestination, targetType)
+		}
+
+		var isMember bool
+
+		if err := state.Pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM team_members WHERE team_id = $1 AND user_id = $2)", teamID, dest.ID).Scan(&isMember); err != nil {
+			return err
+		}
+
+		if !isMember {
+			return fmt.Errorf("%w: %ss can only be given to a member of the team", ErrInvalidDestination, targetType)
+		}
+
+		return nil
+	case "team":
+		if dest.ID == teamID {
+			return fmt.Errorf("%w: %ss can't be moved to the team being deleted", ErrInvalidDestination, targetType)
+		}
+
+		add, _, _, _ := perms.EntityLifecycle(targetType)
+
+		entityPerms, err := teams.GetEntityPerms(ctx, userID, "team", dest.ID)
+
+		if err != nil || !entityPerms.Has(add) {
+			return fmt.Errorf("%w: you must be able to add %ss to the team they are moved to", ErrInvalidDestination, targetType)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown destination type %q", ErrInvalidDestination, dest.Type)
+	}
+}
+
+// moveOrRelease sends every one of a team's entities of targetType to dest
+// within tx, returning their IDs. Moved entities have their API sessions
+// revoked, as the team's members hold their tokens.
+func moveOrRelease(ctx context.Context, tx pgx.Tx, teamID, targetType string, dest *types.TeamEntityDestination) ([]string, error) {
+	table, idCol := "bots", "bot_id"
+
+	if targetType == "server" {
+		table, idCol = "servers", "server_id"
+	}
+
+	var sql string
+	var args = []any{teamID}
+
+	switch dest.Type {
+	case "release":
+		sql = "DELETE FROM " + table + " WHERE team_owner = $1 RETURNING " + idCol
+	case "user":
+		sql = "UPDATE " + table + " SET owner = $2, team_owner = NULL WHERE team_owner = $1 RETURNING " + idCol
+		args = append(args, dest.ID)
+	case "team":
+		sql = "UPDATE " + table + " SET team_owner = $2 WHERE team_owner = $1 RETURNING " + idCol
+		args = append(args, dest.ID)
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if dest.Type != "release" && len(ids) > 0 {
+		if _, err := tx.Exec(ctx, "DELETE FROM api_sessions WHERE target_type = $1 AND target_id = ANY($2)", targetType, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	return ids, nil
+}
+
+// DissolveTeam deletes a team in one transaction, first moving its bots and
+// servers to where userID chose or releasing them from the list. A
+// destination is only needed for entity types the team has any of.
+func DissolveTeam(ctx context.Context, userID, teamID string, bots, servers *types.TeamEntityDestination) (*types.DissolvedTeam, error) {
+	botIDs, serverIDs, err := TeamEntities(ctx, teamID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range []struct {
+		targetType string
+		ids        []string
+		dest       *types.TeamEntityDestination
+	}{
+		{"bot", botIDs, bots},
+		{"server", serverIDs, servers},
+	} {
+		if c.dest == nil {
+			if len(c.ids) > 0 {
+				return nil, fmt.Errorf("%w: the team has %ss, so where they go must be chosen", ErrInvalidDestination, c.targetType)
+			}
+
+			continue
+		}
+
+		if err := checkDestination(ctx, userID, teamID, c.targetType, c.dest); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := state.Pool.Begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var locked int
+
+	err = tx.QueryRow(ctx, "SELECT 1 FROM teams WHERE id = $1 FOR UPDATE", teamID).Scan(&locked)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTeamNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &types.DissolvedTeam{
+		MovedBots:       []string{},
+		ReleasedBots:    []string{},
+		MovedServers:    []string{},
+		ReleasedServers: []string{},
+	}
+
+	// Entities added since they were counted are caught here too, as they are
+	// matched on their team rather than by ID
+	if bots != nil {
+		ids, err := moveOrRelease(ctx, tx, teamID, "bot", bots)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if bots.Type == "release" {
+			res.ReleasedBots = ids
+		} else {
+			res.MovedBots = ids
+		}
+	}
+
+	if servers != nil {
+		ids, err := moveOrRelease(ctx, tx, teamID, "server", servers)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if servers.Type == "release" {
+			res.ReleasedServers = ids
+		} else {
+			res.MovedServers = ids
+		}
+	}
+
+	var remaining int
+
+	if err := tx.QueryRow(ctx, "SELECT (SELECT COUNT(*) FROM bots WHERE team_owner = $1) + (SELECT COUNT(*) FROM servers WHERE team_owner = $1)", teamID).Scan(&remaining); err != nil {
+		return nil, err
+	}
+
+	if remaining > 0 {
+		return nil, fmt.Errorf("%w: the team gained bots or servers while being deleted, so where they go must be chosen", ErrInvalidDestination)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM team_members WHERE team_id = $1", teamID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM teams WHERE id = $1", teamID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, id := range res.MovedBots {
+		api.InvalidateSessionCache(ctx, api.TargetTypeBot, id)
+	}
+
+	for _, id := range res.MovedServers {
+		api.InvalidateSessionCache(ctx, api.TargetTypeServer, id)
+	}
+
+	if bots != nil && bots.Type == "user" {
+		for _, id := range res.MovedBots {
+			notify(bots.ID, types.Alert{
+				Type:    types.AlertTypeInfo,
+				URL:     pgtype.Text{String: state.Config.Sites.Frontend.Parse() + "/bots/" + id, Valid: true},
+				Title:   "Bot Moved To You",
+				Message: fmt.Sprintf("A team you were a member of was deleted, and its bot with the ID %s is now owned by you.", id),
+				AlertData: map[string]any{
+					"bot_id":  id,
+					"team_id": teamID,
+				},
+			})
+		}
+	}
+
+	return res, nil
+}
@@ -52,3 +52,22 @@ type AlertPatchItem struct {
 	ITag  string `json:"itag" validate:"required" description:"The alert's ID"`
 	Patch string `json:"patch" validate:"required,oneof=ack unack delete" description:"The patch to apply to the alert, ack=mark as read, unack=unmark as read, delete=delete the alert"`
 }
+
+type AlertStreamEventType string
+
+const (
+	AlertStreamEventReady   AlertStreamEventType = "ready"
+	AlertStreamEventCreated AlertStreamEventType = "created"
+	AlertStreamEventPatched AlertStreamEventType = "patched"
+	AlertStreamEventCleared AlertStreamEventType = "cleared"
+)
+
+// One event on GET /users/{id}/alerts/stream. The SSE `event:` field carries
+// Event as well, so clients can listen per type instead of switching on the
+// payload.
+type AlertStreamEvent struct {
+	Event        AlertStreamEventType `json:"event" description:"ready=sent once on connect, created=a new alert was saved, patched=alerts were acked/unacked/deleted, cleared=all alerts were deleted"`
+	Alert        *Alert               `json:"alert,omitempty" description:"The new alert. Only set on created"`
+	Patches      []AlertPatchItem     `json:"patches,omitempty" description:"The patches that were applied. Only set on patched"`
+	UnackedCount uint64               `json:"unacked_count" description:"The number of unacknowledged alerts after this event"`
+}
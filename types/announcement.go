@@ -7,16 +7,41 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AnnouncementSegment string
+
+const (
+	AnnouncementSegmentAll                AnnouncementSegment = "all"
+	AnnouncementSegmentBotOwners          AnnouncementSegment = "bot_owners"
+	AnnouncementSegmentCertifiedBotOwners AnnouncementSegment = "certified_bot_owners"
+	AnnouncementSegmentPremiumOwners      AnnouncementSegment = "premium_owners"
+	AnnouncementSegmentTeamMembers        AnnouncementSegment = "team_members"
+)
+
+type AnnouncementStatus string
+
+const (
+	AnnouncementStatusPending    AnnouncementStatus = "pending"
+	AnnouncementStatusDelivering AnnouncementStatus = "delivering"
+	AnnouncementStatusDelivered  AnnouncementStatus = "delivered"
+	AnnouncementStatusCancelled  AnnouncementStatus = "cancelled"
+)
+
 // An announcement
 type Announcement struct {
-	UserID       string                  `db:"author" json:"-"`
-	Author       *dovetypes.PlatformUser `json:"author"` // Must be parsed internally
-	ID           pgtype.UUID             `db:"id" json:"id"`
-	Title        string                  `db:"title" json:"title"`
-	Content      string                  `db:"content" json:"content"`
-	LastModified time.Time               `db:"modified_date" json:"last_modified"`
-	Status       string                  `db:"status" json:"status"`
-	Target       pgtype.Text             `db:"target" json:"target"`
+	UserID         string                  `db:"author" json:"-"`
+	Author         *dovetypes.PlatformUser `json:"author"` // Must be parsed internally
+	ID             pgtype.UUID             `db:"id" json:"id"`
+	Title          string                  `db:"title" json:"title"`
+	Content        string                  `db:"content" json:"content"`
+	LastModified   time.Time               `db:"modified_date" json:"last_modified"`
+	Status         AnnouncementStatus      `db:"status" json:"status" description:"pending=waiting for scheduled_for, delivering=alerts are being sent out, delivered=every recipient has been sent an alert, cancelled=cancelled by staff"`
+	Target         pgtype.Text             `db:"target" json:"target" description:"The team ID for the team_members segment, null otherwise"`
+	Segment        AnnouncementSegment     `db:"segment" json:"segment" description:"Who the announcement is sent to: all=every user, bot_owners=owners of approved or certified bots, certified_bot_owners=owners of certified bots, premium_owners=owners of premium bots, team_members=members of the target team"`
+	AlertType      AlertType               `db:"alert_type" json:"alert_type" description:"The type of the alert sent to each recipient"`
+	URL            pgtype.Text             `db:"url" json:"url" description:"The URL the alert links to, if any"`
+	ScheduledFor   time.Time               `db:"scheduled_for" json:"scheduled_for" description:"When delivery starts"`
+	DeliveredCount int64                   `db:"delivered_count" json:"delivered_count" description:"How many users have been sent the announcement so far"`
+	CreatedAt      time.Time               `db:"created_at" json:"created_at"`
 }
 
 type AnnouncementList struct {
@@ -0,0 +1,20 @@
+package types
+
+import (
+	"time"
+
+	"github.com/infinitybotlist/eureka/dovewing/dovetypes"
+)
+
+type AuditLogEntry struct {
+	ID         string                  `db:"id" json:"id" description:"The ID of the entry"`
+	TargetType string                  `db:"target_type" json:"target_type" description:"The type of the entity changed: bot, server, team or pack"`
+	TargetID   string                  `db:"target_id" json:"target_id" description:"The ID of the entity changed"`
+	ActorType  string                  `db:"actor_type" json:"actor_type" description:"Who made the change: a user, or the bot, server or team itself through an API session"`
+	ActorID    string                  `db:"actor_id" json:"actor_id" description:"The ID of who made the change"`
+	Actor      *dovetypes.PlatformUser `db:"-" json:"actor" description:"The user who made the change, or null if it wasn't a user" ci:"internal"` // Must be parsed internally
+	Action     string                  `db:"action" json:"action" description:"The operation ID of the API route the change was made through"`
+	Before     map[string]any          `db:"before" json:"before" description:"The changed fields as they were, or null if the change created the entity"`
+	After      map[string]any          `db:"after" json:"after" description:"The changed fields as they are now, or null if the change deleted the entity"`
+	CreatedAt  time.Time               `db:"created_at" json:"created_at" description:"When the change was made"`
+}
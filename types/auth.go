@@ -25,7 +25,7 @@ type TestAuth struct {
 	Token    string `json:"token"`
 }
 
-// @ci table=api_sessions ignore_fields=token
+// @ci table=api_sessions ignore_fields=token+token_hash
 //
 // Represents a session that can be used to authorize/identify a user
 type Session struct {
@@ -53,6 +53,6 @@ type CreateSession struct {
 
 type CreateSessionResponse struct {
 	TargetID  string `json:"target_id" description:"The ID of the target"`
-	Token     string `json:"token" description:"The token of the session"`
+	Token     string `json:"token" description:"The token of the session, such as pop_bot_... It is only shown here, as only its hash is stored"`
 	SessionID string `json:"session_id" description:"The ID of the session"`
 }
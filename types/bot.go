@@ -30,6 +30,7 @@ type IndexBot struct {
 	CreatedAt        pgtype.Timestamptz      `db:"created_at" json:"created_at" description:"The creation date of the bot"`
 	SelfStatus       pgtype.Text             `db:"self_status" json:"-" description:"Presence self-reported by the bot via Post Bot Stats. Folded into user.status when present, not exposed directly."`
 	LastStatsPost    pgtype.Timestamptz      `db:"last_stats_post" json:"-" description:"The last time the bot posted stats to the list. Used to infer presence for bots with a real stats track record but no explicit self_status, not exposed directly."`
+	Promotion        string                  `db:"-" json:"promotion,omitempty" description:"Set when the bot fills a promoted slot. Pass it as the promo query parameter when fetching the bot's page so the click-through is attributed" ci:"internal"`
 }
 
 type BotStats struct {
@@ -43,7 +44,7 @@ type BotStats struct {
 	Status string `json:"status" validate:"omitempty,oneof=online idle dnd offline" msg:"Status must be one of online, idle, dnd or offline"`
 }
 
-// @ci table=bots, ignore_fields=api_token+unique_clicks+cache_server_uninvitable
+// @ci table=bots, ignore_fields=api_token+unique_clicks_legacy+cache_server_uninvitable+search_name+search_vector+search_commands
 //
 // Bot represents a bot.
 type Bot struct {
@@ -58,6 +59,7 @@ type Bot struct {
 	MainOwner           *dovetypes.PlatformUser `db:"-" json:"owner" description:"The bot owner's user information. If in a team, this will be null and team_owner will instead be set" ci:"internal"` // Must be parsed internally
 	Short               string                  `db:"short" json:"short" description:"The bot's short description"`
 	Long                string                  `db:"-" json:"long,omitempty" description:"The bot's long description in raw format (HTML/markdown etc. based on the bots settings). May not always be present (e.g. 'long' not in include)" skip:"long" ci:"internal"` // Must be parsed internally
+	LongHTML            string                  `db:"-" json:"long_html,omitempty" description:"The bot's long description rendered to sanitized HTML, safe to embed. Only present if 'long_html' is in include" ci:"internal"`                                         // Must be parsed internally
 	Library             string                  `db:"library" json:"library" description:"The bot's library"`
 	NSFW                bool                    `db:"nsfw" json:"nsfw" description:"Whether the bot is NSFW or not"`
 	Premium             bool                    `db:"premium" json:"premium" description:"Whether the bot is a premium bot or not"`
@@ -83,6 +85,9 @@ type Bot struct {
 	Uptime              int                     `db:"uptime" json:"uptime" description:"The bot's total number of successful uptime checks"`
 	TotalUptime         int                     `db:"total_uptime" json:"total_uptime" description:"The bot's total number of uptime checks"`
 	UptimeLastChecked   pgtype.Timestamptz      `db:"uptime_last_checked" json:"uptime_last_checked" description:"The bot's last uptime check"`
+	UptimeStats         *BotUptime              `db:"-" json:"uptime_stats,omitempty" description:"Uptime percentages and recent incidents. Only present if 'uptime' is in include" ci:"internal"`                           // Must be parsed internally
+	Commands            *BotCommandCatalogue    `db:"-" json:"commands,omitempty" description:"The bot's published command catalogue. Only present if 'commands' is in include and the bot has published one" ci:"internal"` // Must be parsed internally
+	OfflineAlertMinutes int                     `db:"offline_alert_minutes" json:"offline_alert_minutes" description:"How many minutes the bot must be offline before its owners are alerted, 0 if disabled"`
 	Note                pgtype.Text             `db:"approval_note" json:"approval_note" description:"The note for the bot's approval"`
 	CreatedAt           pgtype.Timestamptz      `db:"created_at" json:"created_at" description:"The bot's creation date"`
 	ClaimedBy           pgtype.Text             `db:"claimed_by" json:"claimed_by" description:"The user who claimed the bot"`
@@ -126,6 +131,8 @@ type BotSettingsUpdate struct {
 	Tags          []string `db:"tags" json:"tags" validate:"required,unique,min=1,max=5,dive,min=3,max=30,notblank,nonvulgar" msg:"There must be between 1 and 5 tags without duplicates" amsg:"Each tag must be between 3 and 30 characters and alphabetic"`
 	NSFW          bool     `db:"nsfw" json:"nsfw"`
 	CaptchaOptOut bool     `db:"captcha_opt_out" json:"captcha_opt_out"`
+
+	OfflineAlertMinutes int `db:"offline_alert_minutes" json:"offline_alert_minutes" validate:"omitempty,min=5,max=10080" msg:"Offline alerts must be after between 5 minutes and 7 days, or 0 to disable them"`
 }
 
 type Invite struct {
@@ -0,0 +1,46 @@
+package types
+
+import "time"
+
+// Shaped like Discord application commands, so bots can publish what they
+// already register with Discord plus a category
+type BotCommandType int
+
+const (
+	BotCommandTypeChatInput BotCommandType = 1
+	BotCommandTypeUser      BotCommandType = 2
+	BotCommandTypeMessage   BotCommandType = 3
+)
+
+type BotCommandOptionChoice struct {
+	Name  string `json:"name" validate:"required,max=100" msg:"Choice names must be between 1 and 100 characters"`
+	Value any    `json:"value" validate:"required" msg:"Choices must have a value"`
+}
+
+type BotCommandOption struct {
+	Type        int                      `json:"type" validate:"min=1,max=11" msg:"Option types must be a Discord application command option type (1-11)"`
+	Name        string                   `json:"name" validate:"required,max=32" msg:"Option names must be between 1 and 32 characters"`
+	Description string                   `json:"description" validate:"max=100" msg:"Option descriptions can be at most 100 characters"`
+	Required    bool                     `json:"required"`
+	Choices     []BotCommandOptionChoice `json:"choices,omitempty" validate:"max=25,dive" msg:"Options can have at most 25 choices"`
+	Options     []BotCommandOption       `json:"options,omitempty" validate:"max=25,dive" msg:"Subcommands can have at most 25 options"`
+}
+
+type BotCommand struct {
+	Type        BotCommandType     `json:"type" validate:"omitempty,min=1,max=3" msg:"Command types must be 1 (slash), 2 (user) or 3 (message)"`
+	Name        string             `json:"name" validate:"required,max=32" msg:"Command names must be between 1 and 32 characters"`
+	Description string             `json:"description" validate:"max=100" msg:"Command descriptions can be at most 100 characters"`
+	Category    string             `json:"category" validate:"max=32" msg:"Categories can be at most 32 characters"`
+	NSFW        bool               `json:"nsfw" description:"Whether the command is age restricted"`
+	Options     []BotCommandOption `json:"options,omitempty" validate:"max=25,dive" msg:"Commands can have at most 25 options"`
+}
+
+type PublishBotCommands struct {
+	Commands []BotCommand `json:"commands" validate:"required,max=200,dive" msg:"A bot can publish at most 200 commands"`
+}
+
+type BotCommandCatalogue struct {
+	Version   int          `db:"version" json:"version" description:"Starts at 1 and goes up each time the bot publishes different commands"`
+	Commands  []BotCommand `db:"commands" json:"commands"`
+	CreatedAt time.Time    `db:"created_at" json:"created_at" description:"When this version was published"`
+}
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"time"
+
+	"github.com/infinitybotlist/eureka/dovewing/dovetypes"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ProposeBotTransfer struct {
+	NewOwnerType   string `json:"new_owner_type" validate:"required,oneof=user team" msg:"The new owner must be a user or a team" description:"Whether the bot is being transferred to a user or a team"`
+	NewOwnerID     string `json:"new_owner_id" validate:"required" msg:"The new owner must be provided" description:"The ID of the user or team the bot is being transferred to"`
+	ExpiresInHours int    `json:"expires_in_hours" validate:"omitempty,min=1,max=168" msg:"Transfers must expire between 1 hour and 7 days from now" description:"How long the new owner has to accept the transfer. Defaults to 3 days"`
+}
+
+// BotTransfer is a proposed transfer of a bot to a new owner, waiting on them
+// to accept it
+type BotTransfer struct {
+	ID             string                  `db:"id" json:"id" description:"The ID of the transfer"`
+	BotID          string                  `db:"bot_id" json:"bot_id" description:"The ID of the bot"`
+	Bot            *dovetypes.PlatformUser `db:"-" json:"bot" description:"The bot being transferred" ci:"internal"` // Must be handled internally
+	FromUser       pgtype.Text             `db:"from_user" json:"from_user" description:"The ID of the user who owns the bot, or null if a team owns it"`
+	FromTeam       pgtype.Text             `db:"from_team" json:"from_team" description:"The ID of the team that owns the bot, or null if a user owns it"`
+	ToUser         pgtype.Text             `db:"to_user" json:"to_user" description:"The ID of the user the bot is being transferred to, or null if it is going to a team"`
+	ToTeam         pgtype.Text             `db:"to_team" json:"to_team" description:"The ID of the team the bot is being transferred to, or null if it is going to a user"`
+	ProposedBy     string                  `db:"proposed_by" json:"-" description:"The ID of the user who proposed the transfer"`
+	ProposedByUser *dovetypes.PlatformUser `db:"-" json:"proposed_by" description:"The user who proposed the transfer" ci:"internal"` // Must be handled internally
+	CreatedAt      time.Time               `db:"created_at" json:"created_at" description:"When the transfer was proposed"`
+	ExpiresAt      time.Time               `db:"expires_at" json:"expires_at" description:"When the transfer expires if not accepted"`
+}
+
+type BotTransferList struct {
+	Incoming []BotTransfer `json:"incoming" description:"Transfers the user can accept or decline: those to them, and those to teams they can add bots to"`
+	Outgoing []BotTransfer `json:"outgoing" description:"Transfers the user has proposed"`
+}
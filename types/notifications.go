@@ -1,6 +1,10 @@
 package types
 
-import "time"
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
 
 type NotificationInfo struct {
 	PublicKey string `json:"public_key"`
@@ -33,3 +37,14 @@ type NotifBrowserInfo struct {
 type NotifGetList struct {
 	Notifications []NotifGet `json:"notifications"`
 }
+
+// A users Discord DM notification settings
+type DiscordDMSettings struct {
+	Enabled        bool        `db:"enabled" json:"enabled" description:"Whether alerts are also sent to the user as Discord DMs"`
+	DisabledReason pgtype.Text `db:"disabled_reason" json:"disabled_reason" description:"Why Popplio turned DM notifications off on its own, e.g. because the user's DMs were closed. Null if the user turned them off themselves"`
+	LastSentAt     *time.Time  `db:"last_sent_at" json:"last_sent_at" description:"When the last DM was sent, if ever"`
+}
+
+type PatchDiscordDMSettings struct {
+	Enabled bool `json:"enabled" description:"Whether to send alerts as Discord DMs. Enabling sends a confirmation DM straight away and fails if it cannot be delivered"`
+}
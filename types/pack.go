@@ -18,9 +18,41 @@ type BotPack struct {
 	Tags            []string                `db:"tags" json:"tags" description:"The pack's tags"`
 	URL             string                  `db:"url" json:"url" description:"The pack's URL"`
 	CreatedAt       time.Time               `db:"created_at" json:"created_at" description:"The pack's creation date"`
-	Bots            []string                `db:"bots" json:"bot_ids" description:"The pack's bot IDs"`
+	Bots            []string                `db:"bots" json:"bot_ids" description:"The pack's bot IDs, in the order they are shown"`
 	ResolvedBots    []IndexBot              `db:"-" json:"bots" ci:"internal" description:"The resolved bots in the pack"` // Bots must be resolved internally from their IDs
-	Servers         []string                `db:"servers" json:"server_ids" description:"The pack's server IDs"`
+	Servers         []string                `db:"servers" json:"server_ids" description:"The pack's server IDs, in the order they are shown"`
 	ResolvedServers []IndexServer           `db:"-" json:"servers" ci:"internal" description:"The resolved servers in the pack"` // Servers must be resolved internally from their IDs
 	VoteBanned      bool                    `db:"vote_banned" json:"vote_banned" description:"Whether the pack is banned from voting"`
+	Collaborators   []PackCollaborator      `db:"-" json:"collaborators" ci:"internal" description:"The users who can edit the pack besides its owner"` // Collaborators are retrieved from pack_collaborators
+	Notes           []PackItemNote          `db:"-" json:"notes" ci:"internal" description:"Notes on the pack's bots and servers"`                      // Notes are retrieved from pack_item_notes
+}
+
+// Represents a user who can edit a pack besides its owner
+type PackCollaborator struct {
+	UserID    string                  `db:"user_id" json:"-" description:"The collaborator's user ID"`
+	User      *dovetypes.PlatformUser `db:"-" json:"user" description:"The collaborator"` // Must be resolved internally from the user ID
+	AddedBy   string                  `db:"added_by" json:"added_by" description:"The ID of the user who added the collaborator"`
+	CreatedAt time.Time               `db:"created_at" json:"created_at" description:"When the collaborator was added"`
+}
+
+// Represents a note on one of a pack's bots or servers
+type PackItemNote struct {
+	TargetType string    `db:"target_type" json:"target_type" description:"Whether the item is a bot or a server"`
+	TargetID   string    `db:"target_id" json:"target_id" description:"The ID of the bot or server"`
+	Note       string    `db:"note" json:"note" description:"The note"`
+	UpdatedBy  string    `db:"updated_by" json:"updated_by" description:"The ID of the user who last set the note"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at" description:"When the note was last set"`
+}
+
+// ReorderPackItems gives the new order of a pack's bots and servers. Each
+// must list exactly the items already in the pack.
+type ReorderPackItems struct {
+	Bots    []string `json:"bots" validate:"unique,dive,numeric" msg:"Bots must be listed without duplicates"`
+	Servers []string `json:"servers" validate:"unique,dive,numeric" msg:"Servers must be listed without duplicates"`
+}
+
+// SetPackItemNote sets the note on one of a pack's bots or servers. An empty
+// note removes it.
+type SetPackItemNote struct {
+	Note string `json:"note" validate:"max=200" msg:"Notes can be at most 200 characters"`
 }
@@ -0,0 +1,27 @@
+package types
+
+type PromotionDay struct {
+	Day         string `db:"day" json:"day" description:"The UTC day, as YYYY-MM-DD"`
+	Impressions int64  `db:"impressions" json:"impressions" description:"Times the entity was shown in a promoted slot on the day"`
+	Clicks      int64  `db:"clicks" json:"clicks" description:"Click-throughs from promoted slots on the day"`
+}
+
+type PromotionPlacement struct {
+	Surface          string  `db:"surface" json:"surface" description:"Where the slot is: bot_index, bot_random, server_index or server_random"`
+	Slot             int     `db:"slot" json:"slot" description:"The position of the slot on its surface, from 0"`
+	Impressions      int64   `db:"impressions" json:"impressions" description:"Times the entity was shown in the slot"`
+	Clicks           int64   `db:"clicks" json:"clicks" description:"Click-throughs from the slot"`
+	ClickThroughRate float64 `db:"-" json:"click_through_rate" description:"Clicks divided by impressions, or 0 without impressions"`
+}
+
+type PromotionReport struct {
+	From             string               `json:"from" description:"The first day of the report, as YYYY-MM-DD"`
+	To               string               `json:"to" description:"The last day of the report, as YYYY-MM-DD"`
+	Premium          bool                 `json:"premium" description:"Whether the entity is currently premium, and so being promoted"`
+	Weight           int                  `json:"weight" description:"The entity's rotation weight from its plan: 3 for gold, 2 for silver and 1 otherwise. 0 if not premium"`
+	Impressions      int64                `json:"impressions" description:"Impressions over the report"`
+	Clicks           int64                `json:"clicks" description:"Click-throughs over the report"`
+	ClickThroughRate float64              `json:"click_through_rate" description:"Clicks divided by impressions, or 0 without impressions"`
+	Placements       []PromotionPlacement `json:"placements" description:"Totals per slot over the report, most impressions first"`
+	Days             []PromotionDay       `json:"days" description:"One entry per day, oldest first. Counts are refreshed every 5 minutes"`
+}
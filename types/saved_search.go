@@ -0,0 +1,25 @@
+package types
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type SavedSearch struct {
+	ID            pgtype.UUID        `db:"id" json:"id"`
+	UserID        string             `db:"user_id" json:"user_id"`
+	Name          string             `db:"name" json:"name"`
+	Query         SearchQuery        `db:"query" json:"query" description:"The saved query. Only query, target_types (bot and server), the servers, votes, shards and total_members filters, tags and include_nsfw are used when matching"`
+	CreatedAt     time.Time          `db:"created_at" json:"created_at"`
+	LastMatchedAt pgtype.Timestamptz `db:"last_matched_at" json:"last_matched_at" description:"When a newly listed entity last matched this search, if ever"`
+}
+
+type SavedSearchList struct {
+	SavedSearches []SavedSearch `json:"saved_searches"`
+}
+
+type CreateSavedSearch struct {
+	Name  string      `json:"name" validate:"required,min=1,max=50,notblank" msg:"Name must be between 1 and 50 characters"`
+	Query SearchQuery `json:"query"`
+}
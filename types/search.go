@@ -17,18 +17,98 @@ type TagFilter struct {
 	TagMode TagMode  `json:"tag_mode"`
 }
 
+type SearchSort string
+
+const (
+	SearchSortVotes     SearchSort = "votes"
+	SearchSortServers   SearchSort = "servers"
+	SearchSortMembers   SearchSort = "members"
+	SearchSortCreatedAt SearchSort = "created_at"
+	SearchSortRating    SearchSort = "rating"
+	SearchSortRelevance SearchSort = "relevance"
+)
+
+// Opaque cursors returned in SearchPage.NextCursor, one per target type
+type SearchCursors struct {
+	Bot    string `json:"bot"`
+	Server string `json:"server"`
+	Pack   string `json:"pack"`
+	Team   string `json:"team"`
+	Blog   string `json:"blog"`
+}
+
 type SearchQuery struct {
-	Query        string       `json:"query"`
-	TargetTypes  []string     `json:"target_types"` // Defaults to 'bot' if unset
-	Servers      SearchFilter `json:"servers" msg:"Servers must be a valid filter"`
-	Votes        SearchFilter `json:"votes" msg:"Votes must be a valid filter"`
-	Shards       SearchFilter `json:"shards" msg:"Shards must be a valid filter"`
-	TotalMembers SearchFilter `json:"total_members" msg:"Total members must be a valid filter"`
-	TagFilter    TagFilter    `json:"tags" msg:"Tags must be a valid filter"`
+	Query        string        `json:"query"`
+	TargetTypes  []string      `json:"target_types"` // Defaults to 'bot' if unset
+	Servers      SearchFilter  `json:"servers" msg:"Servers must be a valid filter"`
+	Votes        SearchFilter  `json:"votes" msg:"Votes must be a valid filter"`
+	Shards       SearchFilter  `json:"shards" msg:"Shards must be a valid filter"`
+	TotalMembers SearchFilter  `json:"total_members" msg:"Total members must be a valid filter"`
+	TagFilter    TagFilter     `json:"tags" msg:"Tags must be a valid filter"`
+	Sort         SearchSort    `json:"sort" validate:"omitempty,oneof=votes servers members created_at rating relevance" msg:"Sort must be one of votes, servers, members, created_at, rating or relevance" description:"What to sort results by, always descending. servers and members both sort bots by server count and servers by total members. Defaults to relevance if a query is given and votes otherwise"`
+	Limit        int           `json:"limit" validate:"omitempty,min=1,max=100" msg:"Limit must be between 1 and 100" description:"How many results to return per target type. Defaults to 12"`
+	Facets       bool          `json:"facets" description:"Also return facet counts for bots and servers, over every match of the query and filters. Only computed for the first page"`
+	IncludeNSFW  bool          `json:"include_nsfw" description:"Also return NSFW teams and packs containing NSFW bots"`
+	Cursors      SearchCursors `json:"cursors" description:"The next_cursor of the previous page for each target type, to get the page after it. Leave empty for the first page. A cursor is only valid with the same query, filters and sort it came from"`
+}
+
+type SearchPage struct {
+	NextCursor     string `json:"next_cursor" description:"Pass this in cursors to get the next page. Empty if this is the last page"`
+	EstimatedTotal int64  `json:"estimated_total" description:"How many results match in total, across all pages. Exact up to 1000, larger totals are reported as 1000"`
+}
+
+type SearchFacetBucket struct {
+	From  int64 `json:"from" description:"Inclusive lower bound, usable as-is in the servers/total_members filter"`
+	To    int64 `json:"to" description:"Inclusive upper bound, 0 if unbounded"`
+	Count int64 `json:"count"`
+}
+
+type SearchFacets struct {
+	Tags      map[string]int64    `json:"tags" description:"Tag to number of matches with it, for the 50 most used tags"`
+	Libraries map[string]int64    `json:"libraries" description:"Library to number of matches using it, for the 50 most used libraries. Always empty for servers"`
+	NSFW      int64               `json:"nsfw" description:"Number of matches that are NSFW"`
+	Premium   int64               `json:"premium" description:"Number of matches that are premium"`
+	Certified int64               `json:"certified" description:"Number of matches that are certified"`
+	Size      []SearchFacetBucket `json:"size" description:"Number of matches per server count (bots) or total member count (servers) bucket"`
 }
 
 type SearchResponse struct {
-	TargetTypes []string      `json:"target_types"`
-	Bots        []IndexBot    `json:"bots,omitempty"`
-	Servers     []IndexServer `json:"servers,omitempty"`
+	TargetTypes  []string          `json:"target_types"`
+	Bots         []IndexBot        `json:"bots,omitempty"`
+	Servers      []IndexServer     `json:"servers,omitempty"`
+	BotsPage     *SearchPage       `json:"bots_page,omitempty" description:"Pagination for bots. Only set if bots were searched"`
+	ServersPage  *SearchPage       `json:"servers_page,omitempty" description:"Pagination for servers. Only set if servers were searched"`
+	Packs        []BotPack         `json:"packs,omitempty"`
+	PacksPage    *SearchPage       `json:"packs_page,omitempty" description:"Pagination for packs. Only set if packs were searched"`
+	Teams        []Team            `json:"teams,omitempty"`
+	TeamsPage    *SearchPage       `json:"teams_page,omitempty" description:"Pagination for teams. Only set if teams were searched"`
+	Blogs        []BlogListPost    `json:"blogs,omitempty"`
+	BlogsPage    *SearchPage       `json:"blogs_page,omitempty" description:"Pagination for blog posts. Only set if blogs were searched"`
+	BotFacets    *SearchFacets     `json:"bot_facets,omitempty" description:"Only set if facets were requested, on the first page of bots"`
+	ServerFacets *SearchFacets     `json:"server_facets,omitempty" description:"Only set if facets were requested, on the first page of servers"`
+	Snippets     map[string]string `json:"snippets" description:"Bot/server ID to an HTML excerpt of its short description with the words matching the query wrapped in <mark>. Everything else in it is escaped. Only present for results whose short description matched"`
+}
+
+type SearchSuggestion struct {
+	ID   string `json:"id" description:"The bot, server or team ID"`
+	Name string `json:"name" description:"The bot, server or team name"`
+}
+
+type SearchSuggestions struct {
+	Bots    []SearchSuggestion `json:"bots" description:"Bots whose name matches, prefix matches first"`
+	Servers []SearchSuggestion `json:"servers" description:"Servers whose name matches, prefix matches first"`
+	Teams   []SearchSuggestion `json:"teams" description:"Teams whose name matches, prefix matches first"`
+	Tags    []string           `json:"tags" description:"Tags of listed bots and servers starting with the query"`
+}
+
+type TagUsage struct {
+	Tag     string `db:"tag" json:"tag"`
+	Bots    int64  `db:"bots" json:"bots" description:"Number of listed bots with the tag"`
+	Servers int64  `db:"servers" json:"servers" description:"Number of listed servers with the tag"`
+	Teams   int64  `db:"teams" json:"teams" description:"Number of teams with the tag"`
+	Packs   int64  `db:"packs" json:"packs" description:"Number of packs with the tag"`
+}
+
+type TagList struct {
+	Tags []TagUsage `json:"tags"`
 }
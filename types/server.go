@@ -27,9 +27,10 @@ type IndexServer struct {
 	NSFW             bool        `db:"nsfw" json:"nsfw" description:"Whether the server is NSFW or not"`
 	Tags             []string    `db:"tags" json:"tags" description:"The server's tags (e.g. music, moderation, etc.)"`
 	Premium          bool        `db:"premium" json:"premium" description:"Whether the server is a premium server or not"`
+	Promotion        string      `db:"-" json:"promotion,omitempty" description:"Set when the server fills a promoted slot. Pass it as the promo query parameter when fetching the server's page so the click-through is attributed" ci:"internal"`
 }
 
-// @ci table=servers, ignore_fields=invite+blacklisted_users+api_token+unique_clicks
+// @ci table=servers, ignore_fields=invite+blacklisted_users+api_token+unique_clicks_legacy+search_vector
 //
 // Server represents a server.
 type Server struct {
@@ -40,6 +41,7 @@ type Server struct {
 	OnlineMembers          int                `db:"online_members" json:"online_members" description:"The server's online member count"`
 	Short                  string             `db:"short" json:"short" description:"The server's short description"`
 	Long                   string             `db:"-" json:"long" description:"The server's long description in raw format (HTML/markdown etc. based on the servers settings). May not be included in responses (e.g. long is not set in include)" skip:"long" ci:"internal"` // Must be parsed internally
+	LongHTML               string             `db:"-" json:"long_html,omitempty" description:"The server's long description rendered to sanitized HTML, safe to embed. Only present if 'long_html' is in include" ci:"internal"`                                              // Must be parsed internally
 	Type                   string             `db:"type" json:"type" description:"The server's type (e.g. pending/approved/certified/denied etc.)"`
 	State                  string             `db:"state" json:"state" description:"The server's state (public, private, unlisted, defunct)"`
 	Tags                   []string           `db:"tags" json:"tags" description:"The server's tags"`
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type StatsResolution string
+
+const (
+	StatsResolutionRaw    StatsResolution = "raw"
+	StatsResolutionHourly StatsResolution = "hourly"
+	StatsResolutionDaily  StatsResolution = "daily"
+)
+
+type StatsPoint struct {
+	Time          time.Time   `db:"bucket" json:"time" description:"The start of the minute, hour or day this point covers"`
+	Servers       pgtype.Int8 `db:"servers" json:"servers" description:"Bots only. The server count, averaged over the point for hourly and daily points"`
+	Shards        pgtype.Int8 `db:"shards" json:"shards" description:"Bots only. The shard count"`
+	Users         pgtype.Int8 `db:"users" json:"users" description:"Bots only. The user count"`
+	TotalMembers  pgtype.Int8 `db:"total_members" json:"total_members" description:"Servers only. The total member count"`
+	OnlineMembers pgtype.Int8 `db:"online_members" json:"online_members" description:"Servers only. The online member count"`
+}
+
+type StatsHistory struct {
+	Resolution StatsResolution `json:"resolution" description:"The resolution of the points: raw is one point per minute with stats, hourly and daily are averages"`
+	From       time.Time       `json:"from"`
+	To         time.Time       `json:"to"`
+	Points     []StatsPoint    `json:"points" description:"Oldest first, at most 5000"`
+}
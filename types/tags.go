@@ -0,0 +1,20 @@
+package types
+
+import "time"
+
+// Tag is a tag of the tag registry, which entity tags must be from
+type Tag struct {
+	ID          string    `db:"id" json:"id" description:"The ID of the tag, which is what entities store. A lowercase slug of its name"`
+	Name        string    `db:"name" json:"name" description:"The display name of the tag"`
+	Description string    `db:"description" json:"description" description:"What the tag is for"`
+	Icon        string    `db:"icon" json:"icon" description:"Iconify icon of the tag, empty if none"`
+	TargetTypes []string  `db:"target_types" json:"target_types" description:"The target types (bot, server, team, pack) the tag may be used on"`
+	Aliases     []string  `db:"aliases" json:"aliases" description:"Other tags that are normalized to this one when added"`
+	Retired     bool      `db:"retired" json:"retired" description:"Retired tags are kept by entities already using them, but can't be newly added"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type TagRegistry struct {
+	Tags []Tag `json:"tags"`
+}
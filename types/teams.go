@@ -60,13 +60,39 @@ type TeamMember struct {
 	TeamID      string                  `db:"team_id" json:"team_id" description:"The ID of the team"`
 	UserID      string                  `db:"user_id" json:"-" description:"The ID of the user"`
 	User        *dovetypes.PlatformUser `db:"-" json:"user" description:"A user object representing the user" ci:"internal"` // Must be handled internally
-	Flags       []string                `db:"flags" json:"flags" description:"The permissions/flags of the team member"`
+	Flags       []string                `db:"flags" json:"flags" description:"The permissions/flags of the team member, on top of those of their roles"`
+	Roles       []string                `db:"roles" json:"roles" description:"The IDs of the team roles the member has"`
 	Service     string                  `db:"service" json:"service" description:"The service which added a team member (api/infernoplex) etc."`
 	CreatedAt   time.Time               `db:"created_at" json:"created_at" description:"The time the team member was added"`
 	Mentionable bool                    `db:"mentionable" json:"mentionable" description:"Whether the user is mentionable (for alerts in bot-logs etc.)"`
 	DataHolder  bool                    `db:"data_holder" json:"data_holder" description:"Whether the user is a data holder responsible for all data on the team. That is, should performing mass-scale operations on them affect the team"`
 }
 
+// @ci table=team_roles
+//
+// TeamRole is a named set of permissions a team can give its members
+type TeamRole struct {
+	ID        string      `db:"id" json:"id" description:"The ID of the role"`
+	TeamID    string      `db:"team_id" json:"team_id" description:"The ID of the team"`
+	Name      string      `db:"name" json:"name" description:"The name of the role"`
+	Color     pgtype.Text `db:"color" json:"color" description:"The color of the role as a hex code, or null"`
+	Position  int32       `db:"position" json:"position" description:"The rank of the role, 0 being the most senior. Members can only manage roles below their most senior role"`
+	Perms     []string    `db:"perms" json:"perms" description:"The permissions members with the role have"`
+	CreatedAt time.Time   `db:"created_at" json:"created_at" description:"When the role was created"`
+	UpdatedAt time.Time   `db:"updated_at" json:"updated_at" description:"When the role was last edited"`
+}
+
+type TeamRoleList struct {
+	Roles []TeamRole `json:"roles" description:"The team's roles, most senior first"`
+}
+
+type CreateEditTeamRole struct {
+	Name     string   `json:"name" validate:"required,notblank,nonvulgar,min=1,max=32" msg:"Role name must be between 1 and 32 characters long"`
+	Color    string   `json:"color" validate:"omitempty,hexcolor" msg:"Role color must be a hex color code such as #5865f2"`
+	Position *int32   `json:"position" validate:"omitempty,min=0" msg:"Position cannot be lower than 0" description:"The rank of the role, 0 being the most senior. Roles at and below it move down one. Defaults to the bottom when creating, and to no change when editing"`
+	Perms    []string `json:"perms" description:"The permissions members with the role have. Owner can only be given to members directly"`
+}
+
 type CreateEditTeam struct {
 	Name       string    `json:"name" validate:"required,nonvulgar,min=3,max=32" msg:"Team name must be between 3 and 32 characters long"`
 	Short      *string   `json:"short" validate:"omitempty,max=150" msg:"Short description must be a maximum of 150 characters"` // impld
@@ -102,12 +128,42 @@ func PermissionDataFrom(defs []perms.Definition) []PermissionData {
 }
 
 type AddTeamMember struct {
-	UserID string   `json:"user_id" description:"The ID of the user to add to the team"`
-	Perms  []string `json:"perms" description:"The initial permissions to give to the user"`
+	UserID         string   `json:"user_id" description:"The ID of the user to invite to the team"`
+	Perms          []string `json:"perms" description:"The permissions the user will have once they accept the invite"`
+	ExpiresInHours int      `json:"expires_in_hours" validate:"omitempty,min=1,max=720" msg:"Invites must expire between 1 hour and 30 days from now" description:"How long the user has to accept the invite. Defaults to 7 days"`
+}
+
+type CreateTeamInviteLink struct {
+	Perms          []string `json:"perms" description:"The permissions members joining through the link will have"`
+	ExpiresInHours int      `json:"expires_in_hours" validate:"omitempty,min=1,max=720" msg:"Invites must expire between 1 hour and 30 days from now" description:"How long the link can be used for. Defaults to 7 days"`
+	MaxUses        int      `json:"max_uses" validate:"omitempty,min=1,max=100" msg:"An invite link can be used between 1 and 100 times" description:"How many users can join through the link. Unlimited (until it expires) if unset"`
+}
+
+// TeamInvite is an invite to join a team, either for a single user or a
+// shareable link
+type TeamInvite struct {
+	ID            string                  `db:"id" json:"id" description:"The ID of the invite"`
+	TeamID        string                  `db:"team_id" json:"team_id" description:"The ID of the team"`
+	TeamName      string                  `db:"team_name" json:"team_name" description:"The name of the team"`
+	UserID        pgtype.Text             `db:"user_id" json:"user_id" description:"The ID of the invited user, or null for an invite link"`
+	Code          pgtype.Text             `db:"code" json:"code" description:"The code of an invite link, or null for an invite to a single user. Only shown to the team"`
+	URL           string                  `db:"-" json:"url,omitempty" description:"The shareable URL of an invite link. Only shown to the team"`
+	Flags         []string                `db:"flags" json:"flags" description:"The permissions the member will have once they accept"`
+	InvitedBy     string                  `db:"invited_by" json:"-" description:"The ID of the user who created the invite"`
+	InvitedByUser *dovetypes.PlatformUser `db:"-" json:"invited_by" description:"The user who created the invite" ci:"internal"` // Must be handled internally
+	MaxUses       pgtype.Int4             `db:"max_uses" json:"max_uses" description:"How many users can join through an invite link, or null if unlimited"`
+	Uses          int                     `db:"uses" json:"uses" description:"How many users have joined through an invite link"`
+	CreatedAt     time.Time               `db:"created_at" json:"created_at" description:"When the invite was created"`
+	ExpiresAt     time.Time               `db:"expires_at" json:"expires_at" description:"When the invite expires"`
+}
+
+type TeamInviteList struct {
+	Invites []TeamInvite `json:"invites" description:"The pending invites, newest first"`
 }
 
 type EditTeamMember struct {
 	Perms       *[]string `json:"perms" description:"The permissions to set. If empty, will not update"`
+	Roles       *[]string `json:"roles" description:"The IDs of the team roles to set. If empty, will not update"`
 	Mentionable *bool     `json:"mentionable" description:"Whether the user is mentionable Whether the user is mentionable (for alerts in bot-logs etc.)"`
 	DataHolder  *bool     `db:"data_holder" json:"data_holder" description:"Whether the user is a data holder responsible for all data on the team. That is, should performing mass-scale operations on them affect the team"`
 }
@@ -115,3 +171,27 @@ type EditTeamMember struct {
 type UserEntityPerms struct {
 	Perms []string `json:"perms" description:"The user's permissions on an entity"`
 }
+
+type TransferTeamOwnership struct {
+	UserID string `json:"user_id" validate:"required" msg:"The new owner must be provided" description:"The ID of the member to make an owner of the team"`
+	Leave  bool   `json:"leave" description:"Whether to leave the team once ownership is handed over, rather than staying on as a member without Owner"`
+}
+
+// TeamEntityDestination is where a team's bots or servers go when it is
+// dissolved
+type TeamEntityDestination struct {
+	Type string `json:"type" validate:"required,oneof=user team release" msg:"The destination must be user, team or release" description:"user to give them to a member of the team (bots only), team to move them to another team, or release to remove them from the list"`
+	ID   string `json:"id" description:"The ID of the member or team, unless releasing"`
+}
+
+type DissolveTeam struct {
+	Bots    *TeamEntityDestination `json:"bots" validate:"omitempty" description:"Where the team's bots go. Required if the team has any"`
+	Servers *TeamEntityDestination `json:"servers" validate:"omitempty" description:"Where the team's servers go. Required if the team has any"`
+}
+
+type DissolvedTeam struct {
+	MovedBots       []string `json:"moved_bots" description:"The IDs of the bots moved to their destination"`
+	ReleasedBots    []string `json:"released_bots" description:"The IDs of the bots removed from the list"`
+	MovedServers    []string `json:"moved_servers" description:"The IDs of the servers moved to their destination"`
+	ReleasedServers []string `json:"released_servers" description:"The IDs of the servers removed from the list"`
+}
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type BotIncident struct {
+	ID        pgtype.UUID        `db:"id" json:"id"`
+	StartedAt time.Time          `db:"started_at" json:"started_at" description:"When the bot went offline"`
+	EndedAt   pgtype.Timestamptz `db:"ended_at" json:"ended_at" description:"When the bot came back online, null if it is still offline"`
+}
+
+type BotUptime struct {
+	Online       bool               `json:"online" description:"Whether the bot was online when last observed"`
+	TrackedSince pgtype.Timestamptz `json:"tracked_since" description:"When the bot's presence was first observed. Null if it never has been, in which case the percentages are null too"`
+	Uptime24h    *float64           `json:"uptime_24h" description:"Percentage of the last 24 hours (or since tracked_since, if later) the bot was online"`
+	Uptime7d     *float64           `json:"uptime_7d" description:"Percentage of the last 7 days the bot was online"`
+	Uptime30d    *float64           `json:"uptime_30d" description:"Percentage of the last 30 days the bot was online"`
+	Incidents    []BotIncident      `json:"incidents" description:"Times the bot went offline in the last 30 days, newest first and at most 50"`
+}
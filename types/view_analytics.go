@@ -0,0 +1,17 @@
+package types
+
+type ViewDay struct {
+	Day          string           `db:"day" json:"day" description:"The UTC day, as YYYY-MM-DD"`
+	Views        int64            `db:"views" json:"views" description:"Page views on the day"`
+	UniqueViews  int64            `db:"unique_views" json:"unique_views" description:"Approximate number of distinct visitors on the day"`
+	InviteClicks int64            `db:"invite_clicks" json:"invite_clicks" description:"Invite clicks on the day"`
+	VanityViews  int64            `db:"vanity_views" json:"vanity_views" description:"Page views through the vanity URL"`
+	DirectViews  int64            `db:"direct_views" json:"direct_views" description:"Page views through the ID URL"`
+	Referrers    map[string]int64 `db:"referrers" json:"referrers" description:"Page views by referring host, for at most the top 20 hosts. Views with no known referrer or from the list itself are not included"`
+}
+
+type ViewAnalytics struct {
+	From string    `json:"from" description:"The first day of the series, as YYYY-MM-DD"`
+	To   string    `json:"to" description:"The last day of the series, as YYYY-MM-DD"`
+	Days []ViewDay `json:"days" description:"One entry per day, oldest first. Totals are refreshed every 5 minutes"`
+}
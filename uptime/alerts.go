@@ -0,0 +1,126 @@
+package uptime
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"popplio/notifications"
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/infinitybotlist/eureka/dovewing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// owners selects everyone who manages bot $1: its owner, or every member of
+// its owning team
+const owners = `SELECT owner FROM bots WHERE bot_id = $1 AND owner IS NOT NULL
+	UNION SELECT tm.user_id FROM team_members tm JOIN bots b ON b.team_owner = tm.team_id WHERE b.bot_id = $1`
+
+type alertable struct {
+	ID        pgtype.UUID        `db:"id"`
+	BotID     string             `db:"bot_id"`
+	StartedAt time.Time          `db:"started_at"`
+	EndedAt   pgtype.Timestamptz `db:"ended_at"`
+}
+
+// AlertOffline alerts the owners of bots that opted in (offline_alert_minutes)
+// once an incident has been open that long, and again when it closes.
+func AlertOffline(ctx context.Context) error {
+	// Claiming the flag in the same statement that selects the incident means
+	// an incident is alerted at most once, even if sending fails
+	rows, err := state.Pool.Query(
+		ctx,
+		`UPDATE bot_incidents i SET owners_alerted = true FROM bots b
+		WHERE b.bot_id = i.bot_id AND i.ended_at IS NULL AND NOT i.owners_alerted
+		AND b.offline_alert_minutes > 0 AND (b.type = 'approved' OR b.type = 'certified')
+		AND i.started_at <= NOW() - make_interval(mins => b.offline_alert_minutes)
+		RETURNING i.id, i.bot_id, i.started_at, i.ended_at`,
+	)
+
+	if err != nil {
+		return fmt.Errorf("claiming offline incidents: %w", err)
+	}
+
+	offline, err := pgx.CollectRows(rows, pgx.RowToStructByName[alertable])
+
+	if err != nil {
+		return fmt.Errorf("collecting offline incidents: %w", err)
+	}
+
+	for _, i := range offline {
+		notify(ctx, i, types.AlertTypeWarning, "%s is offline", "%s has been offline since %s.")
+	}
+
+	rows, err = state.Pool.Query(
+		ctx,
+		`UPDATE bot_incidents SET recovery_alerted = true
+		WHERE ended_at IS NOT NULL AND owners_alerted AND NOT recovery_alerted
+		RETURNING id, bot_id, started_at, ended_at`,
+	)
+
+	if err != nil {
+		return fmt.Errorf("claiming recovered incidents: %w", err)
+	}
+
+	recovered, err := pgx.CollectRows(rows, pgx.RowToStructByName[alertable])
+
+	if err != nil {
+		return fmt.Errorf("collecting recovered incidents: %w", err)
+	}
+
+	for _, i := range recovered {
+		notify(ctx, i, types.AlertTypeSuccess, "%s is back online", "%s is back online after being offline since %s.")
+	}
+
+	return nil
+}
+
+// notify sends an alert about incident i to the bot's owners. title and
+// message are formatted with the bot's name, and message also with when the
+// incident started.
+func notify(ctx context.Context, i alertable, alertType types.AlertType, title, message string) {
+	name := i.BotID
+	var avatar string
+
+	if bot, err := dovewing.GetUser(ctx, i.BotID, state.DovewingPlatformDiscord); err == nil {
+		name = bot.Username
+		avatar = bot.Avatar
+	}
+
+	rows, err := state.Pool.Query(ctx, owners, i.BotID)
+
+	if err != nil {
+		state.Logger.Error("Failed to get bot owners for uptime alert", zap.Error(err), zap.String("botID", i.BotID))
+		return
+	}
+
+	userIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+
+	if err != nil {
+		state.Logger.Error("Failed to get bot owners for uptime alert", zap.Error(err), zap.String("botID", i.BotID))
+		return
+	}
+
+	for _, userID := range userIDs {
+		err := notifications.PushNotification(userID, types.Alert{
+			Type:    alertType,
+			URL:     pgtype.Text{String: state.Config.Sites.Frontend.Parse() + "/bot/" + i.BotID, Valid: true},
+			Title:   fmt.Sprintf(title, name),
+			Message: fmt.Sprintf(message, name, i.StartedAt.UTC().Format("2006-01-02 15:04 MST")),
+			Icon:    avatar,
+			AlertData: map[string]any{
+				"incident_id": i.ID,
+				"bot_id":      i.BotID,
+			},
+			Priority: types.AlertPriorityHigh,
+		})
+
+		if err != nil {
+			state.Logger.Error("Failed to send uptime alert", zap.Error(err), zap.String("botID", i.BotID), zap.String("userID", userID))
+		}
+	}
+}
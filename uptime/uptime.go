@@ -0,0 +1,225 @@
+// Package uptime tracks when listed bots go offline and come back.
+//
+// Presence is observed in the main server, from gateway presence updates as
+// they arrive (Listener) and from the bot_uptime_check poll, which catches
+// any update missed while disconnected. Each transition to offline opens an
+// incident and the transition back online closes it. Uptime percentages are
+// derived from the incidents rather than from the poll's check counters, so
+// they reflect exactly how long a bot was down.
+//
+// Owners can opt in to being alerted when their bot stays offline for a while,
+// see AlertOffline.
+package uptime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"popplio/state"
+	"popplio/types"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxIncidents caps the incident list returned by Get
+	maxIncidents = 50
+
+	// observeQueueSize is how many gateway observations may wait to be
+	// written before new ones are dropped. Dropping is safe, the next poll
+	// reconciles.
+	observeQueueSize = 1024
+)
+
+// Observe records whether a bot is online, opening or closing an incident if
+// that changed. Bots that are not listed are ignored.
+func Observe(ctx context.Context, botID string, online bool) error {
+	tx, err := state.Pool.Begin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	// Only a change (or a first observation) returns a row
+	var changed bool
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO bot_presence (bot_id, online)
+		SELECT bot_id, $2 FROM bots WHERE bot_id = $1 AND (type = 'approved' OR type = 'certified')
+		ON CONFLICT (bot_id) DO UPDATE SET online = EXCLUDED.online, changed_at = NOW()
+		WHERE bot_presence.online IS DISTINCT FROM EXCLUDED.online
+		RETURNING true`,
+		botID,
+		online,
+	).Scan(&changed)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("updating presence: %w", err)
+	}
+
+	if online {
+		_, err = tx.Exec(ctx, "UPDATE bot_incidents SET ended_at = NOW() WHERE bot_id = $1 AND ended_at IS NULL", botID)
+	} else {
+		_, err = tx.Exec(ctx, "INSERT INTO bot_incidents (bot_id) VALUES ($1) ON CONFLICT DO NOTHING", botID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("updating incidents: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// IsOnline reports whether a presence status counts as online
+func IsOnline(status discord.OnlineStatus) bool {
+	return status != discord.OnlineStatusOffline && status != discord.OnlineStatusInvisible
+}
+
+type observation struct {
+	botID  string
+	online bool
+}
+
+// Listener returns a gateway event listener that observes presence updates of
+// bots in the main server. Writes happen in order on a worker goroutine, so
+// the gateway is never held up by the database; the worker stops with ctx.
+func Listener(ctx context.Context) bot.EventListener {
+	queue := make(chan observation, observeQueueSize)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case o := <-queue:
+				if err := Observe(ctx, o.botID, o.online); err != nil {
+					state.Logger.Error("Failed to record bot presence", zap.Error(err), zap.String("botID", o.botID))
+				}
+			}
+		}
+	}()
+
+	return &events.ListenerAdapter{
+		OnPresenceUpdate: func(event *events.PresenceUpdate) {
+			if event.GuildID != state.Config.Servers.Main {
+				return
+			}
+
+			// Users' presence churns far more than bots', don't queue it at all
+			member, ok := event.Client().Caches().Member(event.GuildID, event.PresenceUser.ID)
+
+			if !ok || !member.User.Bot {
+				return
+			}
+
+			select {
+			case queue <- observation{botID: event.PresenceUser.ID.String(), online: IsOnline(event.Status)}:
+			default:
+				state.Logger.Warn("Bot presence queue full, dropping update", zap.String("botID", event.PresenceUser.ID.String()))
+			}
+		},
+	}
+}
+
+// Get returns a bot's uptime percentages and recent incidents.
+func Get(ctx context.Context, botID string) (*types.BotUptime, error) {
+	u := &types.BotUptime{
+		Incidents: []types.BotIncident{},
+	}
+
+	err := state.Pool.QueryRow(ctx, "SELECT online, tracked_since FROM bot_presence WHERE bot_id = $1", botID).Scan(&u.Online, &u.TrackedSince)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return u, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	rows, err := state.Pool.Query(
+		ctx,
+		"SELECT id, started_at, ended_at FROM bot_incidents WHERE bot_id = $1 AND (ended_at IS NULL OR ended_at > $2) ORDER BY started_at DESC",
+		botID,
+		now.Add(-30*24*time.Hour),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	incidents, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.BotIncident])
+
+	if err != nil {
+		return nil, err
+	}
+
+	u.Uptime24h = percentUp(incidents, u.TrackedSince.Time, now, 24*time.Hour)
+	u.Uptime7d = percentUp(incidents, u.TrackedSince.Time, now, 7*24*time.Hour)
+	u.Uptime30d = percentUp(incidents, u.TrackedSince.Time, now, 30*24*time.Hour)
+
+	if len(incidents) > maxIncidents {
+		incidents = incidents[:maxIncidents]
+	}
+
+	u.Incidents = incidents
+
+	return u, nil
+}
+
+// percentUp returns the percentage of the window ending at now, clamped to
+// trackedSince, not covered by an incident. It is nil if nothing of the
+// window was tracked.
+func percentUp(incidents []types.BotIncident, trackedSince, now time.Time, window time.Duration) *float64 {
+	start := now.Add(-window)
+
+	if trackedSince.After(start) {
+		start = trackedSince
+	}
+
+	total := now.Sub(start)
+
+	if total <= 0 {
+		return nil
+	}
+
+	var down time.Duration
+
+	for _, i := range incidents {
+		from, to := i.StartedAt, now
+
+		if i.EndedAt.Valid {
+			to = i.EndedAt.Time
+		}
+
+		if from.Before(start) {
+			from = start
+		}
+
+		if to.After(from) {
+			down += to.Sub(from)
+		}
+	}
+
+	pct := 100 * (1 - float64(down)/float64(total))
+
+	if pct < 0 {
+		pct = 0
+	}
+
+	return &pct
+}
@@ -2,7 +2,7 @@
 //
 // It covers both halves of the system: recording and counting votes against
 // any votable entity, and converting accumulated votes into redeemable
-// credits. Queries take a DbConn rather than the pool directly so callers
+// credits. Queries take a db.Conn rather than the pool directly so callers
 // can run them inside a transaction they already opened.
 package votes
 
@@ -18,7 +18,6 @@ import (
 
 	"github.com/infinitybotlist/eureka/dovewing"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
 )
 
 var (
@@ -26,12 +25,6 @@ var (
 	entityVoteCols    = strings.Join(entityVoteColsArr, ",")
 )
 
-type DbConn interface {
-	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
-	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
-	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
-}
-
 func GetDoubleVote() bool {
 	weekday := time.Now().Weekday()
 	return weekday == time.Friday || weekday == time.Saturday || weekday == time.Sunday
@@ -45,7 +38,7 @@ type EntityInfo struct {
 }
 
 // GetEntityInfo returns information about the entity that is being voted for including vote bans etc.
-func GetEntityInfo(ctx context.Context, c DbConn, targetId, targetType string) (*EntityInfo, error) {
+func GetEntityInfo(ctx context.Context, c db.Conn, targetId, targetType string) (*EntityInfo, error) {
 	// Handle entity specific checks here, such as ensuring the entity actually exists
 	switch targetType {
 	case "bot":
@@ -169,7 +162,7 @@ func GetEntityInfo(ctx context.Context, c DbConn, targetId, targetType string) (
 // # If user id is specified, then in the future special perks for the user will be returned as well
 //
 // If vote time is negative, then it is not possible to revote
-func EntityVoteInfo(ctx context.Context, c DbConn, targetId, targetType string) (*types.VoteInfo, error) {
+func EntityVoteInfo(ctx context.Context, c db.Conn, targetId, targetType string) (*types.VoteInfo, error) {
 	var voteEntity = types.VoteInfo{
 		PerUser:           1,     // 1 vote per user
 		VoteTime:          12,    // per day
@@ -242,7 +235,7 @@ func EntityVoteInfo(ctx context.Context, c DbConn, targetId, targetType string)
 }
 
 // Checks whether or not a user has voted for an entity
-func EntityVoteCheck(ctx context.Context, c DbConn, userId, targetId, targetType string) (*types.UserVote, error) {
+func EntityVoteCheck(ctx context.Context, c db.Conn, userId, targetId, targetType string) (*types.UserVote, error) {
 	vi, err := EntityVoteInfo(ctx, c, targetId, targetType)
 
 	if err != nil {
@@ -314,7 +307,7 @@ func EntityVoteCheck(ctx context.Context, c DbConn, userId, targetId, targetType
 }
 
 // Returns the exact (non-cached/approximate) vote count for an entity
-func EntityGetVoteCount(ctx context.Context, c DbConn, targetId, targetType string) (int, error) {
+func EntityGetVoteCount(ctx context.Context, c db.Conn, targetId, targetType string) (int, error) {
 	var upvotes int
 	var downvotes int
 
@@ -332,7 +325,7 @@ func EntityGetVoteCount(ctx context.Context, c DbConn, targetId, targetType stri
 }
 
 // Helper function to give votes to an entity based on vote info
-func EntityGiveVotes(ctx context.Context, c DbConn, upvote bool, author, targetType, targetId string, vi *types.VoteInfo) error {
+func EntityGiveVotes(ctx context.Context, c db.Conn, upvote bool, author, targetType, targetId string, vi *types.VoteInfo) error {
 	// Keep adding votes until, but not including vi.VoteInfo.PerUser
 	for i := 0; i < vi.PerUser; i++ {
 		_, err := c.Exec(ctx, "INSERT INTO entity_votes (author, target_id, target_type, upvote, vote_num) VALUES ($1, $2, $3, $4, $5)", author, targetId, targetType, upvote, i)
@@ -345,7 +338,7 @@ func EntityGiveVotes(ctx context.Context, c DbConn, upvote bool, author, targetT
 }
 
 // Helper function to perform post-vote tasks
-func EntityPostVote(ctx context.Context, c DbConn, targetType, targetId string) error {
+func EntityPostVote(ctx context.Context, c db.Conn, targetType, targetId string) error {
 	nvc, err := EntityGetVoteCount(ctx, c, targetId, targetType)
 
 	if err != nil {
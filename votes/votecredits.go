@@ -23,7 +23,7 @@ var (
 // Returns a summary of the vote credit tiers of an entity
 func EntityGetVoteCreditsSummary(
 	ctx context.Context,
-	c DbConn,
+	c db.Conn,
 	targetId string,
 	targetType string,
 ) (*types.VoteCreditTierRedeemSummary, error) {
@@ -66,7 +66,7 @@ func EntityGetVoteCreditsSummary(
 // Redeems vote credits for a user towards a specific entity
 func EntityRedeemVoteCredits(
 	ctx context.Context,
-	c DbConn,
+	c db.Conn,
 	targetId string,
 	targetType string,
 	votesToRedeem int,
@@ -149,7 +149,7 @@ func EntityRedeemVoteCredits(
 // Returns a summary of the entity vote redeem logs
 func EntityGetVoteRedeemLogsSummary(
 	ctx context.Context,
-	c DbConn,
+	c db.Conn,
 	targetId string,
 	targetType string,
 ) (*types.EntityVoteRedeemLogSummary, error) {
@@ -0,0 +1,74 @@
+package events
+
+import (
+	"popplio/validators"
+	"popplio/webhooks/core/events"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/infinitybotlist/eureka/dovewing/dovetypes"
+)
+
+type WebhookOwnerChangeData struct {
+	OldOwnerType string `json:"old_owner_type" description:"Whether the entity was owned by a user or a team" testvalue:"team"`
+	OldOwnerID   string `json:"old_owner_id" description:"The ID of the user or team that owned the entity"`
+	NewOwnerType string `json:"new_owner_type" description:"Whether the entity is now owned by a user or a team" testvalue:"user"`
+	NewOwnerID   string `json:"new_owner_id" description:"The ID of the user or team that now owns the entity" testvalue:"510065483693817867"`
+	Reason       string `json:"reason" description:"Why the entity changed hands. team_dissolved when its team was deleted and its entities moved" testvalue:"team_dissolved"`
+}
+
+func (n WebhookOwnerChangeData) TargetTypes() []string {
+	return []string{"bot", "server"}
+}
+
+func (n WebhookOwnerChangeData) Event() string {
+	return "OWNER_CHANGE"
+}
+
+func (n WebhookOwnerChangeData) Summary() string {
+	return "Owner Change"
+}
+
+func (n WebhookOwnerChangeData) Description() string {
+	return "This webhook is sent when an entity is moved to a new owner, such as when the team that owned it is deleted."
+}
+
+func ownerRef(ownerType, id string) string {
+	if ownerType == "team" {
+		return "[Team](https://botlist.site/teams/" + id + ")"
+	}
+
+	return "<@" + id + ">"
+}
+
+func (n WebhookOwnerChangeData) CreateDiscordEmbed(creator *dovetypes.PlatformUser, targets events.Target) *discord.Embed {
+	return &discord.Embed{
+		URL: "https://botlist.site/" + targets.GetID(),
+		Thumbnail: &discord.EmbedResource{
+			URL: targets.GetAvatarURL(),
+		},
+		Title:       "📦 Owner Changed!",
+		Description: ":heart: " + creator.DisplayName + " has moved " + targets.GetTargetName() + " to a new owner",
+		Color:       0x8A6BFD,
+		Fields: []discord.EmbedField{
+			{
+				Name:   "Old Owner:",
+				Value:  ownerRef(n.OldOwnerType, n.OldOwnerID),
+				Inline: validators.TruePtr,
+			},
+			{
+				Name:   "New Owner:",
+				Value:  ownerRef(n.NewOwnerType, n.NewOwnerID),
+				Inline: validators.TruePtr,
+			},
+			{
+				Name:   "View Page",
+				Value:  targets.GetViewLink(),
+				Inline: validators.TruePtr,
+			},
+		},
+	}
+}
+
+func init() {
+	events.AddEvent(WebhookOwnerChangeData{})
+}
@@ -0,0 +1,70 @@
+package events
+
+import (
+	"popplio/validators"
+	"popplio/webhooks/core/events"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/infinitybotlist/eureka/dovewing/dovetypes"
+)
+
+type WebhookTeamOwnershipTransferData struct {
+	OldOwner string `json:"old_owner" description:"The ID of the member who handed over ownership" testvalue:"510065483693817867"`
+	NewOwner string `json:"new_owner" description:"The ID of the member who was made an owner" testvalue:"564164277251080208"`
+	Left     bool   `json:"left" description:"Whether the old owner left the team, rather than staying on as a member"`
+}
+
+func (n WebhookTeamOwnershipTransferData) TargetTypes() []string {
+	return []string{"team"}
+}
+
+func (n WebhookTeamOwnershipTransferData) Event() string {
+	return "TEAM_OWNERSHIP_TRANSFER"
+}
+
+func (n WebhookTeamOwnershipTransferData) Summary() string {
+	return "Team Ownership Transfer"
+}
+
+func (n WebhookTeamOwnershipTransferData) Description() string {
+	return "This webhook is sent when an owner of a team hands ownership over to another member."
+}
+
+func (n WebhookTeamOwnershipTransferData) CreateDiscordEmbed(creator *dovetypes.PlatformUser, targets events.Target) *discord.Embed {
+	left := "No"
+
+	if n.Left {
+		left = "Yes"
+	}
+
+	return &discord.Embed{
+		URL: "https://botlist.site/teams/" + targets.GetID(),
+		Thumbnail: &discord.EmbedResource{
+			URL: targets.GetAvatarURL(),
+		},
+		Title:       "👑 Team Ownership Transferred!",
+		Description: ":heart: " + creator.DisplayName + " has handed ownership of " + targets.GetTargetName() + " over to <@" + n.NewOwner + ">",
+		Color:       0x8A6BFD,
+		Fields: []discord.EmbedField{
+			{
+				Name:   "Old Owner:",
+				Value:  "<@" + n.OldOwner + ">",
+				Inline: validators.TruePtr,
+			},
+			{
+				Name:   "New Owner:",
+				Value:  "<@" + n.NewOwner + ">",
+				Inline: validators.TruePtr,
+			},
+			{
+				Name:   "Left Team:",
+				Value:  left,
+				Inline: validators.TruePtr,
+			},
+		},
+	}
+}
+
+func init() {
+	events.AddEvent(WebhookTeamOwnershipTransferData{})
+}
@@ -0,0 +1,246 @@
+package widgets
+
+import (
+	"image/color"
+	"strconv"
+)
+
+type Layout string
+
+const (
+	// LayoutBadge is a shields.io style badge of a single stat
+	LayoutBadge Layout = "badge"
+
+	// LayoutCompact is a single line with the name and every stat
+	LayoutCompact Layout = "compact"
+
+	// LayoutCard is a card with the name above a row of stats
+	LayoutCard Layout = "card"
+)
+
+var Layouts = []Layout{LayoutBadge, LayoutCompact, LayoutCard}
+
+type Theme string
+
+const (
+	ThemeDark  Theme = "dark"
+	ThemeLight Theme = "light"
+)
+
+var Themes = []Theme{ThemeDark, ThemeLight}
+
+type palette struct {
+	Background color.RGBA
+	Text       color.RGBA
+	Muted      color.RGBA
+	Accent     color.RGBA
+	Certified  color.RGBA
+	BadgeLabel color.RGBA
+}
+
+var themes = map[Theme]palette{
+	ThemeDark: {
+		Background: color.RGBA{0x1e, 0x1f, 0x22, 0xff},
+		Text:       color.RGBA{0xf2, 0xf3, 0xf5, 0xff},
+		Muted:      color.RGBA{0xb5, 0xba, 0xc1, 0xff},
+		Accent:     color.RGBA{0x58, 0x65, 0xf2, 0xff},
+		Certified:  color.RGBA{0x23, 0xa5, 0x59, 0xff},
+		BadgeLabel: color.RGBA{0x55, 0x55, 0x55, 0xff},
+	},
+	ThemeLight: {
+		Background: color.RGBA{0xff, 0xff, 0xff, 0xff},
+		Text:       color.RGBA{0x1e, 0x1f, 0x22, 0xff},
+		Muted:      color.RGBA{0x5c, 0x5e, 0x66, 0xff},
+		Accent:     color.RGBA{0x58, 0x65, 0xf2, 0xff},
+		Certified:  color.RGBA{0x1a, 0x7f, 0x45, 0xff},
+		BadgeLabel: color.RGBA{0x55, 0x55, 0x55, 0xff},
+	},
+}
+
+var white = color.RGBA{0xff, 0xff, 0xff, 0xff}
+
+// listName is shown on widgets with room for it
+const listName = "Infinity Bot List"
+
+// canvas is a laid out widget, in SVG user units (pixels at 1x)
+type canvas struct {
+	Width, Height float64
+	Title         string
+	Rects         []rect
+	Texts         []text
+}
+
+type rect struct {
+	X, Y, W, H, Radius float64
+	Fill               color.RGBA
+}
+
+type text struct {
+	// X is the left of the text and Y its baseline
+	X, Y  float64
+	S     string
+	Size  float64
+	Bold  bool
+	Fill  color.RGBA
+	Width float64
+}
+
+func (c *canvas) text(x, y float64, s string, size float64, bold bool, fill color.RGBA) float64 {
+	w := measure(s, size, bold)
+	c.Texts = append(c.Texts, text{X: x, Y: y, S: s, Size: size, Bold: bold, Fill: fill, Width: w})
+	return w
+}
+
+// formatCount abbreviates large counts, as 1.2k or 3.4M
+func formatCount(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return strconv.FormatFloat(float64(n/100_000)/10, 'f', -1, 64) + "M"
+	case n >= 10_000:
+		return strconv.FormatInt(n/1000, 10) + "k"
+	case n >= 1000:
+		return strconv.FormatFloat(float64(n/100)/10, 'f', -1, 64) + "k"
+	default:
+		return strconv.FormatInt(n, 10)
+	}
+}
+
+// truncate shortens s with an ellipsis until it is at most maxWidth wide
+func truncate(s string, size float64, bold bool, maxWidth float64) string {
+	if measure(s, size, bold) <= maxWidth {
+		return s
+	}
+
+	r := []rune(s)
+
+	for len(r) > 0 && measure(string(r)+"…", size, bold) > maxWidth {
+		r = r[:len(r)-1]
+	}
+
+	return string(r) + "…"
+}
+
+func layout(d *Data, o Options) *canvas {
+	p := themes[o.Theme]
+
+	switch o.Layout {
+	case LayoutBadge:
+		return layoutBadge(d, o.Stat, p)
+	case LayoutCompact:
+		return layoutCompact(d, p)
+	default:
+		return layoutCard(d, p)
+	}
+}
+
+func layoutBadge(d *Data, statKey string, p palette) *canvas {
+	const height, size, pad = 20.0, 11.0, 6.0
+
+	stat := d.Stats[0]
+
+	for _, s := range d.Stats {
+		if s.Key == statKey {
+			stat = s
+		}
+	}
+
+	label, value := stat.Label, formatCount(stat.Value)
+	labelWidth := measure(label, size, false) + 2*pad
+	valueWidth := measure(value, size, true) + 2*pad
+
+	valueFill := p.Accent
+
+	if d.Certified {
+		valueFill = p.Certified
+	}
+
+	c := &canvas{
+		Width:  labelWidth + valueWidth,
+		Height: height,
+		Title:  d.Name + ": " + value + " " + label,
+		Rects: []rect{
+			{W: labelWidth + valueWidth, H: height, Radius: 3, Fill: p.BadgeLabel},
+			{X: labelWidth, W: valueWidth, H: height, Radius: 3, Fill: valueFill},
+			// Squares the value's corners where it meets the label
+			{X: labelWidth, W: 3, H: height, Fill: valueFill},
+		},
+	}
+
+	c.text(pad, 14, label, size, false, white)
+	c.text(labelWidth+pad, 14, value, size, true, white)
+
+	return c
+}
+
+func layoutCompact(d *Data, p palette) *canvas {
+	const height, pad, gap = 28.0, 10.0, 8.0
+
+	c := &canvas{Height: height, Title: d.Name}
+
+	x := pad
+	x += c.text(x, 18, truncate(d.Name, 13, true, 180), 13, true, p.Text) + gap
+
+	if d.Certified {
+		x += pill(c, x, 7, "Certified", p.Certified) + gap
+	}
+
+	for i, s := range d.Stats {
+		if i > 0 {
+			x += c.text(x, 18, "·", 12, false, p.Muted) + gap/2
+		}
+
+		x += c.text(x, 18, formatCount(s.Value), 12, true, p.Text) + 3
+		x += c.text(x, 18, s.Label, 12, false, p.Muted) + gap/2
+	}
+
+	c.Width = x - gap/2 + pad
+	c.Rects = append([]rect{{W: c.Width, H: height, Radius: 6, Fill: p.Background}}, c.Rects...)
+
+	return c
+}
+
+func layoutCard(d *Data, p palette) *canvas {
+	const height, pad = 92.0, 16.0
+
+	c := &canvas{Title: d.Name}
+
+	nameWidth := c.text(pad, 30, truncate(d.Name, 16, true, 220), 16, true, p.Text)
+
+	listWidth := measure(listName, 10, false)
+	width := pad + nameWidth + 16 + listWidth + pad
+
+	if d.Certified {
+		width += pill(c, pad+nameWidth+8, 17, "Certified", p.Certified) + 8
+	}
+
+	width = max(width, 300)
+
+	// Right aligned, now that the width is known
+	c.text(width-pad-listWidth, 28, listName, 10, false, p.Muted)
+
+	column := (width - 2*pad) / float64(len(d.Stats))
+
+	for i, s := range d.Stats {
+		x := pad + float64(i)*column
+		c.text(x, 64, formatCount(s.Value), 18, true, p.Text)
+		c.text(x, 80, s.Label, 11, false, p.Muted)
+	}
+
+	c.Width, c.Height = width, height
+	c.Rects = append([]rect{{W: width, H: height, Radius: 8, Fill: p.Background}}, c.Rects...)
+
+	return c
+}
+
+// pill draws a small rounded label with its top left at x, y, returning its
+// width
+func pill(c *canvas, x, y float64, s string, fill color.RGBA) float64 {
+	const size, pad, height = 10.0, 6.0, 16.0
+
+	w := measure(s, size, true) + 2*pad
+
+	c.Rects = append(c.Rects, rect{X: x, Y: y, W: w, H: height, Radius: height / 2, Fill: fill})
+	c.text(x+pad, y+11.5, s, size, true, white)
+
+	return w
+}
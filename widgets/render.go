@@ -0,0 +1,172 @@
+package widgets
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// pngScale is how much larger than the SVG a PNG is drawn, so it stays sharp
+// on high density screens
+const pngScale = 2
+
+// svgFontFamily falls back to fonts of similar width where the Go fonts are
+// not installed; textLength makes up the difference
+const svgFontFamily = "Go,Verdana,DejaVu Sans,sans-serif"
+
+var (
+	regular = mustParseFont(goregular.TTF)
+	bold    = mustParseFont(gobold.TTF)
+
+	// faces are not safe for concurrent use, so every use holds facesMu
+	facesMu sync.Mutex
+	faces   = map[faceKey]font.Face{}
+)
+
+type faceKey struct {
+	size float64
+	bold bool
+}
+
+func mustParseFont(ttf []byte) *opentype.Font {
+	f, err := opentype.Parse(ttf)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return f
+}
+
+// face returns the face of size, facesMu must be held
+func face(size float64, isBold bool) font.Face {
+	key := faceKey{size, isBold}
+
+	if f, ok := faces[key]; ok {
+		return f
+	}
+
+	src := regular
+
+	if isBold {
+		src = bold
+	}
+
+	f, err := opentype.NewFace(src, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+
+	if err != nil {
+		panic(err)
+	}
+
+	faces[key] = f
+	return f
+}
+
+// measure returns how wide s is drawn at size
+func measure(s string, size float64, isBold bool) float64 {
+	facesMu.Lock()
+	defer facesMu.Unlock()
+
+	return float64(font.MeasureString(face(size, isBold), s)) / 64
+}
+
+func num(f float64) string {
+	return strconv.FormatFloat(float64(int(f*10+0.5))/10, 'f', -1, 64)
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func (c *canvas) svg() []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" role="img" aria-label="%s">`, num(c.Width), num(c.Height), num(c.Width), num(c.Height), html.EscapeString(c.Title))
+	fmt.Fprintf(&b, "<title>%s</title>", html.EscapeString(c.Title))
+
+	for _, r := range c.Rects {
+		fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" rx="%s" fill="%s"/>`, num(r.X), num(r.Y), num(r.W), num(r.H), num(r.Radius), hexColor(r.Fill))
+	}
+
+	for _, t := range c.Texts {
+		weight := "normal"
+
+		if t.Bold {
+			weight = "bold"
+		}
+
+		fmt.Fprintf(
+			&b,
+			`<text x="%s" y="%s" font-family="%s" font-size="%s" font-weight="%s" fill="%s" textLength="%s" lengthAdjust="spacingAndGlyphs">%s</text>`,
+			num(t.X), num(t.Y), svgFontFamily, num(t.Size), weight, hexColor(t.Fill), num(t.Width), html.EscapeString(t.S),
+		)
+	}
+
+	b.WriteString("</svg>")
+
+	return []byte(b.String())
+}
+
+func (c *canvas) png() ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, int(c.Width*pngScale+0.5), int(c.Height*pngScale+0.5)))
+
+	for _, r := range c.Rects {
+		z := vector.NewRasterizer(img.Bounds().Dx(), img.Bounds().Dy())
+		roundedRect(z, r.X*pngScale, r.Y*pngScale, r.W*pngScale, r.H*pngScale, r.Radius*pngScale)
+		z.Draw(img, img.Bounds(), image.NewUniform(r.Fill), image.Point{})
+	}
+
+	facesMu.Lock()
+
+	for _, t := range c.Texts {
+		d := font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(t.Fill),
+			Face: face(t.Size*pngScale, t.Bold),
+			Dot:  fixed.Point26_6{X: fixed.Int26_6(t.X * pngScale * 64), Y: fixed.Int26_6(t.Y * pngScale * 64)},
+		}
+
+		d.DrawString(t.S)
+	}
+
+	facesMu.Unlock()
+
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func roundedRect(z *vector.Rasterizer, x, y, w, h, r float64) {
+	r = min(r, w/2, h/2)
+
+	x0, y0, x1, y1 := float32(x), float32(y), float32(x+w), float32(y+h)
+	rr := float32(r)
+
+	z.MoveTo(x0+rr, y0)
+	z.LineTo(x1-rr, y0)
+	z.QuadTo(x1, y0, x1, y0+rr)
+	z.LineTo(x1, y1-rr)
+	z.QuadTo(x1, y1, x1-rr, y1)
+	z.LineTo(x0+rr, y1)
+	z.QuadTo(x0, y1, x0, y1-rr)
+	z.LineTo(x0, y0+rr)
+	z.QuadTo(x0, y0, x0+rr, y0)
+	z.ClosePath()
+}
@@ -0,0 +1,301 @@
+// Package widgets renders embeddable status widgets of bots, servers and
+// teams, for owners to show on their READMEs and websites.
+//
+// A widget is laid out once (see layout.go) and then written out either as SVG
+// or rasterized to PNG, so both formats always agree. Text is measured with
+// the Go fonts, which the PNG is drawn with and the SVG stretches its text to
+// fit, so layouts don't depend on the fonts a viewer has installed.
+//
+// Rendered widgets are cached in Redis. Posting stats or voting calls
+// Invalidate, which bumps the entity's widget version so its cached widgets
+// are never served again.
+package widgets
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"popplio/state"
+	"popplio/votes"
+
+	"github.com/google/uuid"
+	"github.com/infinitybotlist/eureka/dovewing"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// cacheExpiry is how long a rendered widget is cached. Stats that don't
+	// invalidate it, like server members, are at most this stale.
+	cacheExpiry = 15 * time.Minute
+
+	// versionExpiry must outlive cacheExpiry, so a version resetting can
+	// never bring back a widget cached under it
+	versionExpiry = 24 * time.Hour
+)
+
+// ErrNotFound is returned when the entity of a widget doesn't exist
+var ErrNotFound = errors.New("entity not found")
+
+type Format string
+
+const (
+	FormatSVG Format = "svg"
+	FormatPNG Format = "png"
+)
+
+func (f Format) ContentType() string {
+	if f == FormatPNG {
+		return "image/png"
+	}
+
+	return "image/svg+xml"
+}
+
+// Stat is one figure shown on a widget
+type Stat struct {
+	Key   string
+	Label string
+	Value int64
+}
+
+// Data is what a widget shows
+type Data struct {
+	TargetType string
+	Name       string
+	Certified  bool
+
+	// Stats are in display order, and are what a badge's stat may be
+	Stats []Stat
+}
+
+// Options is how a widget is drawn
+type Options struct {
+	Format Format
+	Layout Layout
+	Theme  Theme
+
+	// Stat is the key of the stat a badge shows
+	Stat string
+}
+
+// statKeys are the stats each target type's widgets show, in Load's order
+var statKeys = map[string][]string{
+	"bot":    {"votes", "servers", "shards"},
+	"server": {"votes", "members", "online"},
+	"team":   {"votes", "bots", "servers"},
+}
+
+// ParseOptions reads the layout, theme and stat query parameters of a widget
+// of targetType. Errors are user facing.
+func ParseOptions(targetType, format string, q url.Values) (Options, error) {
+	o := Options{
+		Format: Format(format),
+		Layout: Layout(q.Get("layout")),
+		Theme:  Theme(q.Get("theme")),
+		Stat:   q.Get("stat"),
+	}
+
+	if o.Format != FormatSVG && o.Format != FormatPNG {
+		return o, errors.New("Widgets are available as .svg or .png")
+	}
+
+	if o.Layout == "" {
+		o.Layout = LayoutCard
+	}
+
+	if !slices.Contains(Layouts, o.Layout) {
+		return o, fmt.Errorf("layout must be one of %v", Layouts)
+	}
+
+	if o.Theme == "" {
+		o.Theme = ThemeDark
+	}
+
+	if _, ok := themes[o.Theme]; !ok {
+		return o, fmt.Errorf("theme must be one of %v", Themes)
+	}
+
+	if o.Stat == "" {
+		o.Stat = "votes"
+	}
+
+	if !slices.Contains(statKeys[targetType], o.Stat) {
+		return o, fmt.Errorf("stat must be one of %v", statKeys[targetType])
+	}
+
+	return o, nil
+}
+
+// Load returns what the widget of an entity shows, reading the same data as
+// fetching the entity does. Entities that aren't listed are ErrNotFound.
+func Load(ctx context.Context, targetType, targetID string) (*Data, error) {
+	d := &Data{TargetType: targetType}
+
+	switch targetType {
+	case "bot":
+		var listed bool
+		var botType string
+		var servers, shards int64
+
+		err := state.Pool.QueryRow(ctx, "SELECT type IN ('approved', 'certified'), type, servers, shards FROM bots WHERE bot_id = $1", targetID).Scan(&listed, &botType, &servers, &shards)
+
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && !listed) {
+			return nil, ErrNotFound
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		botUser, err := dovewing.GetUser(ctx, targetID, state.DovewingPlatformDiscord)
+
+		if err != nil {
+			return nil, fmt.Errorf("getting bot user: %w", err)
+		}
+
+		d.Name = botUser.Username
+		d.Certified = botType == "certified"
+		d.Stats = []Stat{
+			{Key: "servers", Label: "servers", Value: servers},
+			{Key: "shards", Label: "shards", Value: shards},
+		}
+	case "server":
+		var listed bool
+		var serverType string
+		var members, online int64
+
+		err := state.Pool.QueryRow(ctx, "SELECT type IN ('approved', 'certified') AND state = 'public', name, type, total_members, online_members FROM servers WHERE server_id = $1", targetID).Scan(&listed, &d.Name, &serverType, &members, &online)
+
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && !listed) {
+			return nil, ErrNotFound
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		d.Certified = serverType == "certified"
+		d.Stats = []Stat{
+			{Key: "members", Label: "members", Value: members},
+			{Key: "online", Label: "online", Value: online},
+		}
+	case "team":
+		if _, err := uuid.Parse(targetID); err != nil {
+			return nil, ErrNotFound
+		}
+
+		var bots, servers int64
+
+		err := state.Pool.QueryRow(
+			ctx,
+			`SELECT name,
+			(SELECT COUNT(*) FROM bots WHERE team_owner = teams.id),
+			(SELECT COUNT(*) FROM servers WHERE team_owner = teams.id)
+			FROM teams WHERE id = $1`,
+			targetID,
+		).Scan(&d.Name, &bots, &servers)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		d.Stats = []Stat{
+			{Key: "bots", Label: "bots", Value: bots},
+			{Key: "servers", Label: "servers", Value: servers},
+		}
+	default:
+		return nil, ErrNotFound
+	}
+
+	voteCount, err := votes.EntityGetVoteCount(ctx, state.Pool, targetID, targetType)
+
+	if err != nil {
+		return nil, fmt.Errorf("getting vote count: %w", err)
+	}
+
+	// Votes always come first
+	d.Stats = append([]Stat{{Key: "votes", Label: "votes", Value: int64(voteCount)}}, d.Stats...)
+
+	return d, nil
+}
+
+// Render draws d as o describes.
+func Render(d *Data, o Options) ([]byte, error) {
+	c := layout(d, o)
+
+	if o.Format == FormatPNG {
+		return c.png()
+	}
+
+	return c.svg(), nil
+}
+
+// ETag returns the entity tag of a rendered widget
+func ETag(widget []byte) string {
+	sum := sha256.Sum256(widget)
+	return `"` + hex.EncodeToString(sum[:12]) + `"`
+}
+
+func versionKey(targetType, targetID string) string {
+	return "widget_version:" + targetType + ":" + targetID
+}
+
+// Get returns the rendered widget of an entity, from the cache if possible.
+func Get(ctx context.Context, targetType, targetID string, o Options) ([]byte, error) {
+	version, err := state.Redis.Get(ctx, versionKey(targetType, targetID)).Int64()
+
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	cacheKey := "widget:" + targetType + ":" + targetID + ":" + strconv.FormatInt(version, 10) + ":" + string(o.Layout) + ":" + string(o.Theme) + ":" + o.Stat + "." + string(o.Format)
+
+	cached, err := state.Redis.Get(ctx, cacheKey).Bytes()
+
+	if err == nil {
+		return cached, nil
+	}
+
+	if !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	d, err := Load(ctx, targetType, targetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	widget, err := Render(d, o)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := state.Redis.Set(ctx, cacheKey, widget, cacheExpiry).Err(); err != nil {
+		return nil, err
+	}
+
+	return widget, nil
+}
+
+// Invalidate stops the cached widgets of an entity from being served.
+func Invalidate(ctx context.Context, targetType, targetID string) error {
+	pipe := state.Redis.TxPipeline()
+	pipe.Incr(ctx, versionKey(targetType, targetID))
+	pipe.Expire(ctx, versionKey(targetType, targetID), versionExpiry)
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,74 @@
+package widgets
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestFormatCount(t *testing.T) {
+	cases := map[int64]string{
+		0:         "0",
+		999:       "999",
+		1000:      "1k",
+		1250:      "1.2k",
+		12_500:    "12k",
+		1_250_000: "1.2M",
+	}
+
+	for n, want := range cases {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	d := &Data{
+		TargetType: "bot",
+		Name:       `Bot <with> "markup" & a name long enough to need truncating on a card`,
+		Certified:  true,
+		Stats: []Stat{
+			{Key: "votes", Label: "votes", Value: 1234},
+			{Key: "servers", Label: "servers", Value: 56789},
+			{Key: "shards", Label: "shards", Value: 3},
+		},
+	}
+
+	for _, l := range Layouts {
+		for _, theme := range Themes {
+			o := Options{Layout: l, Theme: theme, Stat: "servers"}
+
+			o.Format = FormatSVG
+			svg, err := Render(d, o)
+
+			if err != nil {
+				t.Fatalf("%s/%s svg: %v", l, theme, err)
+			}
+
+			if strings.Contains(string(svg), "<with>") {
+				t.Errorf("%s/%s svg: name not escaped", l, theme)
+			}
+
+			o.Format = FormatPNG
+			b, err := Render(d, o)
+
+			if err != nil {
+				t.Fatalf("%s/%s png: %v", l, theme, err)
+			}
+
+			img, err := png.Decode(bytes.NewReader(b))
+
+			if err != nil {
+				t.Fatalf("%s/%s png: %v", l, theme, err)
+			}
+
+			c := layout(d, o)
+
+			if img.Bounds().Dx() != int(c.Width*pngScale+0.5) {
+				t.Errorf("%s/%s png: width %d, want %v", l, theme, img.Bounds().Dx(), c.Width*pngScale)
+			}
+		}
+	}
+}